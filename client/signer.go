@@ -0,0 +1,130 @@
+// Package client signs requests to the wallet service for an API key, as an
+// alternative to sending the key in the x-api-key header. Requests are signed
+// with the signing_secret returned alongside the key, and only the key's ID
+// and a signature travel with each request, so a logged request cannot be
+// replayed or used to recover either secret.
+//
+//	httpClient := client.NewHTTPClient(keyID, signingSecret)
+//	resp, err := httpClient.Get("https://paystack-wallet.fly.dev/wallet/balance")
+package client
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Headers a signed request carries
+const (
+	HeaderKeyID     = "X-Key-Id"
+	HeaderTimestamp = "X-Timestamp"
+	HeaderNonce     = "X-Nonce"
+	HeaderSignature = "X-Signature"
+)
+
+// StringToSign is what a request's signature covers: the key ID, the Unix
+// timestamp in seconds, the nonce, the method, the path with its query
+// string and the hex SHA-256 of the body, one per line.
+func StringToSign(keyID, timestamp, nonce, method, requestURI string, body []byte) string {
+	bodyHash := sha256.Sum256(body)
+	return strings.Join([]string{
+		keyID,
+		timestamp,
+		nonce,
+		strings.ToUpper(method),
+		requestURI,
+		hex.EncodeToString(bodyHash[:]),
+	}, "\n")
+}
+
+// Signature is the hex HMAC-SHA256 of stringToSign
+func Signature(signingKey []byte, stringToSign string) string {
+	mac := hmac.New(sha256.New, signingKey)
+	mac.Write([]byte(stringToSign))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// Signer signs requests for one API key
+type Signer struct {
+	keyID      string
+	signingKey []byte
+	now        func() time.Time
+}
+
+// NewSigner returns a signer for the key with ID keyID. signingSecret is the
+// signing_secret returned when the key was created.
+func NewSigner(keyID, signingSecret string) *Signer {
+	return &Signer{keyID: keyID, signingKey: []byte(signingSecret), now: time.Now}
+}
+
+// Sign sets the signature headers on req. The body is read and replaced, so
+// req can still be sent afterwards.
+func (s *Signer) Sign(req *http.Request) error {
+	var body []byte
+	if req.Body != nil {
+		var err error
+		if body, err = io.ReadAll(req.Body); err != nil {
+			return err
+		}
+		req.Body.Close()
+		req.Body = io.NopCloser(bytes.NewReader(body))
+	}
+
+	nonce, err := newNonce()
+	if err != nil {
+		return err
+	}
+	timestamp := strconv.FormatInt(s.now().Unix(), 10)
+
+	stringToSign := StringToSign(s.keyID, timestamp, nonce, req.Method, req.URL.RequestURI(), body)
+	req.Header.Set(HeaderKeyID, s.keyID)
+	req.Header.Set(HeaderTimestamp, timestamp)
+	req.Header.Set(HeaderNonce, nonce)
+	req.Header.Set(HeaderSignature, Signature(s.signingKey, stringToSign))
+	return nil
+}
+
+func newNonce() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
+
+// Transport signs every request before passing it to Base, or to
+// http.DefaultTransport when Base is nil
+type Transport struct {
+	Signer *Signer
+	Base   http.RoundTripper
+}
+
+func (t *Transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	// A RoundTripper must not modify the request it is given
+	signed := req.Clone(req.Context())
+	if err := t.Signer.Sign(signed); err != nil {
+		if req.Body != nil {
+			req.Body.Close()
+		}
+		return nil, err
+	}
+
+	base := t.Base
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	return base.RoundTrip(signed)
+}
+
+// NewHTTPClient returns an http.Client that signs every request it sends
+// for the key
+func NewHTTPClient(keyID, signingSecret string) *http.Client {
+	return &http.Client{Transport: &Transport{Signer: NewSigner(keyID, signingSecret)}}
+}
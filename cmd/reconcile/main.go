@@ -0,0 +1,68 @@
+package main
+
+import (
+	"flag"
+	"log"
+	"os"
+
+	"github.com/BerylCAtieno/paystack-wallet/internal/config"
+	"github.com/BerylCAtieno/paystack-wallet/internal/database"
+	"github.com/BerylCAtieno/paystack-wallet/internal/reconcile"
+	"github.com/BerylCAtieno/paystack-wallet/internal/repository"
+)
+
+// reconcile recomputes every wallet balance in the live database, or the
+// sandbox one with -sandbox, and reports drift. It exits with status 1 when
+// any wallet has drifted so it can gate a nightly job.
+func main() {
+	format := flag.String("format", "text", "report format: text or json")
+	freeze := flag.Bool("freeze", false, "freeze wallets whose balance has drifted")
+	sandbox := flag.Bool("sandbox", false, "reconcile the sandbox database instead of the live one")
+	flag.Parse()
+
+	if *format != "text" && *format != "json" {
+		log.Fatalf("Unknown format %q, use text or json", *format)
+	}
+
+	cfg := config.Load()
+
+	name, path := "live", cfg.DBPath
+	if *sandbox {
+		name, path = "sandbox", cfg.SandboxDBPath
+	}
+
+	db, err := database.NewSQLite(path)
+	if err != nil {
+		log.Fatalf("Failed to connect to database: %v", err)
+	}
+	defer db.Close()
+
+	if err := database.RunMigrations(db, ""); err != nil {
+		log.Fatalf("Failed to run migrations: %v", err)
+	}
+
+	engine := reconcile.NewEngine(
+		repository.NewWalletRepository(db),
+		repository.NewTransactionRepository(db),
+		repository.NewLedgerRepository(db),
+	)
+
+	report, err := engine.Run(reconcile.Options{FreezeDrifted: *freeze, Database: name})
+	if err != nil {
+		log.Fatalf("Reconciliation failed: %v", err)
+	}
+
+	if *format == "json" {
+		err = report.WriteJSON(os.Stdout)
+	} else {
+		err = report.WriteText(os.Stdout)
+	}
+	if err != nil {
+		log.Fatalf("Failed to write report: %v", err)
+	}
+
+	if report.HasDrift() {
+		db.Close()
+		os.Exit(1)
+	}
+}
@@ -1,17 +1,31 @@
 package main
 
 import (
+	"database/sql"
 	"fmt"
 	"log"
+	"time"
 
 	"github.com/BerylCAtieno/paystack-wallet/internal/api/router"
 	"github.com/BerylCAtieno/paystack-wallet/internal/config"
 	"github.com/BerylCAtieno/paystack-wallet/internal/database"
 	"github.com/BerylCAtieno/paystack-wallet/internal/domain/auth"
+	"github.com/BerylCAtieno/paystack-wallet/internal/domain/dispute"
+	"github.com/BerylCAtieno/paystack-wallet/internal/domain/fee"
+	"github.com/BerylCAtieno/paystack-wallet/internal/domain/fx"
+	"github.com/BerylCAtieno/paystack-wallet/internal/domain/idempotency"
+	"github.com/BerylCAtieno/paystack-wallet/internal/domain/limit"
+	"github.com/BerylCAtieno/paystack-wallet/internal/domain/refund"
 	"github.com/BerylCAtieno/paystack-wallet/internal/domain/wallet"
+	"github.com/BerylCAtieno/paystack-wallet/internal/domain/webhook"
+	"github.com/BerylCAtieno/paystack-wallet/internal/paystack"
 	"github.com/BerylCAtieno/paystack-wallet/internal/repository"
+	"github.com/BerylCAtieno/paystack-wallet/internal/settlement"
 )
 
+// holdExpiryBatch is how many expired holds are released per query
+const holdExpiryBatch = 100
+
 func main() {
 	// Load configuration
 	cfg := config.Load()
@@ -30,18 +44,66 @@ func main() {
 
 	log.Println("Database initialized successfully")
 
-	// Initialize repositories
+	// The sandbox that sk_test_ keys operate on keeps its own database
+	sandboxDB, err := database.NewSQLite(cfg.SandboxDBPath)
+	if err != nil {
+		log.Fatalf("Failed to connect to sandbox database: %v", err)
+	}
+	defer sandboxDB.Close()
+
+	if err := database.RunMigrations(sandboxDB, ""); err != nil {
+		log.Fatalf("Failed to run sandbox migrations: %v", err)
+	}
+
+	rateProvider, err := newRateProvider(cfg)
+	if err != nil {
+		log.Fatalf("Failed to set up exchange rates: %v", err)
+	}
+
+	fees, err := newFeeEngine(cfg)
+	if err != nil {
+		log.Fatalf("Failed to load fee rules: %v", err)
+	}
+
+	limits, err := newLimitPolicy(cfg)
+	if err != nil {
+		log.Fatalf("Failed to load limit rules: %v", err)
+	}
+
+	// Users sign in live, so their KYC tier is always read from the live
+	// database, in the sandbox too
 	userRepo := repository.NewUserRepository(db)
-	walletRepo := repository.NewWalletRepository(db)
-	transactionRepo := repository.NewTransactionRepository(db)
-	apiKeyRepo := repository.NewAPIKeyRepository(db)
+	signingSecret := cfg.SigningSecret
+	if signingSecret == "" {
+		log.Println("SIGNING_SECRET is not set, deriving request signing secrets from JWT_SECRET")
+		signingSecret = cfg.JWTSecret
+	}
+	authService := auth.NewService(repository.NewAPIKeyRepository(db), repository.NewAuditRepository(db), signingSecret, cfg.SignatureMaxSkew)
+
+	live := newEnvironment(cfg, db, userRepo, paystack.NewClient(cfg.PaystackSecretKey), rateProvider, fees, limits)
+
+	sandboxPaystack := paystack.NewClient(cfg.PaystackTestKey)
+	var fake *paystack.Fake
+	if cfg.PaystackTestKey == "" {
+		log.Println("PAYSTACK_TEST_SECRET_KEY is not set, the sandbox uses a fake Paystack")
+		fake = paystack.NewFake()
+		sandboxPaystack = fake.Client()
+	}
+	sandbox := newEnvironment(cfg, sandboxDB, userRepo, sandboxPaystack, rateProvider, fees, limits)
+	if fake != nil {
+		// Deliver the fake's webhooks as if Paystack had sent them
+		fake.OnEvent(func(event, paystackID string, payload []byte) {
+			if _, err := sandbox.WebhookService.Record(event, paystackID, payload); err != nil && err != webhook.ErrDuplicate {
+				log.Printf("Failed to store sandbox webhook %s %s: %v", event, paystackID, err)
+			}
+		})
+	}
 
-	// Initialize services
-	walletService := wallet.NewService(walletRepo, transactionRepo)
-	authService := auth.NewService(apiKeyRepo)
+	runWorkers(cfg, live)
+	runWorkers(cfg, sandbox)
 
 	// Initialize router
-	r := router.NewRouter(cfg, authService, walletService, walletRepo, userRepo)
+	r := router.NewRouter(cfg, authService, live, sandbox)
 
 	// Start server
 	addr := fmt.Sprintf("0.0.0.0:%s", cfg.Port)
@@ -50,3 +112,112 @@ func main() {
 		log.Fatalf("Failed to start server: %v", err)
 	}
 }
+
+// newEnvironment builds the repositories and services that hold and move
+// money in db. Tiers are read from users.
+func newEnvironment(
+	cfg *config.Config,
+	db *sql.DB,
+	users *repository.UserRepository,
+	paystackClient *paystack.Client,
+	rateProvider fx.RateProvider,
+	fees *fee.Engine,
+	limits *limit.Policy,
+) *router.Environment {
+	transactionRepo := repository.NewTransactionRepository(db)
+	walletRepo := repository.NewWalletRepository(db)
+	holdRepo := repository.NewHoldRepository(db)
+
+	walletService := wallet.NewService(walletRepo, transactionRepo, holdRepo, users, fees, limits)
+	refundService := refund.NewService(repository.NewRefundRepository(db), transactionRepo)
+	disputeService := dispute.NewService(repository.NewDisputeRepository(db), transactionRepo)
+	processor := settlement.NewProcessor(walletService, refundService, disputeService)
+
+	return &router.Environment{
+		WalletService:      walletService,
+		IdempotencyService: idempotency.NewService(repository.NewIdempotencyRepository(db), cfg.IdempotencyTTL),
+		RefundService:      refundService,
+		FXService:          fx.NewService(rateProvider, repository.NewFXQuoteRepository(db), int64(cfg.FXSpreadBps), cfg.FXQuoteTTL),
+		WebhookService:     webhook.NewService(repository.NewWebhookEventRepository(db), processor, cfg.WebhookMaxAttempts),
+		Sweeper:            settlement.NewSweeper(walletService, paystackClient, cfg.DepositVerifyAfter, cfg.DepositExpiry),
+		Paystack:           paystackClient,
+		Users:              repository.NewUserRepository(db),
+		WalletRepo:         walletRepo,
+		BankRepo:           repository.NewBankRepository(db),
+		BeneficiaryRepo:    repository.NewBeneficiaryRepository(db),
+	}
+}
+
+// runWorkers starts the environment's background jobs
+func runWorkers(cfg *config.Config, env *router.Environment) {
+	// Apply stored Paystack webhooks in the background
+	go env.WebhookService.Run(cfg.WebhookPollInterval)
+
+	// Verify deposits whose webhook never arrived
+	go env.Sweeper.Run(cfg.DepositSweepInterval)
+
+	// Release authorization holds once they expire
+	go func() {
+		for range time.Tick(cfg.HoldExpiryInterval) {
+			for {
+				expired, err := env.WalletService.ExpireHolds(holdExpiryBatch)
+				if err != nil {
+					log.Printf("Failed to expire holds: %v", err)
+				}
+				if err != nil || expired == 0 {
+					break
+				}
+			}
+		}
+	}()
+
+	// Purge expired idempotency keys in the background
+	go func() {
+		for range time.Tick(time.Hour) {
+			if _, err := env.IdempotencyService.PurgeExpired(); err != nil {
+				log.Printf("Failed to purge idempotency keys: %v", err)
+			}
+		}
+	}()
+}
+
+// newRateProvider builds the exchange rate source selected by FX_PROVIDER
+func newRateProvider(cfg *config.Config) (fx.RateProvider, error) {
+	switch cfg.FXProvider {
+	case "local":
+		return fx.NewLocalProvider(fx.DefaultLocalRates), nil
+	case "static":
+		return fx.NewStaticProvider(cfg.FXRatesFile)
+	case "http":
+		if cfg.FXRatesURL == "" {
+			return nil, fmt.Errorf("FX_RATES_URL is required for the http provider")
+		}
+		return fx.NewHTTPProvider(cfg.FXRatesURL, cfg.FXRatesCacheTTL, cfg.FXRatesMaxAge), nil
+	default:
+		return nil, fmt.Errorf("unknown FX_PROVIDER %q", cfg.FXProvider)
+	}
+}
+
+// newFeeEngine loads the fee rules in FEE_RULES_FILE, or the defaults
+func newFeeEngine(cfg *config.Config) (*fee.Engine, error) {
+	rules := fee.DefaultRules
+	if cfg.FeeRulesFile != "" {
+		var err error
+		if rules, err = fee.LoadRules(cfg.FeeRulesFile); err != nil {
+			return nil, err
+		}
+	}
+	return fee.NewEngine(rules)
+}
+
+// newLimitPolicy loads the tier limits in LIMIT_RULES_FILE, or the defaults
+func newLimitPolicy(cfg *config.Config) (*limit.Policy, error) {
+	rules := limit.DefaultRules
+	if cfg.LimitRulesFile != "" {
+		var err error
+		if rules, err = limit.LoadRules(cfg.LimitRulesFile); err != nil {
+			return nil, err
+		}
+	}
+	return limit.NewPolicy(rules)
+}
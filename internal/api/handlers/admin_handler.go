@@ -0,0 +1,118 @@
+package handlers
+
+import (
+	"database/sql"
+	"net/http"
+	"strconv"
+
+	"github.com/BerylCAtieno/paystack-wallet/internal/domain/user"
+	"github.com/BerylCAtieno/paystack-wallet/internal/domain/wallet"
+	"github.com/BerylCAtieno/paystack-wallet/internal/repository"
+	"github.com/BerylCAtieno/paystack-wallet/internal/settlement"
+	"github.com/BerylCAtieno/paystack-wallet/internal/utils"
+	"github.com/gin-gonic/gin"
+)
+
+// AdminHandler serves operator endpoints for deposits that need a manual
+// decision and for users' KYC tiers
+type AdminHandler struct {
+	walletService *wallet.Service
+	sweeper       *settlement.Sweeper
+	userRepo      *repository.UserRepository
+}
+
+func NewAdminHandler(walletService *wallet.Service, sweeper *settlement.Sweeper, userRepo *repository.UserRepository) *AdminHandler {
+	return &AdminHandler{walletService: walletService, sweeper: sweeper, userRepo: userRepo}
+}
+
+func (h *AdminHandler) ListDepositsInReview(c *gin.Context) {
+	limit := 0
+	if raw := c.Query("limit"); raw != "" {
+		var err error
+		if limit, err = strconv.Atoi(raw); err != nil || limit <= 0 {
+			utils.RespondError(c, http.StatusBadRequest, "limit must be a positive integer")
+			return
+		}
+	}
+
+	deposits, err := h.walletService.ListDepositsInReview(limit)
+	if err != nil {
+		utils.RespondError(c, http.StatusInternalServerError, "failed to list deposits")
+		return
+	}
+
+	utils.RespondSuccess(c, deposits)
+}
+
+func (h *AdminHandler) ApproveDeposit(c *gin.Context) {
+	tx, err := h.walletService.ApproveDeposit(c.Param("reference"))
+	if err != nil {
+		respondReviewError(c, err)
+		return
+	}
+
+	utils.RespondSuccess(c, tx)
+}
+
+func (h *AdminHandler) RejectDeposit(c *gin.Context) {
+	tx, err := h.walletService.RejectDeposit(c.Param("reference"))
+	if err != nil {
+		respondReviewError(c, err)
+		return
+	}
+
+	utils.RespondSuccess(c, tx)
+}
+
+// SweeperMetrics reports how many stale deposits the sweeper recovered,
+// failed or expired since the server started
+func (h *AdminHandler) SweeperMetrics(c *gin.Context) {
+	utils.RespondSuccess(c, h.sweeper.Metrics())
+}
+
+type UpdateTierRequest struct {
+	Tier *user.Tier `json:"tier"`
+}
+
+// UpdateTier moves a user to a KYC tier once their documents are checked,
+// which changes their limits from the next operation
+func (h *AdminHandler) UpdateTier(c *gin.Context) {
+	var req UpdateTierRequest
+	if err := c.BindJSON(&req); err != nil {
+		utils.RespondError(c, http.StatusBadRequest, "invalid request body")
+		return
+	}
+
+	if req.Tier == nil || !req.Tier.Valid() {
+		utils.RespondError(c, http.StatusBadRequest, "tier must be 0, 1 or 2")
+		return
+	}
+
+	if err := h.userRepo.UpdateTier(c.Param("id"), *req.Tier); err != nil {
+		if err == sql.ErrNoRows {
+			utils.RespondError(c, http.StatusNotFound, "user not found")
+			return
+		}
+		utils.RespondError(c, http.StatusInternalServerError, "failed to update tier")
+		return
+	}
+
+	u, err := h.userRepo.GetByID(c.Param("id"))
+	if err != nil {
+		utils.RespondError(c, http.StatusInternalServerError, "failed to update tier")
+		return
+	}
+
+	utils.RespondSuccess(c, u)
+}
+
+func respondReviewError(c *gin.Context, err error) {
+	switch err {
+	case wallet.ErrTransactionNotFound:
+		utils.RespondError(c, http.StatusNotFound, "deposit not found")
+	case wallet.ErrNotInReview, wallet.ErrCurrencyMismatch, wallet.ErrInvalidAmount:
+		utils.RespondError(c, http.StatusConflict, err.Error())
+	default:
+		utils.RespondError(c, http.StatusInternalServerError, "failed to update deposit")
+	}
+}
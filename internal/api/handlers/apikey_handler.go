@@ -1,6 +1,8 @@
 package handlers
 
 import (
+	"errors"
+
 	"github.com/BerylCAtieno/paystack-wallet/internal/api/middleware"
 	"github.com/BerylCAtieno/paystack-wallet/internal/domain/auth"
 	"github.com/BerylCAtieno/paystack-wallet/internal/utils"
@@ -15,14 +17,87 @@ func NewAPIKeyHandler(authService *auth.Service) *APIKeyHandler {
 	return &APIKeyHandler{authService: authService}
 }
 
+// managingKey returns the API key a request to manage keys authenticated
+// with, or nil for JWT users. Such keys hold keys:manage; they only see and
+// manage keys of their own mode and can only hand out permissions and IP
+// ranges they hold themselves, so a sandbox or automation key can never
+// mint itself more power.
+func managingKey(c *gin.Context) *auth.APIKey {
+	if middleware.GetUserID(c) != "" {
+		return nil
+	}
+	return middleware.GetAPIKey(c)
+}
+
+// manageable reports whether the caller may see and manage key
+func manageable(c *gin.Context, key *auth.APIKey) bool {
+	manager := managingKey(c)
+	return manager == nil || key.Mode == manager.Mode
+}
+
+// checkGrantable returns the allowlist a key with the given mode,
+// permissions and requested allowlist gets. It writes an error response and
+// returns false if an API key is creating a key it may not create.
+func checkGrantable(c *gin.Context, mode auth.KeyMode, permissions []auth.Permission, allowedIPs []string) ([]string, bool) {
+	manager := managingKey(c)
+	if manager == nil {
+		return allowedIPs, true
+	}
+
+	if mode != manager.Mode {
+		utils.RespondError(c, 403, "api keys can only manage keys of their own mode")
+		return nil, false
+	}
+	if err := manager.CanGrant(permissions); err != nil {
+		respondDelegationError(c, err)
+		return nil, false
+	}
+
+	allowedIPs, err := manager.DelegatedIPs(allowedIPs)
+	if err != nil {
+		respondDelegationError(c, err)
+		return nil, false
+	}
+	return allowedIPs, true
+}
+
+// checkControl reports whether the caller may revoke key or change its
+// allowlist, writing an error response if not. An API key may only change
+// itself and keys it could have created, so it cannot loosen or revoke a
+// key holding permissions it lacks.
+func checkControl(c *gin.Context, key *auth.APIKey) bool {
+	manager := managingKey(c)
+	if manager == nil || manager.ID == key.ID {
+		return true
+	}
+	if err := manager.CanGrant(key.Permissions); err != nil {
+		utils.RespondError(c, 403, err.Error())
+		return false
+	}
+	return true
+}
+
+func respondDelegationError(c *gin.Context, err error) {
+	if errors.Is(err, auth.ErrInvalidPermissions) || errors.Is(err, auth.ErrInvalidAllowedIPs) {
+		utils.RespondError(c, 400, err.Error())
+		return
+	}
+	utils.RespondError(c, 403, err.Error())
+}
+
 type CreateAPIKeyRequest struct {
-	Name        string              `json:"name"`
-	Permissions []auth.Permission   `json:"permissions"`
-	Expiry      auth.ExpiryDuration `json:"expiry"`
+	Name string `json:"name"`
+	// Mode is live or test; test keys only reach sandbox wallets
+	Mode        auth.KeyMode      `json:"mode"`
+	Permissions []auth.Permission `json:"permissions"`
+	// AllowedIPs are CIDR ranges or single IPs the key may be used from;
+	// any IP when empty
+	AllowedIPs []string            `json:"allowed_ips"`
+	Expiry     auth.ExpiryDuration `json:"expiry"`
 }
 
 func (h *APIKeyHandler) CreateAPIKey(c *gin.Context) {
-	userID := middleware.GetUserID(c)
+	userID := requestUserID(c)
 	if userID == "" {
 		utils.RespondError(c, 401, "user not authenticated")
 		return
@@ -46,9 +121,19 @@ func (h *APIKeyHandler) CreateAPIKey(c *gin.Context) {
 		return
 	}
 
-	apiKey, rawKey, err := h.authService.CreateAPIKey(userID, req.Name, req.Permissions, req.Expiry)
+	if manager := managingKey(c); manager != nil && req.Mode == "" {
+		req.Mode = manager.Mode
+	}
+	allowedIPs, ok := checkGrantable(c, req.Mode, req.Permissions, req.AllowedIPs)
+	if !ok {
+		return
+	}
+	req.AllowedIPs = allowedIPs
+
+	apiKey, rawKey, err := h.authService.CreateAPIKey(userID, req.Name, req.Mode, req.Permissions, req.AllowedIPs, req.Expiry)
 	if err != nil {
-		if err == auth.ErrMaxAPIKeysReached {
+		if err == auth.ErrMaxAPIKeysReached || err == auth.ErrInvalidKeyMode ||
+			errors.Is(err, auth.ErrInvalidPermissions) || errors.Is(err, auth.ErrInvalidAllowedIPs) {
 			utils.RespondError(c, 400, err.Error())
 			return
 		}
@@ -57,8 +142,13 @@ func (h *APIKeyHandler) CreateAPIKey(c *gin.Context) {
 	}
 
 	utils.RespondSuccess(c, map[string]interface{}{
-		"api_key":    rawKey,
-		"expires_at": apiKey.ExpiresAt,
+		"id":             apiKey.ID,
+		"api_key":        rawKey,
+		"signing_secret": h.authService.SigningSecret(apiKey),
+		"key_prefix":     apiKey.KeyPrefix,
+		"mode":           apiKey.Mode,
+		"allowed_ips":    apiKey.AllowedIPs,
+		"expires_at":     apiKey.ExpiresAt,
 	})
 }
 
@@ -68,7 +158,7 @@ type RolloverAPIKeyRequest struct {
 }
 
 func (h *APIKeyHandler) RolloverAPIKey(c *gin.Context) {
-	userID := middleware.GetUserID(c)
+	userID := requestUserID(c)
 	if userID == "" {
 		utils.RespondError(c, 401, "user not authenticated")
 		return
@@ -80,6 +170,21 @@ func (h *APIKeyHandler) RolloverAPIKey(c *gin.Context) {
 		return
 	}
 
+	oldKey, err := h.authService.GetAPIKey(userID, req.ExpiredKeyID)
+	if err != nil || !manageable(c, oldKey) {
+		utils.RespondError(c, 404, auth.ErrKeyNotFound.Error())
+		return
+	}
+	// The new key keeps the old key's allowlist, which must not be wider
+	// than the managing key's own
+	if manager := managingKey(c); manager != nil && len(oldKey.AllowedIPs) == 0 && len(manager.AllowedIPs) > 0 {
+		utils.RespondError(c, 403, auth.ErrAllowedIPsExceedKey.Error())
+		return
+	}
+	if _, ok := checkGrantable(c, oldKey.Mode, oldKey.Permissions, oldKey.AllowedIPs); !ok {
+		return
+	}
+
 	apiKey, rawKey, err := h.authService.RolloverAPIKey(userID, req.ExpiredKeyID, req.Expiry)
 	if err != nil {
 		if err == auth.ErrKeyNotExpired {
@@ -91,7 +196,192 @@ func (h *APIKeyHandler) RolloverAPIKey(c *gin.Context) {
 	}
 
 	utils.RespondSuccess(c, map[string]interface{}{
-		"api_key":    rawKey,
-		"expires_at": apiKey.ExpiresAt,
+		"id":             apiKey.ID,
+		"api_key":        rawKey,
+		"signing_secret": h.authService.SigningSecret(apiKey),
+		"key_prefix":     apiKey.KeyPrefix,
+		"mode":           apiKey.Mode,
+		"allowed_ips":    apiKey.AllowedIPs,
+		"expires_at":     apiKey.ExpiresAt,
+	})
+}
+
+// APIKeyResponse is a key's metadata with its current status. The key
+// itself is only ever returned when it is created.
+type APIKeyResponse struct {
+	*auth.APIKey
+	Status auth.KeyStatus `json:"status"`
+}
+
+func newAPIKeyResponse(key *auth.APIKey) APIKeyResponse {
+	return APIKeyResponse{APIKey: key, Status: key.Status()}
+}
+
+func (h *APIKeyHandler) ListAPIKeys(c *gin.Context) {
+	userID := requestUserID(c)
+	if userID == "" {
+		utils.RespondError(c, 401, "user not authenticated")
+		return
+	}
+
+	keys, err := h.authService.ListAPIKeys(userID)
+	if err != nil {
+		utils.RespondError(c, 500, "failed to list API keys")
+		return
+	}
+
+	response := make([]APIKeyResponse, 0, len(keys))
+	for _, key := range keys {
+		if manageable(c, key) {
+			response = append(response, newAPIKeyResponse(key))
+		}
+	}
+
+	utils.RespondSuccess(c, response)
+}
+
+func (h *APIKeyHandler) GetAPIKey(c *gin.Context) {
+	userID := requestUserID(c)
+	if userID == "" {
+		utils.RespondError(c, 401, "user not authenticated")
+		return
+	}
+
+	key, err := h.authService.GetAPIKey(userID, c.Param("id"))
+	if err != nil || !manageable(c, key) {
+		utils.RespondError(c, 404, auth.ErrKeyNotFound.Error())
+		return
+	}
+
+	utils.RespondSuccess(c, newAPIKeyResponse(key))
+}
+
+// RevokeAPIKey disables a key straight away, e.g. when it has leaked
+func (h *APIKeyHandler) RevokeAPIKey(c *gin.Context) {
+	userID := requestUserID(c)
+	if userID == "" {
+		utils.RespondError(c, 401, "user not authenticated")
+		return
+	}
+
+	key, err := h.authService.GetAPIKey(userID, c.Param("id"))
+	if err != nil || !manageable(c, key) {
+		utils.RespondError(c, 404, auth.ErrKeyNotFound.Error())
+		return
+	}
+	if !checkControl(c, key) {
+		return
+	}
+
+	key, err = h.authService.RevokeAPIKey(userID, key.ID)
+	if err != nil {
+		if err == auth.ErrKeyNotFound {
+			utils.RespondError(c, 404, err.Error())
+			return
+		}
+		utils.RespondError(c, 500, "failed to revoke API key")
+		return
+	}
+
+	utils.RespondSuccess(c, newAPIKeyResponse(key))
+}
+
+// GetSigningSecret returns the secret requests for a key are signed with,
+// for keys created before signing secrets were returned with the key. Only
+// signed in users may read it, so a key holding keys:manage cannot sign
+// requests as another key.
+func (h *APIKeyHandler) GetSigningSecret(c *gin.Context) {
+	userID := requestUserID(c)
+	if userID == "" {
+		utils.RespondError(c, 401, "user not authenticated")
+		return
+	}
+	if managingKey(c) != nil {
+		utils.RespondError(c, 403, "signing secrets can only be read by a signed in user")
+		return
+	}
+
+	key, err := h.authService.GetAPIKey(userID, c.Param("id"))
+	if err != nil {
+		utils.RespondError(c, 404, auth.ErrKeyNotFound.Error())
+		return
+	}
+
+	utils.RespondSuccess(c, map[string]interface{}{
+		"id":             key.ID,
+		"signing_secret": h.authService.SigningSecret(key),
 	})
 }
+
+type SetAllowedIPsRequest struct {
+	// AllowedIPs replaces the key's allowlist; an empty list lets the key
+	// be used from any IP
+	AllowedIPs []string `json:"allowed_ips"`
+}
+
+// SetAllowedIPs replaces the IP ranges a key may be used from
+func (h *APIKeyHandler) SetAllowedIPs(c *gin.Context) {
+	userID := requestUserID(c)
+	if userID == "" {
+		utils.RespondError(c, 401, "user not authenticated")
+		return
+	}
+
+	var req SetAllowedIPsRequest
+	if err := c.BindJSON(&req); err != nil {
+		utils.RespondError(c, 400, "invalid request body")
+		return
+	}
+
+	key, err := h.authService.GetAPIKey(userID, c.Param("id"))
+	if err != nil || !manageable(c, key) {
+		utils.RespondError(c, 404, auth.ErrKeyNotFound.Error())
+		return
+	}
+	if !checkControl(c, key) {
+		return
+	}
+
+	if manager := managingKey(c); manager != nil {
+		if req.AllowedIPs, err = manager.DelegatedIPs(req.AllowedIPs); err != nil {
+			respondDelegationError(c, err)
+			return
+		}
+	}
+
+	key, err = h.authService.SetAllowedIPs(userID, key.ID, req.AllowedIPs)
+	if err != nil {
+		if errors.Is(err, auth.ErrInvalidAllowedIPs) {
+			utils.RespondError(c, 400, err.Error())
+			return
+		}
+		utils.RespondError(c, 500, "failed to update allowed IPs")
+		return
+	}
+
+	utils.RespondSuccess(c, newAPIKeyResponse(key))
+}
+
+// ListAuditEvents returns the latest requests the key was refused for, such
+// as uses from outside its allowlist
+func (h *APIKeyHandler) ListAuditEvents(c *gin.Context) {
+	userID := requestUserID(c)
+	if userID == "" {
+		utils.RespondError(c, 401, "user not authenticated")
+		return
+	}
+
+	key, err := h.authService.GetAPIKey(userID, c.Param("id"))
+	if err != nil || !manageable(c, key) {
+		utils.RespondError(c, 404, auth.ErrKeyNotFound.Error())
+		return
+	}
+
+	events, err := h.authService.ListAuditEvents(userID, key.ID)
+	if err != nil {
+		utils.RespondError(c, 500, "failed to list audit events")
+		return
+	}
+
+	utils.RespondSuccess(c, events)
+}
@@ -8,6 +8,7 @@ import (
 
 	"github.com/BerylCAtieno/paystack-wallet/internal/domain/user"
 	"github.com/BerylCAtieno/paystack-wallet/internal/domain/wallet"
+	"github.com/BerylCAtieno/paystack-wallet/internal/money"
 	"github.com/BerylCAtieno/paystack-wallet/internal/repository"
 	"github.com/BerylCAtieno/paystack-wallet/internal/security"
 	"github.com/BerylCAtieno/paystack-wallet/internal/utils"
@@ -98,7 +99,7 @@ func (h *AuthHandler) GoogleCallback(c *gin.Context) {
 			return
 		}
 
-		if _, err := h.walletService.CreateWallet(u.ID); err != nil {
+		if _, err := h.walletService.CreateWallet(u.ID, money.DefaultCurrency); err != nil {
 			utils.RespondError(c, 500, "failed to create wallet")
 			return
 		}
@@ -0,0 +1,62 @@
+package handlers
+
+import (
+	"strings"
+
+	"github.com/BerylCAtieno/paystack-wallet/internal/domain/bank"
+	"github.com/BerylCAtieno/paystack-wallet/internal/utils"
+	"github.com/gin-gonic/gin"
+)
+
+type BankHandler struct {
+	bankService *bank.Service
+}
+
+func NewBankHandler(bankService *bank.Service) *BankHandler {
+	return &BankHandler{bankService: bankService}
+}
+
+func (h *BankHandler) ListBanks(c *gin.Context) {
+	currency := strings.ToUpper(c.DefaultQuery("currency", "NGN"))
+
+	banks, err := h.bankService.ListBanks(currency)
+	if err != nil {
+		utils.RespondError(c, 502, "failed to fetch banks")
+		return
+	}
+
+	utils.RespondSuccess(c, banks)
+}
+
+type ResolveAccountRequest struct {
+	AccountNumber string `json:"account_number"`
+	BankCode      string `json:"bank_code"`
+}
+
+func (h *BankHandler) ResolveAccount(c *gin.Context) {
+	var req ResolveAccountRequest
+	if err := c.BindJSON(&req); err != nil {
+		utils.RespondError(c, 400, "invalid request body")
+		return
+	}
+
+	if req.AccountNumber == "" || req.BankCode == "" {
+		utils.RespondError(c, 400, "account_number and bank_code are required")
+		return
+	}
+
+	resolution, err := h.bankService.ResolveAccount(req.AccountNumber, req.BankCode)
+	if err != nil {
+		switch err {
+		case bank.ErrInvalidAccountNumber:
+			utils.RespondError(c, 400, err.Error())
+		case bank.ErrAccountNotResolved:
+			utils.RespondError(c, 422, err.Error())
+		default:
+			utils.RespondError(c, 500, "failed to resolve account")
+		}
+		return
+	}
+
+	utils.RespondSuccess(c, resolution)
+}
@@ -0,0 +1,173 @@
+package handlers
+
+import (
+	"github.com/BerylCAtieno/paystack-wallet/internal/domain/auth"
+	"github.com/BerylCAtieno/paystack-wallet/internal/domain/bank"
+	"github.com/BerylCAtieno/paystack-wallet/internal/domain/beneficiary"
+	"github.com/BerylCAtieno/paystack-wallet/internal/domain/wallet"
+	"github.com/BerylCAtieno/paystack-wallet/internal/repository"
+	"github.com/BerylCAtieno/paystack-wallet/internal/utils"
+	"github.com/gin-gonic/gin"
+)
+
+type BeneficiaryHandler struct {
+	beneficiaryService *beneficiary.Service
+	walletRepo         *repository.WalletRepository
+}
+
+func NewBeneficiaryHandler(beneficiaryService *beneficiary.Service, walletRepo *repository.WalletRepository) *BeneficiaryHandler {
+	return &BeneficiaryHandler{
+		beneficiaryService: beneficiaryService,
+		walletRepo:         walletRepo,
+	}
+}
+
+// walletID authenticates the request, checks the permission and returns the
+// ID of the caller's wallet in the currency query parameter. It writes the
+// error response and returns "" on failure.
+func (h *BeneficiaryHandler) walletID(c *gin.Context, perm auth.Permission) string {
+	userID := requestUserID(c)
+	if userID == "" {
+		utils.RespondError(c, 401, "user not authenticated")
+		return ""
+	}
+
+	if !hasPermission(c, perm) {
+		utils.RespondError(c, 403, "insufficient permissions")
+		return ""
+	}
+
+	userWallet := requestWallet(c, h.walletRepo, userID, c.Query("currency"))
+	if userWallet == nil {
+		return ""
+	}
+
+	return userWallet.ID
+}
+
+type CreateBeneficiaryRequest struct {
+	Type          beneficiary.Type `json:"type"`
+	Nickname      string           `json:"nickname"`
+	WalletNumber  string           `json:"wallet_number"`
+	AccountNumber string           `json:"account_number"`
+	BankCode      string           `json:"bank_code"`
+}
+
+func (h *BeneficiaryHandler) Create(c *gin.Context) {
+	walletID := h.walletID(c, auth.PermissionTransfer)
+	if walletID == "" {
+		return
+	}
+
+	var req CreateBeneficiaryRequest
+	if err := c.BindJSON(&req); err != nil {
+		utils.RespondError(c, 400, "invalid request body")
+		return
+	}
+
+	b, err := h.beneficiaryService.Create(walletID, beneficiary.CreateInput{
+		Type:          req.Type,
+		Nickname:      req.Nickname,
+		WalletNumber:  req.WalletNumber,
+		AccountNumber: req.AccountNumber,
+		BankCode:      req.BankCode,
+	})
+	if err != nil {
+		switch err {
+		case beneficiary.ErrInvalidType, beneficiary.ErrMissingDetails, beneficiary.ErrOwnWallet,
+			beneficiary.ErrRecipientNotFound, bank.ErrInvalidAccountNumber, wallet.ErrInvalidWalletNumber:
+			utils.RespondError(c, 400, err.Error())
+		case beneficiary.ErrDuplicate:
+			utils.RespondError(c, 409, err.Error())
+		case bank.ErrAccountNotResolved:
+			utils.RespondError(c, 422, err.Error())
+		default:
+			utils.RespondError(c, 500, "failed to save beneficiary")
+		}
+		return
+	}
+
+	utils.RespondSuccess(c, b)
+}
+
+func (h *BeneficiaryHandler) List(c *gin.Context) {
+	walletID := h.walletID(c, auth.PermissionRead)
+	if walletID == "" {
+		return
+	}
+
+	beneficiaries, err := h.beneficiaryService.List(walletID)
+	if err != nil {
+		utils.RespondError(c, 500, "failed to get beneficiaries")
+		return
+	}
+
+	utils.RespondSuccess(c, beneficiaries)
+}
+
+func (h *BeneficiaryHandler) Get(c *gin.Context) {
+	walletID := h.walletID(c, auth.PermissionRead)
+	if walletID == "" {
+		return
+	}
+
+	b, err := h.beneficiaryService.Get(walletID, c.Param("id"))
+	if err != nil {
+		utils.RespondError(c, 404, err.Error())
+		return
+	}
+
+	utils.RespondSuccess(c, b)
+}
+
+type UpdateBeneficiaryRequest struct {
+	Nickname string `json:"nickname"`
+}
+
+func (h *BeneficiaryHandler) Update(c *gin.Context) {
+	walletID := h.walletID(c, auth.PermissionTransfer)
+	if walletID == "" {
+		return
+	}
+
+	var req UpdateBeneficiaryRequest
+	if err := c.BindJSON(&req); err != nil {
+		utils.RespondError(c, 400, "invalid request body")
+		return
+	}
+
+	b, err := h.beneficiaryService.Rename(walletID, c.Param("id"), req.Nickname)
+	if err != nil {
+		switch err {
+		case beneficiary.ErrNotFound:
+			utils.RespondError(c, 404, err.Error())
+		case beneficiary.ErrMissingDetails:
+			utils.RespondError(c, 400, "nickname is required")
+		default:
+			utils.RespondError(c, 500, "failed to update beneficiary")
+		}
+		return
+	}
+
+	utils.RespondSuccess(c, b)
+}
+
+func (h *BeneficiaryHandler) Delete(c *gin.Context) {
+	walletID := h.walletID(c, auth.PermissionTransfer)
+	if walletID == "" {
+		return
+	}
+
+	if err := h.beneficiaryService.Delete(walletID, c.Param("id")); err != nil {
+		if err == beneficiary.ErrNotFound {
+			utils.RespondError(c, 404, err.Error())
+			return
+		}
+		utils.RespondError(c, 500, "failed to delete beneficiary")
+		return
+	}
+
+	utils.RespondSuccess(c, map[string]interface{}{
+		"deleted": true,
+	})
+}
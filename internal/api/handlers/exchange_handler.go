@@ -0,0 +1,217 @@
+package handlers
+
+import (
+	"errors"
+
+	"github.com/BerylCAtieno/paystack-wallet/internal/domain/auth"
+	"github.com/BerylCAtieno/paystack-wallet/internal/domain/fx"
+	"github.com/BerylCAtieno/paystack-wallet/internal/domain/wallet"
+	"github.com/BerylCAtieno/paystack-wallet/internal/money"
+	"github.com/BerylCAtieno/paystack-wallet/internal/repository"
+	"github.com/BerylCAtieno/paystack-wallet/internal/utils"
+	"github.com/gin-gonic/gin"
+)
+
+type ExchangeHandler struct {
+	fxService     *fx.Service
+	walletService *wallet.Service
+	walletRepo    *repository.WalletRepository
+}
+
+func NewExchangeHandler(fxService *fx.Service, walletService *wallet.Service, walletRepo *repository.WalletRepository) *ExchangeHandler {
+	return &ExchangeHandler{
+		fxService:     fxService,
+		walletService: walletService,
+		walletRepo:    walletRepo,
+	}
+}
+
+// userID authenticates the request and checks the permission. It writes the
+// error response and returns "" on failure.
+func (h *ExchangeHandler) userID(c *gin.Context, perm auth.Permission) string {
+	userID := requestUserID(c)
+	if userID == "" {
+		utils.RespondError(c, 401, "user not authenticated")
+		return ""
+	}
+
+	if !hasPermission(c, perm) {
+		utils.RespondError(c, 403, "insufficient permissions")
+		return ""
+	}
+
+	return userID
+}
+
+type ExchangeQuoteRequest struct {
+	// From selects the wallet to sell from, NGN when omitted
+	From string `json:"from"`
+	To   string `json:"to"`
+	// Amount is what leaves the From wallet, in its minor unit
+	Amount int64 `json:"amount"`
+}
+
+// Quote locks a rate for converting between two of the caller's currencies.
+// The target wallet does not need to exist yet.
+func (h *ExchangeHandler) Quote(c *gin.Context) {
+	userID := h.userID(c, auth.PermissionTransfer)
+	if userID == "" {
+		return
+	}
+
+	var req ExchangeQuoteRequest
+	if err := c.BindJSON(&req); err != nil {
+		utils.RespondError(c, 400, "invalid request body")
+		return
+	}
+
+	from := requestWallet(c, h.walletRepo, userID, req.From)
+	if from == nil {
+		return
+	}
+
+	to, err := money.ParseCurrency(req.To)
+	if err != nil {
+		utils.RespondError(c, 400, unsupportedCurrencyMessage)
+		return
+	}
+
+	amount := money.New(req.Amount, from.Currency)
+	if !authorize(c, auth.Action{Permission: auth.PermissionTransfer, Amount: amount}) {
+		return
+	}
+
+	quote, err := h.fxService.Quote(userID, amount, to)
+	if err != nil {
+		respondFXError(c, err, "failed to create quote")
+		return
+	}
+
+	utils.RespondSuccess(c, quote)
+}
+
+func (h *ExchangeHandler) GetQuote(c *gin.Context) {
+	userID := h.userID(c, auth.PermissionRead)
+	if userID == "" {
+		return
+	}
+
+	quote, err := h.fxService.GetQuote(userID, c.Param("id"))
+	if err != nil {
+		respondFXError(c, err, "failed to get quote")
+		return
+	}
+
+	utils.RespondSuccess(c, quote)
+}
+
+type ExchangeRequest struct {
+	QuoteID string `json:"quote_id"`
+}
+
+// Exchange converts money between the caller's wallets at a quoted rate,
+// opening the target wallet if the caller does not hold that currency yet.
+func (h *ExchangeHandler) Exchange(c *gin.Context) {
+	userID := h.userID(c, auth.PermissionTransfer)
+	if userID == "" {
+		return
+	}
+
+	var req ExchangeRequest
+	if err := c.BindJSON(&req); err != nil {
+		utils.RespondError(c, 400, "invalid request body")
+		return
+	}
+
+	if req.QuoteID == "" {
+		utils.RespondError(c, 400, "quote_id is required")
+		return
+	}
+
+	quote, err := h.fxService.OpenQuote(userID, req.QuoteID)
+	if err != nil {
+		respondFXError(c, err, "failed to get quote")
+		return
+	}
+
+	from := requestWallet(c, h.walletRepo, userID, string(quote.Source.Currency))
+	if from == nil {
+		return
+	}
+
+	if !authorize(c, auth.Action{Permission: auth.PermissionTransfer, Amount: quote.Source}) {
+		return
+	}
+
+	to, err := h.walletService.GetOrCreateWallet(userID, quote.Target.Currency)
+	if err != nil {
+		utils.RespondError(c, 500, "failed to open wallet")
+		return
+	}
+
+	conversion := quoteConversion(quote)
+	tx, err := h.walletService.Exchange(from.ID, to.ID, conversion)
+	if err != nil {
+		respondConversionError(c, err, "exchange failed")
+		return
+	}
+
+	utils.RespondSuccess(c, map[string]interface{}{
+		"status":    "success",
+		"message":   "Exchange completed",
+		"reference": tx.Reference,
+		"quote_id":  quote.ID,
+		"source":    conversion.Source,
+		"spread":    conversion.Spread,
+		"target":    conversion.Target,
+		"rate":      conversion.Rate,
+	})
+}
+
+// quoteConversion applies a quote to money moving between wallets
+func quoteConversion(q *fx.Quote) wallet.Conversion {
+	return wallet.Conversion{
+		QuoteID: q.ID,
+		Source:  q.Source,
+		Spread:  q.Spread,
+		Target:  q.Target,
+		Rate:    q.Rate,
+	}
+}
+
+func respondFXError(c *gin.Context, err error, fallback string) {
+	switch {
+	case errors.Is(err, fx.ErrQuoteNotFound):
+		utils.RespondError(c, 404, err.Error())
+	case errors.Is(err, fx.ErrQuoteExpired), errors.Is(err, fx.ErrQuoteUsed):
+		utils.RespondError(c, 409, err.Error())
+	case errors.Is(err, fx.ErrInvalidAmount):
+		utils.RespondError(c, 400, "amount must be greater than 0")
+	case errors.Is(err, fx.ErrSameCurrency), errors.Is(err, fx.ErrAmountTooSmall):
+		utils.RespondError(c, 400, err.Error())
+	case errors.Is(err, money.ErrUnsupportedCurrency):
+		utils.RespondError(c, 400, unsupportedCurrencyMessage)
+	case errors.Is(err, fx.ErrRateUnavailable):
+		utils.RespondError(c, 503, fx.ErrRateUnavailable.Error())
+	default:
+		utils.RespondError(c, 500, fallback)
+	}
+}
+
+// respondConversionError maps the errors of moving money at a quoted rate
+func respondConversionError(c *gin.Context, err error, fallback string) {
+	switch err {
+	case wallet.ErrInsufficientBalance:
+		utils.RespondError(c, 400, "insufficient balance")
+	case wallet.ErrWalletNotFound:
+		utils.RespondError(c, 404, err.Error())
+	case wallet.ErrWalletFrozen:
+		utils.RespondError(c, 403, err.Error())
+	case wallet.ErrQuoteUnavailable:
+		utils.RespondError(c, 409, err.Error())
+	case wallet.ErrQuoteMismatch, wallet.ErrSelfTransfer, wallet.ErrInvalidAmount:
+		utils.RespondError(c, 400, err.Error())
+	default:
+		utils.RespondError(c, 500, fallback)
+	}
+}
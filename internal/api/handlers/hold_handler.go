@@ -0,0 +1,257 @@
+package handlers
+
+import (
+	"time"
+
+	"github.com/BerylCAtieno/paystack-wallet/internal/domain/auth"
+	"github.com/BerylCAtieno/paystack-wallet/internal/domain/wallet"
+	"github.com/BerylCAtieno/paystack-wallet/internal/identifier"
+	"github.com/BerylCAtieno/paystack-wallet/internal/money"
+	"github.com/BerylCAtieno/paystack-wallet/internal/repository"
+	"github.com/BerylCAtieno/paystack-wallet/internal/utils"
+	"github.com/gin-gonic/gin"
+)
+
+type HoldHandler struct {
+	walletService *wallet.Service
+	walletRepo    *repository.WalletRepository
+	defaultTTL    time.Duration
+}
+
+func NewHoldHandler(walletService *wallet.Service, walletRepo *repository.WalletRepository, defaultTTL time.Duration) *HoldHandler {
+	return &HoldHandler{
+		walletService: walletService,
+		walletRepo:    walletRepo,
+		defaultTTL:    defaultTTL,
+	}
+}
+
+// userID authenticates the request and checks the permission. It writes the
+// error response and returns "" on failure.
+func (h *HoldHandler) userID(c *gin.Context, perm auth.Permission) string {
+	userID := requestUserID(c)
+	if userID == "" {
+		utils.RespondError(c, 401, "user not authenticated")
+		return ""
+	}
+
+	if !hasPermission(c, perm) {
+		utils.RespondError(c, 403, "insufficient permissions")
+		return ""
+	}
+
+	return userID
+}
+
+// userWallet returns the caller's wallet in the currency query parameter.
+// It writes the error response and returns nil on failure.
+func (h *HoldHandler) userWallet(c *gin.Context, perm auth.Permission) *wallet.Wallet {
+	userID := h.userID(c, perm)
+	if userID == "" {
+		return nil
+	}
+
+	return requestWallet(c, h.walletRepo, userID, c.Query("currency"))
+}
+
+// hold returns the hold named in the path if it is on one of the caller's
+// wallets. It writes the error response and returns nil on failure.
+func (h *HoldHandler) hold(c *gin.Context, perm auth.Permission) *wallet.Hold {
+	userID := h.userID(c, perm)
+	if userID == "" {
+		return nil
+	}
+
+	hold, err := h.walletService.GetUserHold(userID, c.Param("id"))
+	if err != nil {
+		utils.RespondError(c, 404, err.Error())
+		return nil
+	}
+
+	return hold
+}
+
+type PlaceHoldRequest struct {
+	Amount int64 `json:"amount"`
+	// WalletNumber is the wallet paid when the hold is captured
+	WalletNumber string `json:"wallet_number"`
+	Reason       string `json:"reason"`
+	Reference    string `json:"reference"`
+	// ExpiresIn is the hold's lifetime in seconds
+	ExpiresIn int64 `json:"expires_in"`
+}
+
+func (h *HoldHandler) Place(c *gin.Context) {
+	userWallet := h.userWallet(c, auth.PermissionTransfer)
+	if userWallet == nil {
+		return
+	}
+
+	var req PlaceHoldRequest
+	if err := c.BindJSON(&req); err != nil {
+		utils.RespondError(c, 400, "invalid request body")
+		return
+	}
+
+	if req.Amount <= 0 {
+		utils.RespondError(c, 400, "amount must be greater than 0")
+		return
+	}
+
+	if !identifier.ValidWalletNumber(req.WalletNumber) {
+		utils.RespondError(c, 400, wallet.ErrInvalidWalletNumber.Error())
+		return
+	}
+
+	action := auth.Action{
+		Permission: auth.PermissionTransfer,
+		Amount:     money.New(req.Amount, userWallet.Currency),
+		Recipient:  req.WalletNumber,
+	}
+	if !authorize(c, action) {
+		return
+	}
+
+	ttl := h.defaultTTL
+	if req.ExpiresIn != 0 {
+		ttl = time.Duration(req.ExpiresIn) * time.Second
+	}
+
+	hold, err := h.walletService.PlaceHold(userWallet.ID, wallet.HoldRequest{
+		Amount:          req.Amount,
+		Reason:          req.Reason,
+		Reference:       req.Reference,
+		RecipientWallet: req.WalletNumber,
+		TTL:             ttl,
+	})
+	if err != nil {
+		switch err {
+		case wallet.ErrInsufficientBalance:
+			utils.RespondError(c, 400, "insufficient balance")
+		case wallet.ErrWalletNotFound:
+			utils.RespondError(c, 400, "recipient wallet not found")
+		case wallet.ErrSelfTransfer, wallet.ErrInvalidHoldExpiry, wallet.ErrInvalidAmount, wallet.ErrCrossCurrencyTransfer:
+			utils.RespondError(c, 400, err.Error())
+		case wallet.ErrWalletFrozen:
+			utils.RespondError(c, 403, err.Error())
+		case wallet.ErrHoldReferenceConflict:
+			utils.RespondError(c, 409, err.Error())
+		default:
+			utils.RespondError(c, 500, "failed to place hold")
+		}
+		return
+	}
+
+	utils.RespondSuccess(c, hold)
+}
+
+func (h *HoldHandler) List(c *gin.Context) {
+	userWallet := h.userWallet(c, auth.PermissionRead)
+	if userWallet == nil {
+		return
+	}
+
+	holds, err := h.walletService.ListHolds(userWallet.ID, wallet.HoldStatus(c.Query("status")))
+	if err != nil {
+		if err == wallet.ErrInvalidFilter {
+			utils.RespondError(c, 400, "status must be active, captured, released or expired")
+			return
+		}
+		utils.RespondError(c, 500, "failed to list holds")
+		return
+	}
+
+	utils.RespondSuccess(c, holds)
+}
+
+func (h *HoldHandler) Get(c *gin.Context) {
+	hold := h.hold(c, auth.PermissionRead)
+	if hold == nil {
+		return
+	}
+
+	utils.RespondSuccess(c, hold)
+}
+
+type CaptureHoldRequest struct {
+	// Amount defaults to the whole hold
+	Amount int64 `json:"amount"`
+}
+
+func (h *HoldHandler) Capture(c *gin.Context) {
+	hold := h.hold(c, auth.PermissionTransfer)
+	if hold == nil {
+		return
+	}
+
+	var req CaptureHoldRequest
+	if c.Request.ContentLength > 0 {
+		if err := c.BindJSON(&req); err != nil {
+			utils.RespondError(c, 400, "invalid request body")
+			return
+		}
+	}
+
+	holdWallet, err := h.walletRepo.GetByID(hold.WalletID)
+	if err != nil {
+		utils.RespondError(c, 500, "failed to capture hold")
+		return
+	}
+
+	amount := req.Amount
+	if amount == 0 {
+		amount = hold.Amount
+	}
+	action := auth.Action{
+		Permission: auth.PermissionTransfer,
+		Amount:     money.New(amount, holdWallet.Currency),
+		Recipient:  hold.RecipientWallet,
+	}
+	if !authorize(c, action) {
+		return
+	}
+
+	tx, err := h.walletService.CaptureHold(hold.WalletID, hold.ID, req.Amount)
+	if err != nil {
+		respondHoldError(c, err, "failed to capture hold")
+		return
+	}
+
+	utils.RespondSuccess(c, tx)
+}
+
+func (h *HoldHandler) Release(c *gin.Context) {
+	hold := h.hold(c, auth.PermissionTransfer)
+	if hold == nil {
+		return
+	}
+
+	hold, err := h.walletService.ReleaseHold(hold.WalletID, hold.ID)
+	if err != nil {
+		respondHoldError(c, err, "failed to release hold")
+		return
+	}
+
+	utils.RespondSuccess(c, hold)
+}
+
+func respondHoldError(c *gin.Context, err error, fallback string) {
+	if respondLimitExceeded(c, err) {
+		return
+	}
+
+	switch err {
+	case wallet.ErrHoldNotFound:
+		utils.RespondError(c, 404, err.Error())
+	case wallet.ErrHoldNotActive, wallet.ErrHoldExpired, wallet.ErrHoldNotCapturable:
+		utils.RespondError(c, 409, err.Error())
+	case wallet.ErrInvalidAmount:
+		utils.RespondError(c, 400, "amount must not exceed the held amount")
+	case wallet.ErrWalletFrozen:
+		utils.RespondError(c, 403, err.Error())
+	case wallet.ErrInsufficientBalance:
+		utils.RespondError(c, 400, "insufficient balance")
+	default:
+		utils.RespondError(c, 500, fallback)
+	}
+}
@@ -0,0 +1,93 @@
+package handlers
+
+import (
+	"database/sql"
+
+	"github.com/BerylCAtieno/paystack-wallet/internal/api/middleware"
+	"github.com/BerylCAtieno/paystack-wallet/internal/domain/auth"
+	"github.com/BerylCAtieno/paystack-wallet/internal/domain/wallet"
+	"github.com/BerylCAtieno/paystack-wallet/internal/money"
+	"github.com/BerylCAtieno/paystack-wallet/internal/repository"
+	"github.com/BerylCAtieno/paystack-wallet/internal/utils"
+	"github.com/gin-gonic/gin"
+)
+
+// requestUserID returns the user authenticated by either JWT or API key
+func requestUserID(c *gin.Context) string {
+	// Try JWT first
+	if userID := middleware.GetUserID(c); userID != "" {
+		return userID
+	}
+	// Try API Key
+	if userID := middleware.GetAPIKeyUserID(c); userID != "" {
+		return userID
+	}
+	return ""
+}
+
+func hasPermission(c *gin.Context, perm auth.Permission) bool {
+	// JWT users have all permissions
+	if middleware.GetUserID(c) != "" {
+		return true
+	}
+
+	// Check API key permissions
+	if apiKey := middleware.GetAPIKey(c); apiKey != nil {
+		return apiKey.HasPermission(perm)
+	}
+
+	return false
+}
+
+// authorize checks what the request does, once its amount and recipient are
+// known, against the constraints on the API key's grants. JWT users may do
+// anything. It writes the 403 response and returns false on failure.
+func authorize(c *gin.Context, action auth.Action) bool {
+	if middleware.GetUserID(c) != "" {
+		return true
+	}
+
+	apiKey := middleware.GetAPIKey(c)
+	if apiKey == nil {
+		utils.RespondError(c, 403, "insufficient permissions")
+		return false
+	}
+	if err := apiKey.Authorize(action); err != nil {
+		utils.RespondError(c, 403, err.Error())
+		return false
+	}
+	return true
+}
+
+const unsupportedCurrencyMessage = "currency must be one of NGN, GHS, ZAR, KES or USD"
+
+// parseRequestCurrency reads a currency code from a request, defaulting to
+// NGN when it is empty
+func parseRequestCurrency(code string) (money.Currency, error) {
+	if code == "" {
+		return money.DefaultCurrency, nil
+	}
+	return money.ParseCurrency(code)
+}
+
+// requestWallet returns the user's wallet in the currency named by code,
+// the NGN wallet when code is empty. It writes the error response and
+// returns nil on failure.
+func requestWallet(c *gin.Context, walletRepo *repository.WalletRepository, userID, code string) *wallet.Wallet {
+	currency, err := parseRequestCurrency(code)
+	if err != nil {
+		utils.RespondError(c, 400, unsupportedCurrencyMessage)
+		return nil
+	}
+
+	userWallet, err := walletRepo.GetByUserID(userID, currency)
+	if err == sql.ErrNoRows {
+		utils.RespondError(c, 404, "no "+string(currency)+" wallet, open one with POST /wallet/currencies")
+		return nil
+	}
+	if err != nil {
+		utils.RespondError(c, 500, "wallet not found")
+		return nil
+	}
+	return userWallet
+}
@@ -1,59 +1,60 @@
 package handlers
 
 import (
-	"fmt"
+	"errors"
+	"log"
+	"strconv"
+	"time"
 
 	"github.com/BerylCAtieno/paystack-wallet/internal/api/middleware"
 	"github.com/BerylCAtieno/paystack-wallet/internal/domain/auth"
+	"github.com/BerylCAtieno/paystack-wallet/internal/domain/beneficiary"
+	"github.com/BerylCAtieno/paystack-wallet/internal/domain/fee"
+	"github.com/BerylCAtieno/paystack-wallet/internal/domain/fx"
+	"github.com/BerylCAtieno/paystack-wallet/internal/domain/limit"
+	"github.com/BerylCAtieno/paystack-wallet/internal/domain/refund"
 	"github.com/BerylCAtieno/paystack-wallet/internal/domain/wallet"
+	"github.com/BerylCAtieno/paystack-wallet/internal/identifier"
+	"github.com/BerylCAtieno/paystack-wallet/internal/money"
 	"github.com/BerylCAtieno/paystack-wallet/internal/paystack"
 	"github.com/BerylCAtieno/paystack-wallet/internal/repository"
+	"github.com/BerylCAtieno/paystack-wallet/internal/settlement"
 	"github.com/BerylCAtieno/paystack-wallet/internal/utils"
 	"github.com/gin-gonic/gin"
 )
 
 type WalletHandler struct {
-	walletService  *wallet.Service
-	walletRepo     *repository.WalletRepository
-	paystackClient *paystack.Client
+	walletService      *wallet.Service
+	beneficiaryService *beneficiary.Service
+	refundService      *refund.Service
+	fxService          *fx.Service
+	walletRepo         *repository.WalletRepository
+	paystackClient     *paystack.Client
 }
 
-func NewWalletHandler(walletService *wallet.Service, walletRepo *repository.WalletRepository, paystackClient *paystack.Client) *WalletHandler {
+func NewWalletHandler(walletService *wallet.Service, beneficiaryService *beneficiary.Service, refundService *refund.Service, fxService *fx.Service, walletRepo *repository.WalletRepository, paystackClient *paystack.Client) *WalletHandler {
 	return &WalletHandler{
-		walletService:  walletService,
-		walletRepo:     walletRepo,
-		paystackClient: paystackClient,
+		walletService:      walletService,
+		beneficiaryService: beneficiaryService,
+		refundService:      refundService,
+		fxService:          fxService,
+		walletRepo:         walletRepo,
+		paystackClient:     paystackClient,
 	}
 }
 
 func (h *WalletHandler) getUserID(c *gin.Context) string {
-	// Try JWT first
-	if userID := middleware.GetUserID(c); userID != "" {
-		return userID
-	}
-	// Try API Key
-	if userID := middleware.GetAPIKeyUserID(c); userID != "" {
-		return userID
-	}
-	return ""
+	return requestUserID(c)
 }
 
 func (h *WalletHandler) checkPermission(c *gin.Context, perm auth.Permission) bool {
-	// JWT users have all permissions
-	if middleware.GetUserID(c) != "" {
-		return true
-	}
-
-	// Check API key permissions
-	if apiKey := middleware.GetAPIKey(c); apiKey != nil {
-		return apiKey.HasPermission(perm)
-	}
-
-	return false
+	return hasPermission(c, perm)
 }
 
 type DepositRequest struct {
-	Amount int64 `json:"amount"`
+	Amount int64 `json:"amount"` // in the currency's minor unit
+	// Currency selects the wallet to fund, NGN when omitted
+	Currency string `json:"currency"`
 }
 
 func (h *WalletHandler) InitiateDeposit(c *gin.Context) {
@@ -79,34 +80,59 @@ func (h *WalletHandler) InitiateDeposit(c *gin.Context) {
 		return
 	}
 
-	userWallet, err := h.walletRepo.GetByUserID(userID)
-	if err != nil {
-		utils.RespondError(c, 500, "wallet not found")
+	userWallet := requestWallet(c, h.walletRepo, userID, req.Currency)
+	if userWallet == nil {
+		return
+	}
+
+	if userWallet.Status == wallet.WalletStatusFrozen {
+		utils.RespondError(c, 403, wallet.ErrWalletFrozen.Error())
 		return
 	}
 
-	reference := fmt.Sprintf("DEP_%s_%d", userID, req.Amount)
+	reference := identifier.NewReference(identifier.PrefixDeposit)
 
 	email := middleware.GetUserEmail(c)
 	if email == "" {
 		email = "user@example.com" // fallback for API key auth
 	}
 
-	paystackResp, err := h.paystackClient.InitializeTransaction(email, req.Amount, reference)
+	amount := money.New(req.Amount, userWallet.Currency)
+	if !authorize(c, auth.Action{Permission: auth.PermissionDeposit, Amount: amount}) {
+		return
+	}
+
+	tx, err := h.walletService.InitiateDeposit(userWallet.ID, amount, reference, email)
 	if err != nil {
-		utils.RespondError(c, 500, "failed to initialize payment")
+		if respondLimitExceeded(c, err) {
+			return
+		}
+		utils.RespondError(c, 500, "failed to create transaction")
 		return
 	}
 
-	_, err = h.walletService.InitiateDeposit(userWallet.ID, req.Amount, reference)
+	details, err := tx.DepositDetails()
 	if err != nil {
 		utils.RespondError(c, 500, "failed to create transaction")
 		return
 	}
 
+	// The customer pays the fee on top of the amount credited
+	paystackResp, err := h.paystackClient.InitializeTransaction(email, details.Fee.Total, reference)
+	if err != nil {
+		if failErr := h.walletService.FailDeposit(reference); failErr != nil {
+			log.Printf("failed to close deposit %s: %v", reference, failErr)
+		}
+		utils.RespondError(c, 500, "failed to initialize payment")
+		return
+	}
+
 	utils.RespondSuccess(c, map[string]interface{}{
 		"reference":         paystackResp.Data.Reference,
 		"authorization_url": paystackResp.Data.AuthorizationURL,
+		"amount":            amount.Amount,
+		"currency":          amount.Currency,
+		"fee":               details.Fee,
 	})
 }
 
@@ -117,25 +143,146 @@ func (h *WalletHandler) GetBalance(c *gin.Context) {
 		return
 	}
 
-	if !h.checkPermission(c, auth.PermissionRead) {
+	if !h.checkPermission(c, auth.PermissionBalanceRead) {
 		utils.RespondError(c, 403, "insufficient permissions")
 		return
 	}
 
-	balance, err := h.walletService.GetBalance(userID)
+	userWallet := requestWallet(c, h.walletRepo, userID, c.Query("currency"))
+	if userWallet == nil {
+		return
+	}
+
+	balance, err := h.walletService.GetBalance(userWallet.ID)
 	if err != nil {
 		utils.RespondError(c, 500, "failed to get balance")
 		return
 	}
 
+	// balance is the spendable amount, as it was before holds existed
 	utils.RespondSuccess(c, map[string]interface{}{
-		"balance": balance,
+		"balance":           balance.AvailableBalance,
+		"currency":          balance.Currency,
+		"display":           money.New(balance.AvailableBalance, balance.Currency).String(),
+		"available_balance": balance.AvailableBalance,
+		"ledger_balance":    balance.LedgerBalance,
+		"held_amount":       balance.HeldAmount,
 	})
 }
 
+// GetLimits returns the caller's KYC tier and the limits and usage of their
+// wallet in the requested currency
+func (h *WalletHandler) GetLimits(c *gin.Context) {
+	userID := h.getUserID(c)
+	if userID == "" {
+		utils.RespondError(c, 401, "user not authenticated")
+		return
+	}
+
+	if !h.checkPermission(c, auth.PermissionRead) {
+		utils.RespondError(c, 403, "insufficient permissions")
+		return
+	}
+
+	userWallet := requestWallet(c, h.walletRepo, userID, c.Query("currency"))
+	if userWallet == nil {
+		return
+	}
+
+	status, err := h.walletService.Limits(userWallet.ID)
+	if err != nil {
+		utils.RespondError(c, 500, "failed to get limits")
+		return
+	}
+
+	utils.RespondSuccess(c, status)
+}
+
+// respondLimitExceeded writes a limit_exceeded error with the remaining
+// allowance if err is one, and reports whether it did
+func respondLimitExceeded(c *gin.Context, err error) bool {
+	var exceeded *limit.ExceededError
+	if !errors.As(err, &exceeded) {
+		return false
+	}
+
+	utils.RespondErrorDetails(c, 403, "limit_exceeded", exceeded.Error(), exceeded)
+	return true
+}
+
+// ListWallets returns the caller's wallets, one per currency
+func (h *WalletHandler) ListWallets(c *gin.Context) {
+	userID := h.getUserID(c)
+	if userID == "" {
+		utils.RespondError(c, 401, "user not authenticated")
+		return
+	}
+
+	if !h.checkPermission(c, auth.PermissionBalanceRead) {
+		utils.RespondError(c, 403, "insufficient permissions")
+		return
+	}
+
+	wallets, err := h.walletService.ListWallets(userID)
+	if err != nil {
+		utils.RespondError(c, 500, "failed to list wallets")
+		return
+	}
+
+	utils.RespondSuccess(c, wallets)
+}
+
+type OpenWalletRequest struct {
+	Currency string `json:"currency"`
+}
+
+// OpenWallet opens a wallet in another currency. Opening a currency the
+// caller already holds returns the existing wallet.
+func (h *WalletHandler) OpenWallet(c *gin.Context) {
+	userID := h.getUserID(c)
+	if userID == "" {
+		utils.RespondError(c, 401, "user not authenticated")
+		return
+	}
+
+	if !h.checkPermission(c, auth.PermissionDeposit) {
+		utils.RespondError(c, 403, "insufficient permissions")
+		return
+	}
+
+	var req OpenWalletRequest
+	if err := c.BindJSON(&req); err != nil {
+		utils.RespondError(c, 400, "invalid request body")
+		return
+	}
+
+	currency, err := money.ParseCurrency(req.Currency)
+	if err != nil {
+		utils.RespondError(c, 400, unsupportedCurrencyMessage)
+		return
+	}
+
+	userWallet, err := h.walletService.GetOrCreateWallet(userID, currency)
+	if err != nil {
+		utils.RespondError(c, 500, "failed to open wallet")
+		return
+	}
+
+	utils.RespondSuccess(c, userWallet)
+}
+
 type TransferRequest struct {
-	WalletNumber string `json:"wallet_number"`
-	Amount       int64  `json:"amount"`
+	WalletNumber  string `json:"wallet_number"`
+	BeneficiaryID string `json:"beneficiary_id"`
+	Amount        int64  `json:"amount"`
+	// Currency selects the wallet to send from, NGN when omitted
+	Currency string `json:"currency"`
+	// QuoteID sends to a wallet in another currency at a rate locked with
+	// POST /wallet/exchange/quote; amount defaults to the quoted amount
+	QuoteID string `json:"quote_id"`
+	// Convert sends to a wallet in another currency at the current rate
+	// without a prior quote
+	Convert bool `json:"convert"`
 }
 
 func (h *WalletHandler) Transfer(c *gin.Context) {
@@ -156,13 +303,70 @@ func (h *WalletHandler) Transfer(c *gin.Context) {
 		return
 	}
 
-	senderWallet, err := h.walletRepo.GetByUserID(userID)
-	if err != nil {
-		utils.RespondError(c, 500, "wallet not found")
+	// A quote fixes the wallet to send from and, by default, the amount
+	var quote *fx.Quote
+	if req.QuoteID != "" {
+		var err error
+		if quote, err = h.fxService.OpenQuote(userID, req.QuoteID); err != nil {
+			respondFXError(c, err, "failed to get quote")
+			return
+		}
+		if req.Currency == "" {
+			req.Currency = string(quote.Source.Currency)
+		}
+		if req.Amount == 0 {
+			req.Amount = quote.Source.Amount
+		}
+	}
+
+	senderWallet := requestWallet(c, h.walletRepo, userID, req.Currency)
+	if senderWallet == nil {
 		return
 	}
 
-	if err := h.walletService.Transfer(senderWallet.ID, req.WalletNumber, req.Amount); err != nil {
+	if req.BeneficiaryID != "" {
+		payee, err := h.beneficiaryService.Get(senderWallet.ID, req.BeneficiaryID)
+		if err != nil {
+			utils.RespondError(c, 404, err.Error())
+			return
+		}
+		if payee.Type != beneficiary.TypeWallet {
+			utils.RespondError(c, 400, "beneficiary is a bank account, use /wallet/withdraw")
+			return
+		}
+		req.WalletNumber = payee.WalletNumber
+	}
+
+	if req.WalletNumber == "" {
+		utils.RespondError(c, 400, "wallet_number or beneficiary_id is required")
+		return
+	}
+
+	if !identifier.ValidWalletNumber(req.WalletNumber) {
+		utils.RespondError(c, 400, wallet.ErrInvalidWalletNumber.Error())
+		return
+	}
+
+	amount := money.New(req.Amount, senderWallet.Currency)
+	if !amount.IsPositive() {
+		utils.RespondError(c, 400, "amount must be greater than 0")
+		return
+	}
+
+	if !authorize(c, auth.Action{Permission: auth.PermissionTransfer, Amount: amount, Recipient: req.WalletNumber}) {
+		return
+	}
+
+	conversion, ok := h.transferConversion(c, userID, req, quote, amount)
+	if !ok {
+		return
+	}
+
+	breakdown, err := h.walletService.Transfer(senderWallet.ID, req.WalletNumber, amount, conversion)
+	if err != nil {
+		if respondLimitExceeded(c, err) {
+			return
+		}
 		if err == wallet.ErrInsufficientBalance {
 			utils.RespondError(c, 400, "insufficient balance")
 			return
@@ -171,13 +375,325 @@ func (h *WalletHandler) Transfer(c *gin.Context) {
 			utils.RespondError(c, 400, "recipient wallet not found")
 			return
 		}
+		if err == wallet.ErrWalletFrozen {
+			utils.RespondError(c, 403, err.Error())
+			return
+		}
+		if err == wallet.ErrSelfTransfer || err == wallet.ErrCrossCurrencyTransfer || err == wallet.ErrQuoteMismatch {
+			utils.RespondError(c, 400, err.Error())
+			return
+		}
+		if err == wallet.ErrQuoteUnavailable {
+			utils.RespondError(c, 409, err.Error())
+			return
+		}
+		if err == wallet.ErrInvalidAmount {
+			utils.RespondError(c, 400, "amount must be greater than 0")
+			return
+		}
 		utils.RespondError(c, 500, "transfer failed")
 		return
 	}
 
+	response := map[string]interface{}{
+		"status":   "success",
+		"message":  "Transfer completed",
+		"amount":   amount.Amount,
+		"currency": amount.Currency,
+		"fee":      breakdown,
+	}
+	if conversion != nil {
+		response["conversion"] = conversion
+	}
+	utils.RespondSuccess(c, response)
+}
+
+// transferConversion returns the conversion a transfer asked for: the quote
+// it names, or a quote made now when convert is set and the recipient holds
+// another currency. It is nil for same-currency transfers. It writes the
+// error response and returns false on failure.
+func (h *WalletHandler) transferConversion(c *gin.Context, userID string, req TransferRequest, quote *fx.Quote, amount money.Money) (*wallet.Conversion, bool) {
+	if quote != nil {
+		conversion := quoteConversion(quote)
+		return &conversion, true
+	}
+
+	if !req.Convert {
+		return nil, true
+	}
+
+	recipient, err := h.walletRepo.GetByWalletNumber(req.WalletNumber)
+	if err != nil {
+		utils.RespondError(c, 400, "recipient wallet not found")
+		return nil, false
+	}
+	if recipient.Currency == amount.Currency {
+		return nil, true
+	}
+
+	quote, err = h.fxService.Quote(userID, amount, recipient.Currency)
+	if err != nil {
+		respondFXError(c, err, "failed to create quote")
+		return nil, false
+	}
+	conversion := quoteConversion(quote)
+	return &conversion, true
+}
+
+type TransferQuoteRequest struct {
+	Amount int64 `json:"amount"`
+	// Currency selects the wallet to send from, NGN when omitted
+	Currency string `json:"currency"`
+}
+
+// QuoteTransfer previews the fee a transfer of amount would be charged
+// without moving any money.
+func (h *WalletHandler) QuoteTransfer(c *gin.Context) {
+	userID := h.getUserID(c)
+	if userID == "" {
+		utils.RespondError(c, 401, "user not authenticated")
+		return
+	}
+
+	if !h.checkPermission(c, auth.PermissionTransfer) {
+		utils.RespondError(c, 403, "insufficient permissions")
+		return
+	}
+
+	var req TransferQuoteRequest
+	if err := c.BindJSON(&req); err != nil {
+		utils.RespondError(c, 400, "invalid request body")
+		return
+	}
+
+	if req.Amount <= 0 {
+		utils.RespondError(c, 400, "amount must be greater than 0")
+		return
+	}
+
+	senderWallet := requestWallet(c, h.walletRepo, userID, req.Currency)
+	if senderWallet == nil {
+		return
+	}
+
+	breakdown, err := h.walletService.QuoteFee(senderWallet.ID, fee.OperationTransfer, money.New(req.Amount, senderWallet.Currency))
+	if err != nil {
+		utils.RespondError(c, 500, "failed to quote transfer")
+		return
+	}
+
+	utils.RespondSuccess(c, breakdown)
+}
+
+type WithdrawRequest struct {
+	Amount int64 `json:"amount"`
+	// Currency selects the wallet to withdraw from, NGN when omitted
+	Currency      string `json:"currency"`
+	BeneficiaryID string `json:"beneficiary_id"`
+	AccountNumber string `json:"account_number"`
+	BankCode      string `json:"bank_code"`
+	AccountName   string `json:"account_name"`
+	Reason        string `json:"reason"`
+}
+
+func (h *WalletHandler) Withdraw(c *gin.Context) {
+	userID := h.getUserID(c)
+	if userID == "" {
+		utils.RespondError(c, 401, "user not authenticated")
+		return
+	}
+
+	if !h.checkPermission(c, auth.PermissionWithdraw) {
+		utils.RespondError(c, 403, "insufficient permissions")
+		return
+	}
+
+	var req WithdrawRequest
+	if err := c.BindJSON(&req); err != nil {
+		utils.RespondError(c, 400, "invalid request body")
+		return
+	}
+
+	if req.Amount <= 0 {
+		utils.RespondError(c, 400, "amount must be greater than 0")
+		return
+	}
+
+	userWallet := requestWallet(c, h.walletRepo, userID, req.Currency)
+	if userWallet == nil {
+		return
+	}
+
+	if req.BeneficiaryID != "" {
+		payee, err := h.beneficiaryService.Get(userWallet.ID, req.BeneficiaryID)
+		if err != nil {
+			utils.RespondError(c, 404, err.Error())
+			return
+		}
+		if payee.Type != beneficiary.TypeBank {
+			utils.RespondError(c, 400, "beneficiary is a wallet, use /wallet/transfer")
+			return
+		}
+		req.AccountNumber = payee.AccountNumber
+		req.BankCode = payee.BankCode
+		req.AccountName = payee.AccountName
+	}
+
+	if req.AccountNumber == "" || req.BankCode == "" || req.AccountName == "" {
+		utils.RespondError(c, 400, "account_number, bank_code and account_name are required")
+		return
+	}
+
+	action := auth.Action{
+		Permission: auth.PermissionWithdraw,
+		Amount:     money.New(req.Amount, userWallet.Currency),
+		Recipient:  req.AccountNumber,
+	}
+	if !authorize(c, action) {
+		return
+	}
+
+	// Check limits and the balance and hold the funds and the fee before
+	// anything is registered with Paystack
+	tx, err := h.walletService.InitiateWithdrawal(userWallet.ID, req.Amount, wallet.WithdrawalDetails{
+		AccountNumber: req.AccountNumber,
+		AccountName:   req.AccountName,
+		BankCode:      req.BankCode,
+		Reason:        req.Reason,
+	})
+	if err != nil {
+		if respondLimitExceeded(c, err) {
+			return
+		}
+		switch err {
+		case wallet.ErrInsufficientBalance:
+			utils.RespondError(c, 400, "insufficient balance")
+		case wallet.ErrWalletFrozen:
+			utils.RespondError(c, 403, err.Error())
+		default:
+			utils.RespondError(c, 500, "failed to create withdrawal")
+		}
+		return
+	}
+
+	details, err := tx.WithdrawalDetails()
+	if err != nil {
+		h.releaseWithdrawal(tx.Reference)
+		utils.RespondError(c, 500, "failed to create withdrawal")
+		return
+	}
+
+	// Creating a recipient moves no money, so the withdrawal can be
+	// released whatever went wrong
+	recipient, err := h.paystackClient.CreateTransferRecipient(req.AccountName, req.AccountNumber, req.BankCode, userWallet.Currency)
+	if err != nil {
+		h.releaseWithdrawal(tx.Reference)
+		utils.RespondError(c, 400, "failed to verify bank account")
+		return
+	}
+	if err := h.walletService.AttachRecipient(tx.Reference, recipient.Data.RecipientCode); err != nil {
+		log.Printf("failed to store recipient code for %s: %v", tx.Reference, err)
+	}
+
+	// The fee stays with us; Paystack sends only the amount
+	transfer, err := h.paystackClient.InitiateTransfer(money.New(req.Amount, tx.Currency), recipient.Data.RecipientCode, tx.Reference, req.Reason)
+	if err != nil {
+		if paystack.IsRejected(err) {
+			h.releaseWithdrawal(tx.Reference)
+			utils.RespondError(c, 502, "failed to initiate transfer")
+			return
+		}
+
+		// Paystack may have accepted the transfer before the connection
+		// failed, so the funds stay on hold until the webhook or the
+		// sweeper learns the outcome
+		log.Printf("outcome of transfer %s unknown, leaving it pending: %v", tx.Reference, err)
+		utils.RespondJSON(c, 202, utils.SuccessResponse{
+			Data: map[string]interface{}{
+				"reference":    tx.Reference,
+				"status":       paystack.TransferStatusPending,
+				"requires_otp": false,
+				"fee":          details.Fee,
+			},
+			Message: "transfer submitted, its outcome will be confirmed by Paystack",
+		})
+		return
+	}
+
+	if err := h.walletService.AttachTransferCode(tx.Reference, transfer.Data.TransferCode); err != nil {
+		log.Printf("failed to store transfer code for %s: %v", tx.Reference, err)
+	}
+
+	utils.RespondSuccess(c, map[string]interface{}{
+		"reference":     tx.Reference,
+		"transfer_code": transfer.Data.TransferCode,
+		"status":        transfer.Data.Status,
+		"requires_otp":  transfer.Data.Status == paystack.TransferStatusOTP,
+		"fee":           details.Fee,
+	})
+}
+
+// releaseWithdrawal fails a withdrawal Paystack never took on, returning
+// its held funds
+func (h *WalletHandler) releaseWithdrawal(reference string) {
+	if err := h.walletService.FailWithdrawal(reference); err != nil {
+		log.Printf("failed to release withdrawal %s: %v", reference, err)
+	}
+}
+
+type FinalizeWithdrawalRequest struct {
+	Reference string `json:"reference"`
+	OTP       string `json:"otp"`
+}
+
+func (h *WalletHandler) FinalizeWithdrawal(c *gin.Context) {
+	userID := h.getUserID(c)
+	if userID == "" {
+		utils.RespondError(c, 401, "user not authenticated")
+		return
+	}
+
+	if !h.checkPermission(c, auth.PermissionWithdraw) {
+		utils.RespondError(c, 403, "insufficient permissions")
+		return
+	}
+
+	var req FinalizeWithdrawalRequest
+	if err := c.BindJSON(&req); err != nil {
+		utils.RespondError(c, 400, "invalid request body")
+		return
+	}
+
+	if req.Reference == "" || req.OTP == "" {
+		utils.RespondError(c, 400, "reference and otp are required")
+		return
+	}
+
+	tx, err := h.walletService.GetUserTransaction(userID, req.Reference)
+	if err != nil || tx.Type != wallet.TransactionTypeWithdrawal {
+		utils.RespondError(c, 404, "withdrawal not found")
+		return
+	}
+	if tx.Status != wallet.TransactionStatusPending {
+		utils.RespondError(c, 409, "withdrawal is "+string(tx.Status)+", only pending withdrawals can be finalized")
+		return
+	}
+
+	details, err := tx.WithdrawalDetails()
+	if err != nil || details.TransferCode == "" {
+		utils.RespondError(c, 400, "withdrawal has no pending transfer")
+		return
+	}
+
+	transfer, err := h.paystackClient.FinalizeTransfer(details.TransferCode, req.OTP)
+	if err != nil {
+		utils.RespondError(c, 400, "failed to finalize transfer")
+		return
+	}
+
 	utils.RespondSuccess(c, map[string]interface{}{
-		"status":  "success",
-		"message": "Transfer completed",
+		"reference": tx.Reference,
+		"status":    transfer.Data.Status,
 	})
 }
 
@@ -188,22 +704,341 @@ func (h *WalletHandler) GetTransactions(c *gin.Context) {
 		return
 	}
 
-	if !h.checkPermission(c, auth.PermissionRead) {
+	if !h.checkPermission(c, auth.PermissionTransactionsRead) {
+		utils.RespondError(c, 403, "insufficient permissions")
+		return
+	}
+
+	userWallet := requestWallet(c, h.walletRepo, userID, c.Query("currency"))
+	if userWallet == nil {
+		return
+	}
+
+	filter, err := parseTransactionFilter(c)
+	if err != nil {
+		utils.RespondError(c, 400, err.Error())
+		return
+	}
+
+	limit := 0
+	if raw := c.Query("limit"); raw != "" {
+		if limit, err = strconv.Atoi(raw); err != nil || limit <= 0 {
+			utils.RespondError(c, 400, "limit must be a positive integer")
+			return
+		}
+	}
+
+	page, err := h.walletService.GetTransactions(userWallet.ID, filter, c.Query("cursor"), limit)
+	if err != nil {
+		switch err {
+		case wallet.ErrInvalidCursor, wallet.ErrInvalidFilter:
+			utils.RespondError(c, 400, err.Error())
+		default:
+			utils.RespondError(c, 500, "failed to get transactions")
+		}
+		return
+	}
+
+	utils.RespondSuccess(c, page)
+}
+
+func (h *WalletHandler) GetTransaction(c *gin.Context) {
+	userID := h.getUserID(c)
+	if userID == "" {
+		utils.RespondError(c, 401, "user not authenticated")
+		return
+	}
+
+	if !h.checkPermission(c, auth.PermissionTransactionsRead) {
+		utils.RespondError(c, 403, "insufficient permissions")
+		return
+	}
+
+	tx, err := h.walletService.GetUserTransaction(userID, c.Param("reference"))
+	if err != nil {
+		utils.RespondError(c, 404, err.Error())
+		return
+	}
+
+	utils.RespondSuccess(c, tx)
+}
+
+type DepositStatusResponse struct {
+	Reference      string                   `json:"reference"`
+	Amount         int64                    `json:"amount"`
+	Currency       money.Currency           `json:"currency"`
+	Status         wallet.TransactionStatus `json:"status"`
+	PaystackStatus string                   `json:"paystack_status,omitempty"`
+}
+
+// GetDepositStatus reports a deposit's status. Pending deposits are checked
+// against Paystack first and settled if the payment went through, so a
+// missed webhook does not leave the wallet short.
+func (h *WalletHandler) GetDepositStatus(c *gin.Context) {
+	userID := h.getUserID(c)
+	if userID == "" {
+		utils.RespondError(c, 401, "user not authenticated")
+		return
+	}
+
+	if !h.checkPermission(c, auth.PermissionTransactionsRead) {
 		utils.RespondError(c, 403, "insufficient permissions")
 		return
 	}
 
-	userWallet, err := h.walletRepo.GetByUserID(userID)
+	tx, err := h.walletService.GetUserTransaction(userID, c.Param("reference"))
+	if err != nil || tx.Type != wallet.TransactionTypeDeposit {
+		utils.RespondError(c, 404, "deposit not found")
+		return
+	}
+
+	resp := DepositStatusResponse{
+		Reference: tx.Reference,
+		Amount:    tx.Amount,
+		Currency:  tx.Currency,
+		Status:    tx.Status,
+	}
+
+	if tx.Status != wallet.TransactionStatusPending {
+		utils.RespondSuccess(c, resp)
+		return
+	}
+
+	verification, err := h.paystackClient.VerifyTransaction(tx.Reference)
+	if err != nil {
+		log.Printf("verify deposit %s: %v", tx.Reference, err)
+		utils.RespondError(c, 502, "failed to verify deposit with Paystack")
+		return
+	}
+	resp.PaystackStatus = verification.Data.Status
+
+	if verification.Data.Status == paystack.TransactionStatusSuccess {
+		settled, err := h.walletService.SettleDeposit(tx.Reference, settlement.VerifiedPayment(verification))
+		if err != nil {
+			utils.RespondError(c, 500, "failed to complete deposit")
+			return
+		}
+		resp.Amount = settled.Amount
+		resp.Status = settled.Status
+	}
+
+	utils.RespondSuccess(c, resp)
+}
+
+type RefundRequest struct {
+	// Amount defaults to whatever is left of the deposit
+	Amount int64  `json:"amount"`
+	Reason string `json:"reason"`
+}
+
+// RefundDeposit returns a deposit to the card or account it was paid from.
+// The amount is held in the wallet until Paystack reports the refund as
+// processed or failed.
+func (h *WalletHandler) RefundDeposit(c *gin.Context) {
+	userID := h.getUserID(c)
+	if userID == "" {
+		utils.RespondError(c, 401, "user not authenticated")
+		return
+	}
+
+	if !h.checkPermission(c, auth.PermissionWithdraw) {
+		utils.RespondError(c, 403, "insufficient permissions")
+		return
+	}
+
+	var req RefundRequest
+	if c.Request.ContentLength > 0 {
+		if err := c.BindJSON(&req); err != nil {
+			utils.RespondError(c, 400, "invalid request body")
+			return
+		}
+	}
+
+	if req.Amount < 0 {
+		utils.RespondError(c, 400, "amount must be greater than 0")
+		return
+	}
+
+	depositReference := c.Param("reference")
+	deposit, err := h.walletService.GetUserTransaction(userID, depositReference)
+	if err != nil {
+		utils.RespondError(c, 404, "deposit not found")
+		return
+	}
+
+	refundAmount := req.Amount
+	if refundAmount == 0 {
+		refundAmount = deposit.Amount
+	}
+	if !authorize(c, auth.Action{Permission: auth.PermissionWithdraw, Amount: money.New(refundAmount, deposit.Currency)}) {
+		return
+	}
+
+	// Hold the funds before asking Paystack to return them
+	tx, err := h.walletService.RefundDeposit(deposit.WalletID, depositReference, req.Amount, req.Reason)
+	if err != nil {
+		respondRefundError(c, err)
+		return
+	}
+
+	h.submitRefund(c, tx, depositReference, req.Reason)
+}
+
+// AdminRefundDeposit refunds any successful deposit by reference on behalf
+// of its owner, e.g. for a chargeback or a payment made in error. The same
+// balance and deposit rules apply as to refunds started from the wallet.
+func (h *WalletHandler) AdminRefundDeposit(c *gin.Context) {
+	var req RefundRequest
+	if c.Request.ContentLength > 0 {
+		if err := c.BindJSON(&req); err != nil {
+			utils.RespondError(c, 400, "invalid request body")
+			return
+		}
+	}
+
+	if req.Amount < 0 {
+		utils.RespondError(c, 400, "amount must be greater than 0")
+		return
+	}
+
+	depositReference := c.Param("reference")
+	deposit, err := h.walletService.GetDeposit(depositReference)
+	if err != nil {
+		utils.RespondError(c, 404, "deposit not found")
+		return
+	}
+
+	tx, err := h.walletService.RefundDeposit(deposit.WalletID, depositReference, req.Amount, req.Reason)
 	if err != nil {
-		utils.RespondError(c, 500, "wallet not found")
+		respondRefundError(c, err)
 		return
 	}
 
-	transactions, err := h.walletService.GetTransactions(userWallet.ID)
+	h.submitRefund(c, tx, depositReference, req.Reason)
+}
+
+func respondRefundError(c *gin.Context, err error) {
+	switch err {
+	case wallet.ErrTransactionNotFound:
+		utils.RespondError(c, 404, "deposit not found")
+	case wallet.ErrInsufficientBalance, wallet.ErrRefundExceedsDeposit, wallet.ErrNotRefundable:
+		utils.RespondError(c, 400, err.Error())
+	case wallet.ErrWalletFrozen:
+		utils.RespondError(c, 403, err.Error())
+	default:
+		utils.RespondError(c, 500, "failed to create refund")
+	}
+}
+
+// submitRefund asks Paystack to return a refund whose funds are already on
+// hold. The hold is only released when Paystack rejects the refund; if its
+// outcome is unknown the refund stays pending until the refund webhook,
+// matched on the deposit and amount, settles it.
+func (h *WalletHandler) submitRefund(c *gin.Context, tx *wallet.Transaction, depositReference, reason string) {
+	paystackRefund, err := h.paystackClient.CreateRefund(depositReference, -tx.Amount, reason)
 	if err != nil {
-		utils.RespondError(c, 500, "failed to get transactions")
+		if paystack.IsRejected(err) {
+			if failErr := h.walletService.FailRefund(tx.Reference); failErr != nil {
+				log.Printf("failed to release refund %s: %v", tx.Reference, failErr)
+			}
+			utils.RespondError(c, 502, "failed to initiate refund")
+			return
+		}
+
+		log.Printf("outcome of refund %s unknown, leaving it pending: %v", tx.Reference, err)
+		utils.RespondJSON(c, 202, utils.SuccessResponse{
+			Data: map[string]interface{}{
+				"reference":         tx.Reference,
+				"deposit_reference": depositReference,
+				"amount":            -tx.Amount,
+				"currency":          tx.Currency,
+				"status":            tx.Status,
+			},
+			Message: "refund submitted, its outcome will be confirmed by Paystack",
+		})
 		return
 	}
 
-	utils.RespondSuccess(c, transactions)
+	paystackID := paystackRefund.Data.ID.String()
+	if err := h.walletService.AttachRefundID(tx.Reference, paystackID); err != nil {
+		log.Printf("failed to store refund ID for %s: %v", tx.Reference, err)
+	}
+
+	status := refund.Status(paystackRefund.Data.Status)
+	if status == "" {
+		status = refund.StatusPending
+	}
+
+	// The webhook may have beaten us here; settle in case it already
+	// reported a final status
+	r, err := h.refundService.Attach(paystackID, depositReference, tx.Reference, tx.WalletID, -tx.Amount, paystackRefund.Data.Currency, status)
+	if err != nil {
+		log.Printf("failed to record refund %s: %v", tx.Reference, err)
+	} else if err := settlement.SettleRefund(h.walletService, r); err != nil {
+		log.Printf("failed to settle refund %s: %v", tx.Reference, err)
+	}
+
+	utils.RespondSuccess(c, map[string]interface{}{
+		"reference":         tx.Reference,
+		"deposit_reference": depositReference,
+		"amount":            -tx.Amount,
+		"currency":          tx.Currency,
+		"status":            tx.Status,
+		"paystack_status":   paystackRefund.Data.Status,
+	})
+}
+
+// parseTransactionFilter reads the history filters from the query string.
+// Dates accept RFC 3339 timestamps or plain YYYY-MM-DD days; a plain "to"
+// day includes the whole day.
+func parseTransactionFilter(c *gin.Context) (wallet.TransactionFilter, error) {
+	filter := wallet.TransactionFilter{
+		Type:         wallet.TransactionType(c.Query("type")),
+		Status:       wallet.TransactionStatus(c.Query("status")),
+		Counterparty: c.Query("counterparty"),
+	}
+
+	if filter.Counterparty != "" && !identifier.ValidWalletNumber(filter.Counterparty) {
+		return filter, wallet.ErrInvalidWalletNumber
+	}
+
+	var err error
+	if raw := c.Query("from"); raw != "" {
+		if filter.From, err = parseFilterTime(raw, false); err != nil {
+			return filter, errors.New("from must be an RFC 3339 timestamp or YYYY-MM-DD date")
+		}
+	}
+	if raw := c.Query("to"); raw != "" {
+		if filter.To, err = parseFilterTime(raw, true); err != nil {
+			return filter, errors.New("to must be an RFC 3339 timestamp or YYYY-MM-DD date")
+		}
+	}
+
+	if raw := c.Query("min_amount"); raw != "" {
+		if filter.MinAmount, err = strconv.ParseInt(raw, 10, 64); err != nil || filter.MinAmount <= 0 {
+			return filter, errors.New("min_amount must be a positive integer")
+		}
+	}
+	if raw := c.Query("max_amount"); raw != "" {
+		if filter.MaxAmount, err = strconv.ParseInt(raw, 10, 64); err != nil || filter.MaxAmount <= 0 {
+			return filter, errors.New("max_amount must be a positive integer")
+		}
+	}
+
+	return filter, nil
+}
+
+func parseFilterTime(raw string, endOfDay bool) (time.Time, error) {
+	if t, err := time.Parse(time.RFC3339, raw); err == nil {
+		return t, nil
+	}
+
+	day, err := time.ParseInLocation("2006-01-02", raw, time.Local)
+	if err != nil {
+		return time.Time{}, err
+	}
+	if endOfDay {
+		return day.Add(24*time.Hour - time.Nanosecond), nil
+	}
+	return day, nil
 }
@@ -1,27 +1,32 @@
 package handlers
 
 import (
+	"crypto/sha256"
+	"encoding/hex"
+	"log"
 	"net/http"
+	"strconv"
 
-	"github.com/BerylCAtieno/paystack-wallet/internal/domain/wallet"
+	"github.com/BerylCAtieno/paystack-wallet/internal/domain/webhook"
 	"github.com/BerylCAtieno/paystack-wallet/internal/paystack"
 	"github.com/BerylCAtieno/paystack-wallet/internal/utils"
 	"github.com/gin-gonic/gin"
 )
 
 type WebhookHandler struct {
-	walletService  *wallet.Service
+	webhookService *webhook.Service
 	paystackSecret string
 }
 
-func NewWebhookHandler(walletService *wallet.Service, paystackSecret string) *WebhookHandler {
+func NewWebhookHandler(webhookService *webhook.Service, paystackSecret string) *WebhookHandler {
 	return &WebhookHandler{
-		walletService:  walletService,
+		webhookService: webhookService,
 		paystackSecret: paystackSecret,
 	}
 }
 
-// HandlePaystackWebhook now accepts *gin.Context
+// HandlePaystackWebhook stores a verified event and acknowledges it; the
+// event is applied to wallets asynchronously by the webhook worker.
 func (h *WebhookHandler) HandlePaystackWebhook(c *gin.Context) {
 	body, valid := paystack.ValidateWebhookSignature(c.Request, h.paystackSecret)
 	if !valid {
@@ -36,15 +41,17 @@ func (h *WebhookHandler) HandlePaystackWebhook(c *gin.Context) {
 		return
 	}
 
-	// Handle charge.success event
-	if event.Event == "charge.success" {
-		if event.Data.Status == "success" {
-			// Complete deposit
-			if err := h.walletService.CompleteDeposit(event.Data.Reference); err != nil {
-				utils.RespondError(c, http.StatusInternalServerError, "failed to complete deposit")
-				return
-			}
-		}
+	paystackID := event.ObjectID()
+	if paystackID == "" {
+		sum := sha256.Sum256(body)
+		paystackID = hex.EncodeToString(sum[:])
+	}
+
+	if _, err := h.webhookService.Record(event.Event, paystackID, body); err != nil && err != webhook.ErrDuplicate {
+		// Paystack retries deliveries that are not acknowledged
+		log.Printf("Failed to store webhook %s %s: %v", event.Event, paystackID, err)
+		utils.RespondError(c, http.StatusInternalServerError, "failed to store webhook")
+		return
 	}
 
 	utils.RespondSuccess(c, map[string]interface{}{
@@ -52,17 +59,52 @@ func (h *WebhookHandler) HandlePaystackWebhook(c *gin.Context) {
 	})
 }
 
-// GetDepositStatus now accepts *gin.Context
-func (h *WebhookHandler) GetDepositStatus(c *gin.Context) {
-	reference := c.Query("reference")
-	if reference == "" {
-		utils.RespondError(c, http.StatusBadRequest, "reference is required")
+func (h *WebhookHandler) ListEvents(c *gin.Context) {
+	limit := 0
+	if raw := c.Query("limit"); raw != "" {
+		var err error
+		if limit, err = strconv.Atoi(raw); err != nil || limit <= 0 {
+			utils.RespondError(c, http.StatusBadRequest, "limit must be a positive integer")
+			return
+		}
+	}
+
+	events, err := h.webhookService.List(webhook.Status(c.Query("status")), limit)
+	if err != nil {
+		if err == webhook.ErrInvalidStatus {
+			utils.RespondError(c, http.StatusBadRequest, err.Error())
+			return
+		}
+		utils.RespondError(c, http.StatusInternalServerError, "failed to list webhook events")
 		return
 	}
 
-	// This is a read-only endpoint - does not credit wallet
-	// Only the webhook credits wallets
-	utils.RespondSuccess(c, map[string]interface{}{
-		"message": "Use webhook for real-time updates. This endpoint is for manual verification only.",
-	})
+	utils.RespondSuccess(c, events)
+}
+
+func (h *WebhookHandler) GetEvent(c *gin.Context) {
+	event, err := h.webhookService.Get(c.Param("id"))
+	if err != nil {
+		utils.RespondError(c, http.StatusNotFound, err.Error())
+		return
+	}
+
+	utils.RespondSuccess(c, event)
+}
+
+func (h *WebhookHandler) ReplayEvent(c *gin.Context) {
+	event, err := h.webhookService.Replay(c.Param("id"))
+	if err != nil {
+		switch err {
+		case webhook.ErrNotFound:
+			utils.RespondError(c, http.StatusNotFound, err.Error())
+		case webhook.ErrNotReplayable:
+			utils.RespondError(c, http.StatusConflict, err.Error())
+		default:
+			utils.RespondError(c, http.StatusInternalServerError, "failed to replay webhook event")
+		}
+		return
+	}
+
+	utils.RespondSuccess(c, event)
 }
@@ -0,0 +1,26 @@
+package middleware
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/BerylCAtieno/paystack-wallet/internal/utils"
+	"github.com/gin-gonic/gin"
+)
+
+// AdminOnly restricts a route to users whose JWT email is listed in
+// ADMIN_EMAILS. It must run after JWTAuth.
+func AdminOnly(adminEmails []string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		email := GetUserEmail(c)
+		for _, admin := range adminEmails {
+			if email != "" && strings.EqualFold(email, admin) {
+				c.Next()
+				return
+			}
+		}
+
+		utils.RespondError(c, http.StatusForbidden, "admin access required")
+		c.Abort()
+	}
+}
@@ -1,6 +1,7 @@
 package middleware
 
 import (
+	"log"
 	"net/http"
 
 	"github.com/BerylCAtieno/paystack-wallet/internal/domain/auth"
@@ -23,10 +24,8 @@ func APIKeyAuth(authService *auth.Service) gin.HandlerFunc {
 			return
 		}
 
-		key, err := authService.ValidateAPIKey(apiKey)
-		if err != nil {
-			utils.RespondError(c, http.StatusUnauthorized, err.Error())
-			c.Abort()
+		key := authenticateAPIKey(c, authService, apiKey)
+		if key == nil {
 			return
 		}
 
@@ -36,6 +35,40 @@ func APIKeyAuth(authService *auth.Service) gin.HandlerFunc {
 	}
 }
 
+// authenticateAPIKey validates rawKey and admits the key. It writes the
+// error response, aborts and returns nil if the key cannot be used.
+func authenticateAPIKey(c *gin.Context, authService *auth.Service, rawKey string) *auth.APIKey {
+	key, err := authService.ValidateAPIKey(rawKey)
+	if err != nil {
+		utils.RespondError(c, http.StatusUnauthorized, err.Error())
+		c.Abort()
+		return nil
+	}
+
+	if !admitAPIKey(c, authService, key) {
+		return nil
+	}
+	return key
+}
+
+// admitAPIKey checks the client IP against the key's allowlist, however the
+// key was presented, and records the use. It writes a 403 response, aborts
+// and returns false if the key may not be used from here.
+func admitAPIKey(c *gin.Context, authService *auth.Service, key *auth.APIKey) bool {
+	ip := c.ClientIP()
+	if err := authService.CheckIP(key, ip); err != nil {
+		log.Printf("rejected api key %s from %s: %v", key.ID, ip, err)
+		utils.RespondError(c, http.StatusForbidden, err.Error())
+		c.Abort()
+		return false
+	}
+
+	if err := authService.RecordUse(key, ip); err != nil {
+		log.Printf("failed to record use of api key %s: %v", key.ID, err)
+	}
+	return true
+}
+
 // GetAPIKey retrieves the APIKey object from Gin context
 func GetAPIKey(c *gin.Context) *auth.APIKey {
 	val, exists := c.Get(APIKeyKey)
@@ -49,6 +82,13 @@ func GetAPIKey(c *gin.Context) *auth.APIKey {
 	return key
 }
 
+// IsTestMode reports whether the request was authenticated by a sk_test_
+// key and so must only touch sandbox wallets. JWT requests are always live.
+func IsTestMode(c *gin.Context) bool {
+	key := GetAPIKey(c)
+	return key != nil && key.IsTest()
+}
+
 // GetAPIKeyUserID retrieves the authenticated user ID from Gin Context
 func GetAPIKeyUserID(c *gin.Context) string {
 	val, exists := c.Get(APIKeyUserIDKey)
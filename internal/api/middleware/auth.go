@@ -3,12 +3,13 @@ package middleware
 import (
 	"net/http"
 
+	"github.com/BerylCAtieno/paystack-wallet/client"
 	"github.com/BerylCAtieno/paystack-wallet/internal/domain/auth"
 	"github.com/BerylCAtieno/paystack-wallet/internal/utils"
 	"github.com/gin-gonic/gin"
 )
 
-// FlexibleAuthGin allows either JWT or API Key authentication for Gin
+// FlexibleAuthGin allows JWT, API key or signed request authentication for Gin
 func FlexibleAuth(jwtSecret string, authService *auth.Service, requiredPermission ...auth.Permission) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		// Try JWT first
@@ -21,31 +22,33 @@ func FlexibleAuth(jwtSecret string, authService *auth.Service, requiredPermissio
 			return
 		}
 
-		// Try API Key
-		if apiKey := c.GetHeader("x-api-key"); apiKey != "" {
-			key, err := authService.ValidateAPIKey(apiKey)
-			if err != nil {
-				utils.RespondError(c, http.StatusUnauthorized, err.Error())
+		// Try a signed request, then a plain API key
+		var key *auth.APIKey
+		switch {
+		case c.GetHeader(client.HeaderSignature) != "":
+			key = authenticateSignature(c, authService)
+		case c.GetHeader("x-api-key") != "":
+			key = authenticateAPIKey(c, authService, c.GetHeader("x-api-key"))
+		default:
+			utils.RespondError(c, http.StatusUnauthorized, "authentication required")
+			c.Abort()
+			return
+		}
+		if key == nil {
+			return
+		}
+
+		// Check required permissions
+		for _, perm := range requiredPermission {
+			if !key.HasPermission(perm) {
+				utils.RespondError(c, http.StatusForbidden, "insufficient permissions")
 				c.Abort()
 				return
 			}
-
-			// Check required permissions
-			for _, perm := range requiredPermission {
-				if !key.HasPermission(perm) {
-					utils.RespondError(c, http.StatusForbidden, "insufficient permissions")
-					c.Abort()
-					return
-				}
-			}
-
-			c.Set(APIKeyKey, key)
-			c.Set(APIKeyUserIDKey, key.UserID)
-			c.Next()
-			return
 		}
 
-		utils.RespondError(c, http.StatusUnauthorized, "authentication required")
-		c.Abort()
+		c.Set(APIKeyKey, key)
+		c.Set(APIKeyUserIDKey, key.UserID)
+		c.Next()
 	}
 }
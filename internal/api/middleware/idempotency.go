@@ -0,0 +1,115 @@
+package middleware
+
+import (
+	"bytes"
+	"io"
+	"log"
+	"net/http"
+
+	"github.com/BerylCAtieno/paystack-wallet/internal/domain/idempotency"
+	"github.com/BerylCAtieno/paystack-wallet/internal/utils"
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	IdempotencyKeyHeader     = "Idempotency-Key"
+	IdempotentReplayedHeader = "Idempotent-Replayed"
+	maxIdempotencyKeyLength  = 255
+)
+
+// responseRecorder keeps a copy of everything the handler writes
+type responseRecorder struct {
+	gin.ResponseWriter
+	body bytes.Buffer
+}
+
+func (w *responseRecorder) Write(b []byte) (int, error) {
+	w.body.Write(b)
+	return w.ResponseWriter.Write(b)
+}
+
+func (w *responseRecorder) WriteString(s string) (int, error) {
+	w.body.WriteString(s)
+	return w.ResponseWriter.WriteString(s)
+}
+
+// Idempotency replays the stored response when a request is retried with the
+// same Idempotency-Key header. It must run after authentication because keys
+// are scoped per user. Requests without the header are passed through.
+func Idempotency(service *idempotency.Service) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		key := c.GetHeader(IdempotencyKeyHeader)
+		if key == "" {
+			c.Next()
+			return
+		}
+
+		if len(key) > maxIdempotencyKeyLength {
+			utils.RespondError(c, http.StatusBadRequest, "idempotency key is too long")
+			c.Abort()
+			return
+		}
+
+		userID := GetUserID(c)
+		if userID == "" {
+			userID = GetAPIKeyUserID(c)
+		}
+		if userID == "" {
+			utils.RespondError(c, http.StatusUnauthorized, "authentication required")
+			c.Abort()
+			return
+		}
+
+		body, err := io.ReadAll(c.Request.Body)
+		if err != nil {
+			utils.RespondError(c, http.StatusBadRequest, "invalid request body")
+			c.Abort()
+			return
+		}
+		c.Request.Body = io.NopCloser(bytes.NewReader(body))
+
+		hash := idempotency.HashRequest(c.Request.Method, c.Request.URL.Path, body)
+
+		stored, err := service.Begin(userID, key, hash)
+		switch err {
+		case nil:
+		case idempotency.ErrKeyReused:
+			utils.RespondError(c, http.StatusUnprocessableEntity, err.Error())
+			c.Abort()
+			return
+		case idempotency.ErrInProgress:
+			utils.RespondError(c, http.StatusConflict, err.Error())
+			c.Abort()
+			return
+		default:
+			utils.RespondError(c, http.StatusInternalServerError, "failed to check idempotency key")
+			c.Abort()
+			return
+		}
+
+		if stored != nil {
+			c.Header(IdempotentReplayedHeader, "true")
+			c.Data(stored.StatusCode, "application/json; charset=utf-8", stored.ResponseBody)
+			c.Abort()
+			return
+		}
+
+		recorder := &responseRecorder{ResponseWriter: c.Writer}
+		c.Writer = recorder
+
+		c.Next()
+
+		// Server errors are not replayed so the client can retry them
+		status := recorder.Status()
+		if status >= http.StatusInternalServerError {
+			if err := service.Release(userID, key); err != nil {
+				log.Printf("failed to release idempotency key %s: %v", key, err)
+			}
+			return
+		}
+
+		if err := service.Complete(userID, key, status, recorder.body.Bytes()); err != nil {
+			log.Printf("failed to store idempotent response for key %s: %v", key, err)
+		}
+	}
+}
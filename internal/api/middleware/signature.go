@@ -0,0 +1,69 @@
+package middleware
+
+import (
+	"bytes"
+	"io"
+	"log"
+	"net/http"
+
+	"github.com/BerylCAtieno/paystack-wallet/client"
+	"github.com/BerylCAtieno/paystack-wallet/internal/domain/auth"
+	"github.com/BerylCAtieno/paystack-wallet/internal/utils"
+	"github.com/gin-gonic/gin"
+)
+
+// SignatureAuth is a Gin middleware for HMAC-signed requests, see the client
+// package for the scheme
+func SignatureAuth(authService *auth.Service) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if c.GetHeader(client.HeaderSignature) == "" {
+			utils.RespondError(c, http.StatusUnauthorized, "missing request signature")
+			c.Abort()
+			return
+		}
+
+		key := authenticateSignature(c, authService)
+		if key == nil {
+			return
+		}
+
+		c.Set(APIKeyKey, key)
+		c.Set(APIKeyUserIDKey, key.UserID)
+		c.Next()
+	}
+}
+
+// authenticateSignature verifies a signed request and admits the key that
+// signed it. It writes the error response, aborts and returns nil if the
+// request cannot be authenticated.
+func authenticateSignature(c *gin.Context, authService *auth.Service) *auth.APIKey {
+	body, err := io.ReadAll(c.Request.Body)
+	if err != nil {
+		utils.RespondError(c, http.StatusBadRequest, "invalid request body")
+		c.Abort()
+		return nil
+	}
+	c.Request.Body = io.NopCloser(bytes.NewReader(body))
+
+	req := auth.SignedRequest{
+		KeyID:     c.GetHeader(client.HeaderKeyID),
+		Timestamp: c.GetHeader(client.HeaderTimestamp),
+		Nonce:     c.GetHeader(client.HeaderNonce),
+		Signature: c.GetHeader(client.HeaderSignature),
+	}
+	req.StringToSign = client.StringToSign(req.KeyID, req.Timestamp, req.Nonce,
+		c.Request.Method, c.Request.URL.RequestURI(), body)
+
+	key, err := authService.VerifySignature(req, c.ClientIP())
+	if err != nil {
+		log.Printf("rejected signed request for api key %s from %s: %v", req.KeyID, c.ClientIP(), err)
+		utils.RespondError(c, http.StatusUnauthorized, err.Error())
+		c.Abort()
+		return nil
+	}
+
+	if !admitAPIKey(c, authService, key) {
+		return nil
+	}
+	return key
+}
@@ -0,0 +1,113 @@
+package router
+
+import (
+	"log"
+	"net/http"
+	"sync"
+
+	"github.com/BerylCAtieno/paystack-wallet/internal/api/handlers"
+	"github.com/BerylCAtieno/paystack-wallet/internal/api/middleware"
+	"github.com/BerylCAtieno/paystack-wallet/internal/domain/bank"
+	"github.com/BerylCAtieno/paystack-wallet/internal/domain/beneficiary"
+	"github.com/BerylCAtieno/paystack-wallet/internal/domain/fx"
+	"github.com/BerylCAtieno/paystack-wallet/internal/domain/idempotency"
+	"github.com/BerylCAtieno/paystack-wallet/internal/domain/refund"
+	"github.com/BerylCAtieno/paystack-wallet/internal/domain/wallet"
+	"github.com/BerylCAtieno/paystack-wallet/internal/domain/webhook"
+	"github.com/BerylCAtieno/paystack-wallet/internal/money"
+	"github.com/BerylCAtieno/paystack-wallet/internal/paystack"
+	"github.com/BerylCAtieno/paystack-wallet/internal/repository"
+	"github.com/BerylCAtieno/paystack-wallet/internal/settlement"
+	"github.com/BerylCAtieno/paystack-wallet/internal/utils"
+	"github.com/gin-gonic/gin"
+)
+
+// Environment is everything that holds or moves money. The live environment
+// serves JWTs and sk_live_ keys; the sandbox serves sk_test_ keys from its
+// own database and Paystack test account.
+type Environment struct {
+	WalletService      *wallet.Service
+	IdempotencyService *idempotency.Service
+	RefundService      *refund.Service
+	FXService          *fx.Service
+	WebhookService     *webhook.Service
+	Sweeper            *settlement.Sweeper
+	Paystack           *paystack.Client
+	// Users is the environment's copy of the users table. Users sign in
+	// live and are copied to the sandbox the first time they use it.
+	Users           *repository.UserRepository
+	WalletRepo      *repository.WalletRepository
+	BankRepo        *repository.BankRepository
+	BeneficiaryRepo *repository.BeneficiaryRepository
+}
+
+// environmentHandlers are the handlers serving one environment
+type environmentHandlers struct {
+	wallet      *handlers.WalletHandler
+	beneficiary *handlers.BeneficiaryHandler
+	hold        *handlers.HoldHandler
+	exchange    *handlers.ExchangeHandler
+	bank        *handlers.BankHandler
+	idempotency gin.HandlerFunc
+}
+
+func (r *Router) newEnvironmentHandlers(env *Environment) *environmentHandlers {
+	bankService := bank.NewService(env.BankRepo, env.Paystack, r.cfg.BankCacheTTL)
+	beneficiaryService := beneficiary.NewService(env.BeneficiaryRepo, env.WalletRepo, bankService)
+
+	return &environmentHandlers{
+		wallet:      handlers.NewWalletHandler(env.WalletService, beneficiaryService, env.RefundService, env.FXService, env.WalletRepo, env.Paystack),
+		beneficiary: handlers.NewBeneficiaryHandler(beneficiaryService, env.WalletRepo),
+		hold:        handlers.NewHoldHandler(env.WalletService, env.WalletRepo, r.cfg.HoldTTL),
+		exchange:    handlers.NewExchangeHandler(env.FXService, env.WalletService, env.WalletRepo),
+		bank:        handlers.NewBankHandler(bankService),
+		idempotency: middleware.Idempotency(env.IdempotencyService),
+	}
+}
+
+// byMode serves a request with the sandbox handler when it was authenticated
+// by a test key and with the live handler otherwise
+func (r *Router) byMode(live, sandbox gin.HandlerFunc) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if !middleware.IsTestMode(c) {
+			live(c)
+			return
+		}
+
+		if err := r.sandboxAccounts.ensure(middleware.GetAPIKeyUserID(c)); err != nil {
+			log.Printf("failed to set up sandbox account: %v", err)
+			utils.RespondError(c, http.StatusInternalServerError, "failed to set up sandbox account")
+			c.Abort()
+			return
+		}
+		sandbox(c)
+	}
+}
+
+// sandboxAccounts copies users into the sandbox and opens their NGN sandbox
+// wallet the first time they use a test key
+type sandboxAccounts struct {
+	live    *Environment
+	sandbox *Environment
+	ready   sync.Map
+}
+
+func (a *sandboxAccounts) ensure(userID string) error {
+	if _, ok := a.ready.Load(userID); ok {
+		return nil
+	}
+
+	u, err := a.live.Users.GetByID(userID)
+	if err != nil {
+		return err
+	}
+	if err := a.sandbox.Users.CreateIfMissing(u); err != nil {
+		return err
+	}
+	if _, err := a.sandbox.WalletService.GetOrCreateWallet(userID, money.DefaultCurrency); err != nil {
+		return err
+	}
+
+	a.ready.Store(userID, true)
+	return nil
+}
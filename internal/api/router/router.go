@@ -1,16 +1,16 @@
 package router
 
 import (
+	"log"
 	"net/http"
+	"strings"
 
+	"github.com/BerylCAtieno/paystack-wallet/client"
 	"github.com/BerylCAtieno/paystack-wallet/docs"
 	"github.com/BerylCAtieno/paystack-wallet/internal/api/handlers"
 	"github.com/BerylCAtieno/paystack-wallet/internal/api/middleware"
 	"github.com/BerylCAtieno/paystack-wallet/internal/config"
 	"github.com/BerylCAtieno/paystack-wallet/internal/domain/auth"
-	"github.com/BerylCAtieno/paystack-wallet/internal/domain/wallet"
-	"github.com/BerylCAtieno/paystack-wallet/internal/paystack"
-	"github.com/BerylCAtieno/paystack-wallet/internal/repository"
 	"github.com/gin-contrib/cors"
 
 	"github.com/gin-gonic/gin"
@@ -20,45 +20,69 @@ import (
 )
 
 type Router struct {
-	Engine        *gin.Engine
-	cfg           *config.Config
-	authService   *auth.Service
-	walletService *wallet.Service
-	walletRepo    *repository.WalletRepository
-	userRepo      *repository.UserRepository
+	Engine      *gin.Engine
+	cfg         *config.Config
+	authService *auth.Service
+	live        *Environment
+	sandbox     *Environment
+
+	liveHandlers    *environmentHandlers
+	sandboxHandlers *environmentHandlers
+	sandboxAccounts *sandboxAccounts
 }
 
-func NewRouter(
-	cfg *config.Config,
-	authService *auth.Service,
-	walletService *wallet.Service,
-	walletRepo *repository.WalletRepository,
-	userRepo *repository.UserRepository,
-) *Router {
+func NewRouter(cfg *config.Config, authService *auth.Service, live, sandbox *Environment) *Router {
 	engine := gin.Default()
+	configureClientIP(engine, cfg)
 
 	// CORS Configuration
 	engine.Use(cors.New(cors.Config{
 		AllowOrigins:     []string{"http://localhost:8080/docs", "https://paystack-wallet.fly.dev/docs", "https://paystack-wallet-beryl-673dde33fda9.herokuapp.com/"},
 		AllowMethods:     []string{"GET", "POST", "PUT", "DELETE", "OPTIONS"},
-		AllowHeaders:     []string{"Origin", "Content-Type", "Authorization", "x-api-key", "x-paystack-signature"},
-		ExposeHeaders:    []string{"Content-Length"},
+		AllowHeaders:     []string{"Origin", "Content-Type", "Authorization", "x-api-key", "x-paystack-signature", "Idempotency-Key", client.HeaderKeyID, client.HeaderTimestamp, client.HeaderNonce, client.HeaderSignature},
+		ExposeHeaders:    []string{"Content-Length", "Idempotent-Replayed"},
 		AllowCredentials: true,
 	}))
 
 	r := &Router{
-		Engine:        engine,
-		cfg:           cfg,
-		authService:   authService,
-		walletService: walletService,
-		walletRepo:    walletRepo,
-		userRepo:      userRepo,
+		Engine:          engine,
+		cfg:             cfg,
+		authService:     authService,
+		live:            live,
+		sandbox:         sandbox,
+		sandboxAccounts: &sandboxAccounts{live: live, sandbox: sandbox},
 	}
+	r.liveHandlers = r.newEnvironmentHandlers(live)
+	r.sandboxHandlers = r.newEnvironmentHandlers(sandbox)
 
 	r.setupRoutes()
 	return r
 }
 
+// platformHeaders are the TRUSTED_PLATFORM shorthands for the header each
+// platform's edge puts the client IP in
+var platformHeaders = map[string]string{
+	"fly":        gin.PlatformFlyIO,
+	"cloudflare": gin.PlatformCloudflare,
+}
+
+// configureClientIP decides where c.ClientIP(), and so API key allowlists,
+// read the client IP from. Forwarding headers are only trusted from the
+// configured platform or proxies, so callers cannot spoof their IP.
+func configureClientIP(engine *gin.Engine, cfg *config.Config) {
+	if cfg.TrustedPlatform != "" {
+		engine.TrustedPlatform = cfg.TrustedPlatform
+		if header, ok := platformHeaders[strings.ToLower(cfg.TrustedPlatform)]; ok {
+			engine.TrustedPlatform = header
+		}
+	}
+
+	if err := engine.SetTrustedProxies(cfg.TrustedProxies); err != nil {
+		log.Printf("Invalid TRUSTED_PROXIES, trusting no proxies: %v", err)
+		_ = engine.SetTrustedProxies(nil)
+	}
+}
+
 func (r *Router) setupRoutes() {
 	r.Engine.GET("/", func(c *gin.Context) {
 		c.String(http.StatusOK, "Welcome to the Wallet Service")
@@ -90,8 +114,8 @@ func (r *Router) setupRoutes() {
 
 	// AUTH ROUTES
 	authHandler := handlers.NewAuthHandler(
-		r.userRepo,
-		r.walletService,
+		r.live.Users,
+		r.live.WalletService,
 		r.cfg.GoogleClientID,
 		r.cfg.GoogleClientSecret,
 		r.cfg.GoogleRedirectURL,
@@ -101,50 +125,227 @@ func (r *Router) setupRoutes() {
 	r.Engine.GET("/auth/google", authHandler.GoogleLogin)
 	r.Engine.GET("/auth/google/callback", authHandler.GoogleCallback)
 
-	// API KEY ROUTES (JWT)
+	// API KEY ROUTES (JWT/API KEY with keys:manage)
 	apiKeyHandler := handlers.NewAPIKeyHandler(r.authService)
 
 	keysGroup := r.Engine.Group("/keys")
-	keysGroup.Use(middleware.JWTAuth(r.cfg.JWTSecret))
+	keysGroup.Use(middleware.FlexibleAuth(r.cfg.JWTSecret, r.authService, auth.PermissionKeysManage))
 	{
+		keysGroup.GET("", apiKeyHandler.ListAPIKeys)
 		keysGroup.POST("/create", apiKeyHandler.CreateAPIKey)
 		keysGroup.POST("/rollover", apiKeyHandler.RolloverAPIKey)
+		keysGroup.GET("/:id", apiKeyHandler.GetAPIKey)
+		keysGroup.POST("/:id/revoke", apiKeyHandler.RevokeAPIKey)
+		keysGroup.PUT("/:id/allowed-ips", apiKeyHandler.SetAllowedIPs)
+		keysGroup.GET("/:id/audit", apiKeyHandler.ListAuditEvents)
+		keysGroup.GET("/:id/signing-secret", apiKeyHandler.GetSigningSecret)
 	}
 
-	// WALLET ROUTES (JWT/API KEY)
-	paystackClient := paystack.NewClient(r.cfg.PaystackSecretKey)
-	walletHandler := handlers.NewWalletHandler(r.walletService, r.walletRepo, paystackClient)
+	// WALLET ROUTES (JWT/API KEY, sk_test_ keys are served by the sandbox)
+	live, sandbox := r.liveHandlers, r.sandboxHandlers
+	idempotent := r.byMode(live.idempotency, sandbox.idempotency)
 
 	walletGroup := r.Engine.Group("/wallet")
 	{
 		walletGroup.POST(
 			"/deposit",
 			middleware.FlexibleAuth(r.cfg.JWTSecret, r.authService, auth.PermissionDeposit),
-			walletHandler.InitiateDeposit,
+			idempotent,
+			r.byMode(live.wallet.InitiateDeposit, sandbox.wallet.InitiateDeposit),
 		)
 
 		walletGroup.GET(
-			"/balance",
+			"/limits",
 			middleware.FlexibleAuth(r.cfg.JWTSecret, r.authService, auth.PermissionRead),
-			walletHandler.GetBalance,
+			r.byMode(live.wallet.GetLimits, sandbox.wallet.GetLimits),
+		)
+
+		walletGroup.GET(
+			"/currencies",
+			middleware.FlexibleAuth(r.cfg.JWTSecret, r.authService, auth.PermissionBalanceRead),
+			r.byMode(live.wallet.ListWallets, sandbox.wallet.ListWallets),
+		)
+
+		walletGroup.POST(
+			"/currencies",
+			middleware.FlexibleAuth(r.cfg.JWTSecret, r.authService, auth.PermissionDeposit),
+			r.byMode(live.wallet.OpenWallet, sandbox.wallet.OpenWallet),
+		)
+
+		walletGroup.GET(
+			"/balance",
+			middleware.FlexibleAuth(r.cfg.JWTSecret, r.authService, auth.PermissionBalanceRead),
+			r.byMode(live.wallet.GetBalance, sandbox.wallet.GetBalance),
 		)
 
 		walletGroup.POST(
 			"/transfer",
 			middleware.FlexibleAuth(r.cfg.JWTSecret, r.authService, auth.PermissionTransfer),
-			walletHandler.Transfer,
+			idempotent,
+			r.byMode(live.wallet.Transfer, sandbox.wallet.Transfer),
+		)
+
+		walletGroup.POST(
+			"/transfer/quote",
+			middleware.FlexibleAuth(r.cfg.JWTSecret, r.authService, auth.PermissionTransfer),
+			r.byMode(live.wallet.QuoteTransfer, sandbox.wallet.QuoteTransfer),
+		)
+
+		walletGroup.POST(
+			"/exchange/quote",
+			middleware.FlexibleAuth(r.cfg.JWTSecret, r.authService, auth.PermissionTransfer),
+			r.byMode(live.exchange.Quote, sandbox.exchange.Quote),
+		)
+
+		walletGroup.GET(
+			"/exchange/quote/:id",
+			middleware.FlexibleAuth(r.cfg.JWTSecret, r.authService, auth.PermissionRead),
+			r.byMode(live.exchange.GetQuote, sandbox.exchange.GetQuote),
+		)
+
+		walletGroup.POST(
+			"/exchange",
+			middleware.FlexibleAuth(r.cfg.JWTSecret, r.authService, auth.PermissionTransfer),
+			idempotent,
+			r.byMode(live.exchange.Exchange, sandbox.exchange.Exchange),
+		)
+
+		walletGroup.POST(
+			"/withdraw",
+			middleware.FlexibleAuth(r.cfg.JWTSecret, r.authService, auth.PermissionWithdraw),
+			idempotent,
+			r.byMode(live.wallet.Withdraw, sandbox.wallet.Withdraw),
+		)
+
+		walletGroup.POST(
+			"/withdraw/finalize",
+			middleware.FlexibleAuth(r.cfg.JWTSecret, r.authService, auth.PermissionWithdraw),
+			idempotent,
+			r.byMode(live.wallet.FinalizeWithdrawal, sandbox.wallet.FinalizeWithdrawal),
 		)
 
 		walletGroup.GET(
 			"/transactions",
+			middleware.FlexibleAuth(r.cfg.JWTSecret, r.authService, auth.PermissionTransactionsRead),
+			r.byMode(live.wallet.GetTransactions, sandbox.wallet.GetTransactions),
+		)
+
+		walletGroup.GET(
+			"/transactions/:reference",
+			middleware.FlexibleAuth(r.cfg.JWTSecret, r.authService, auth.PermissionTransactionsRead),
+			r.byMode(live.wallet.GetTransaction, sandbox.wallet.GetTransaction),
+		)
+
+		walletGroup.GET(
+			"/deposit/:reference/status",
+			middleware.FlexibleAuth(r.cfg.JWTSecret, r.authService, auth.PermissionTransactionsRead),
+			r.byMode(live.wallet.GetDepositStatus, sandbox.wallet.GetDepositStatus),
+		)
+
+		walletGroup.POST(
+			"/deposit/:reference/refund",
+			middleware.FlexibleAuth(r.cfg.JWTSecret, r.authService, auth.PermissionWithdraw),
+			idempotent,
+			r.byMode(live.wallet.RefundDeposit, sandbox.wallet.RefundDeposit),
+		)
+
+		walletGroup.GET(
+			"/holds",
+			middleware.FlexibleAuth(r.cfg.JWTSecret, r.authService, auth.PermissionRead),
+			r.byMode(live.hold.List, sandbox.hold.List),
+		)
+
+		walletGroup.POST(
+			"/holds",
+			middleware.FlexibleAuth(r.cfg.JWTSecret, r.authService, auth.PermissionTransfer),
+			idempotent,
+			r.byMode(live.hold.Place, sandbox.hold.Place),
+		)
+
+		walletGroup.GET(
+			"/holds/:id",
+			middleware.FlexibleAuth(r.cfg.JWTSecret, r.authService, auth.PermissionRead),
+			r.byMode(live.hold.Get, sandbox.hold.Get),
+		)
+
+		walletGroup.POST(
+			"/holds/:id/capture",
+			middleware.FlexibleAuth(r.cfg.JWTSecret, r.authService, auth.PermissionTransfer),
+			idempotent,
+			r.byMode(live.hold.Capture, sandbox.hold.Capture),
+		)
+
+		walletGroup.POST(
+			"/holds/:id/release",
+			middleware.FlexibleAuth(r.cfg.JWTSecret, r.authService, auth.PermissionTransfer),
+			r.byMode(live.hold.Release, sandbox.hold.Release),
+		)
+
+		walletGroup.GET(
+			"/beneficiaries",
+			middleware.FlexibleAuth(r.cfg.JWTSecret, r.authService, auth.PermissionRead),
+			r.byMode(live.beneficiary.List, sandbox.beneficiary.List),
+		)
+
+		walletGroup.POST(
+			"/beneficiaries",
+			middleware.FlexibleAuth(r.cfg.JWTSecret, r.authService, auth.PermissionTransfer),
+			r.byMode(live.beneficiary.Create, sandbox.beneficiary.Create),
+		)
+
+		walletGroup.GET(
+			"/beneficiaries/:id",
 			middleware.FlexibleAuth(r.cfg.JWTSecret, r.authService, auth.PermissionRead),
-			walletHandler.GetTransactions,
+			r.byMode(live.beneficiary.Get, sandbox.beneficiary.Get),
+		)
+
+		walletGroup.PUT(
+			"/beneficiaries/:id",
+			middleware.FlexibleAuth(r.cfg.JWTSecret, r.authService, auth.PermissionTransfer),
+			r.byMode(live.beneficiary.Update, sandbox.beneficiary.Update),
 		)
+
+		walletGroup.DELETE(
+			"/beneficiaries/:id",
+			middleware.FlexibleAuth(r.cfg.JWTSecret, r.authService, auth.PermissionTransfer),
+			r.byMode(live.beneficiary.Delete, sandbox.beneficiary.Delete),
+		)
+	}
+
+	// BANK ROUTES (JWT/API KEY)
+	banksGroup := r.Engine.Group("/banks")
+	banksGroup.Use(middleware.FlexibleAuth(r.cfg.JWTSecret, r.authService))
+	{
+		banksGroup.GET("", r.byMode(live.bank.ListBanks, sandbox.bank.ListBanks))
+		banksGroup.POST("/resolve", r.byMode(live.bank.ResolveAccount, sandbox.bank.ResolveAccount))
 	}
 
 	// WEBHOOK
-	webhookHandler := handlers.NewWebhookHandler(r.walletService, r.cfg.PaystackSecretKey)
+	webhookHandler := handlers.NewWebhookHandler(r.live.WebhookService, r.cfg.PaystackSecretKey)
 
 	r.Engine.POST("/wallet/paystack/webhook", webhookHandler.HandlePaystackWebhook)
-	r.Engine.GET("/wallet/deposit/:reference/status", webhookHandler.GetDepositStatus)
+
+	// Paystack test mode sends its webhooks here when the sandbox uses it
+	// rather than the local fake
+	if r.cfg.PaystackTestKey != "" {
+		sandboxWebhookHandler := handlers.NewWebhookHandler(r.sandbox.WebhookService, r.cfg.PaystackTestKey)
+		r.Engine.POST("/wallet/paystack/webhook/test", sandboxWebhookHandler.HandlePaystackWebhook)
+	}
+
+	// ADMIN ROUTES (JWT, ADMIN_EMAILS only)
+	adminHandler := handlers.NewAdminHandler(r.live.WalletService, r.live.Sweeper, r.live.Users)
+
+	adminGroup := r.Engine.Group("/admin")
+	adminGroup.Use(middleware.JWTAuth(r.cfg.JWTSecret), middleware.AdminOnly(r.cfg.AdminEmails))
+	{
+		adminGroup.GET("/webhooks", webhookHandler.ListEvents)
+		adminGroup.GET("/webhooks/:id", webhookHandler.GetEvent)
+		adminGroup.POST("/webhooks/:id/replay", webhookHandler.ReplayEvent)
+		adminGroup.GET("/deposits/review", adminHandler.ListDepositsInReview)
+		adminGroup.GET("/deposits/sweeper", adminHandler.SweeperMetrics)
+		adminGroup.POST("/deposits/:reference/approve", adminHandler.ApproveDeposit)
+		adminGroup.POST("/deposits/:reference/reject", adminHandler.RejectDeposit)
+		adminGroup.POST("/deposits/:reference/refund", r.liveHandlers.wallet.AdminRefundDeposit)
+		adminGroup.PUT("/users/:id/tier", adminHandler.UpdateTier)
+	}
 }
@@ -3,6 +3,9 @@ package config
 import (
 	"log"
 	"os"
+	"strconv"
+	"strings"
+	"time"
 
 	"github.com/joho/godotenv"
 )
@@ -16,6 +19,60 @@ type Config struct {
 	JWTSecret          string
 	PaystackSecretKey  string
 	PaystackPublicKey  string
+	BankCacheTTL       time.Duration
+	IdempotencyTTL     time.Duration
+	AdminEmails        []string
+	// WebhookMaxAttempts is how often an event is tried before it is
+	// marked failed and left for an admin to replay
+	WebhookMaxAttempts  int
+	WebhookPollInterval time.Duration
+	// Pending deposits are verified with Paystack once they are older than
+	// DepositVerifyAfter and expired once older than DepositExpiry
+	DepositSweepInterval time.Duration
+	DepositVerifyAfter   time.Duration
+	DepositExpiry        time.Duration
+	// HoldTTL is how long an authorization hold reserves funds when the
+	// request does not say; expired holds are released every
+	// HoldExpiryInterval
+	HoldTTL            time.Duration
+	HoldExpiryInterval time.Duration
+	// FXProvider selects where exchange rates come from: "local" fixed
+	// rates, a "static" JSON file at FXRatesFile or an "http" feed at
+	// FXRatesURL cached for FXRatesCacheTTL, and served for at most
+	// FXRatesMaxAge while the feed is down
+	FXProvider      string
+	FXRatesFile     string
+	FXRatesURL      string
+	FXRatesCacheTTL time.Duration
+	FXRatesMaxAge   time.Duration
+	// FXQuoteTTL is how long a quoted rate can be used
+	FXQuoteTTL time.Duration
+	// FXSpreadBps is the share of converted amounts kept as revenue, in
+	// basis points
+	FXSpreadBps int
+	// FeeRulesFile is a JSON array of fee rules; the built-in rules that
+	// pass on Paystack's NGN pricing apply when it is empty
+	FeeRulesFile string
+	// LimitRulesFile is a JSON array of per-tier transaction limits; the
+	// built-in CBN tier limits apply when it is empty
+	LimitRulesFile string
+	// Requests made with sk_test_ keys use the sandbox database at
+	// SandboxDBPath and Paystack's test mode with PaystackTestKey, or an
+	// in-memory fake of Paystack when it is empty
+	SandboxDBPath   string
+	PaystackTestKey string
+	// The client IP that API key allowlists are checked against is read
+	// from the header TrustedPlatform names ("fly" for Fly-Client-IP), or
+	// from X-Forwarded-For when the request comes from one of the
+	// TrustedProxies CIDR ranges, or else from the connection
+	TrustedPlatform string
+	TrustedProxies  []string
+	// SignatureMaxSkew is how far the timestamp of a signed request may be
+	// from the server's clock; nonces are remembered for as long
+	SignatureMaxSkew time.Duration
+	// SigningSecret is the server secret every key's request signing
+	// secret is derived from; JWTSecret is used when it is empty
+	SigningSecret string
 }
 
 func Load() *Config {
@@ -24,14 +81,39 @@ func Load() *Config {
 	}
 
 	return &Config{
-		Port:               getEnv("PORT", "8080"),
-		DBPath:             getEnv("DB_PATH", "./wallet.db"),
-		GoogleClientID:     getEnv("GOOGLE_CLIENT_ID", ""),
-		GoogleClientSecret: getEnv("GOOGLE_CLIENT_SECRET", ""),
-		GoogleRedirectURL:  getEnv("GOOGLE_REDIRECT_URL", "http://localhost:8080/auth/google/callback"),
-		JWTSecret:          getEnv("JWT_SECRET", ""),
-		PaystackSecretKey:  getEnv("PAYSTACK_SECRET_KEY", ""),
-		PaystackPublicKey:  getEnv("PAYSTACK_PUBLIC_KEY", ""),
+		Port:                 getEnv("PORT", "8080"),
+		DBPath:               getEnv("DB_PATH", "./wallet.db"),
+		GoogleClientID:       getEnv("GOOGLE_CLIENT_ID", ""),
+		GoogleClientSecret:   getEnv("GOOGLE_CLIENT_SECRET", ""),
+		GoogleRedirectURL:    getEnv("GOOGLE_REDIRECT_URL", "http://localhost:8080/auth/google/callback"),
+		JWTSecret:            getEnv("JWT_SECRET", ""),
+		PaystackSecretKey:    getEnv("PAYSTACK_SECRET_KEY", ""),
+		PaystackPublicKey:    getEnv("PAYSTACK_PUBLIC_KEY", ""),
+		BankCacheTTL:         getEnvDuration("BANK_CACHE_TTL", 24*time.Hour),
+		IdempotencyTTL:       getEnvDuration("IDEMPOTENCY_TTL", 24*time.Hour),
+		AdminEmails:          getEnvList("ADMIN_EMAILS"),
+		WebhookMaxAttempts:   getEnvInt("WEBHOOK_MAX_ATTEMPTS", 5),
+		WebhookPollInterval:  getEnvDuration("WEBHOOK_POLL_INTERVAL", 10*time.Second),
+		DepositSweepInterval: getEnvDuration("DEPOSIT_SWEEP_INTERVAL", 5*time.Minute),
+		DepositVerifyAfter:   getEnvDuration("DEPOSIT_VERIFY_AFTER", 15*time.Minute),
+		DepositExpiry:        getEnvDuration("DEPOSIT_EXPIRY", 24*time.Hour),
+		HoldTTL:              getEnvDuration("HOLD_TTL", 7*24*time.Hour),
+		HoldExpiryInterval:   getEnvDuration("HOLD_EXPIRY_INTERVAL", time.Minute),
+		FXProvider:           getEnv("FX_PROVIDER", "local"),
+		FXRatesFile:          getEnv("FX_RATES_FILE", ""),
+		FXRatesURL:           getEnv("FX_RATES_URL", ""),
+		FXRatesCacheTTL:      getEnvDuration("FX_RATES_CACHE_TTL", 5*time.Minute),
+		FXRatesMaxAge:        getEnvDuration("FX_RATES_MAX_AGE", time.Hour),
+		FXQuoteTTL:           getEnvDuration("FX_QUOTE_TTL", 30*time.Second),
+		FXSpreadBps:          getEnvInt("FX_SPREAD_BPS", 100),
+		FeeRulesFile:         getEnv("FEE_RULES_FILE", ""),
+		LimitRulesFile:       getEnv("LIMIT_RULES_FILE", ""),
+		SandboxDBPath:        getEnv("SANDBOX_DB_PATH", "./wallet_sandbox.db"),
+		PaystackTestKey:      getEnv("PAYSTACK_TEST_SECRET_KEY", ""),
+		TrustedPlatform:      getEnv("TRUSTED_PLATFORM", ""),
+		TrustedProxies:       getEnvList("TRUSTED_PROXIES"),
+		SignatureMaxSkew:     getEnvDuration("SIGNATURE_MAX_SKEW", 5*time.Minute),
+		SigningSecret:        getEnv("SIGNING_SECRET", ""),
 	}
 }
 
@@ -41,3 +123,42 @@ func getEnv(key, fallback string) string {
 	}
 	return fallback
 }
+
+func getEnvDuration(key string, fallback time.Duration) time.Duration {
+	value := os.Getenv(key)
+	if value == "" {
+		return fallback
+	}
+
+	d, err := time.ParseDuration(value)
+	if err != nil {
+		log.Printf("Invalid duration for %s, using default %s", key, fallback)
+		return fallback
+	}
+	return d
+}
+
+func getEnvInt(key string, fallback int) int {
+	value := os.Getenv(key)
+	if value == "" {
+		return fallback
+	}
+
+	n, err := strconv.Atoi(value)
+	if err != nil {
+		log.Printf("Invalid integer for %s, using default %d", key, fallback)
+		return fallback
+	}
+	return n
+}
+
+// getEnvList reads a comma-separated list, skipping empty items
+func getEnvList(key string) []string {
+	var items []string
+	for _, item := range strings.Split(os.Getenv(key), ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
@@ -0,0 +1,55 @@
+CREATE TABLE IF NOT EXISTS ledger_accounts (
+    id TEXT PRIMARY KEY,
+    name TEXT NOT NULL,
+    type TEXT NOT NULL,
+    currency TEXT NOT NULL,
+    wallet_id TEXT UNIQUE,
+    created_at DATETIME NOT NULL,
+    FOREIGN KEY (wallet_id) REFERENCES wallets(id)
+);
+
+CREATE TABLE IF NOT EXISTS journal_entries (
+    id TEXT PRIMARY KEY,
+    reference TEXT NOT NULL,
+    description TEXT,
+    created_at DATETIME NOT NULL
+);
+CREATE INDEX IF NOT EXISTS idx_journal_entries_reference ON journal_entries(reference);
+
+-- Positive amounts are debits, negative amounts are credits
+CREATE TABLE IF NOT EXISTS postings (
+    id TEXT PRIMARY KEY,
+    entry_id TEXT NOT NULL,
+    account_id TEXT NOT NULL,
+    amount INTEGER NOT NULL,
+    created_at DATETIME NOT NULL,
+    FOREIGN KEY (entry_id) REFERENCES journal_entries(id),
+    FOREIGN KEY (account_id) REFERENCES ledger_accounts(id)
+);
+CREATE INDEX IF NOT EXISTS idx_postings_entry_id ON postings(entry_id);
+CREATE INDEX IF NOT EXISTS idx_postings_account_id ON postings(account_id);
+
+ALTER TABLE transactions ADD COLUMN journal_entry_id TEXT NOT NULL DEFAULT '';
+
+INSERT OR IGNORE INTO ledger_accounts (id, name, type, currency, wallet_id, created_at) VALUES
+    ('system:paystack_settlement:NGN', 'Paystack settlement', 'asset', 'NGN', NULL, CURRENT_TIMESTAMP),
+    ('system:fees:NGN', 'Fee income', 'revenue', 'NGN', NULL, CURRENT_TIMESTAMP),
+    ('system:suspense:NGN', 'Suspense', 'liability', 'NGN', NULL, CURRENT_TIMESTAMP);
+
+-- Backfill an account per existing wallet
+INSERT OR IGNORE INTO ledger_accounts (id, name, type, currency, wallet_id, created_at)
+    SELECT 'wallet:' || id, 'Wallet ' || wallet_number, 'liability', 'NGN', id, CURRENT_TIMESTAMP
+    FROM wallets;
+
+-- Existing balances have no provable history, so open them against suspense
+INSERT INTO journal_entries (id, reference, description, created_at)
+    SELECT 'opening:' || id, 'OPENING_' || id, 'Opening balance', CURRENT_TIMESTAMP
+    FROM wallets WHERE balance != 0;
+
+INSERT INTO postings (id, entry_id, account_id, amount, created_at)
+    SELECT 'opening:' || id || ':wallet', 'opening:' || id, 'wallet:' || id, -balance, CURRENT_TIMESTAMP
+    FROM wallets WHERE balance != 0;
+
+INSERT INTO postings (id, entry_id, account_id, amount, created_at)
+    SELECT 'opening:' || id || ':suspense', 'opening:' || id, 'system:suspense:NGN', balance, CURRENT_TIMESTAMP
+    FROM wallets WHERE balance != 0;
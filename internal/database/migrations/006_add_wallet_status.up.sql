@@ -0,0 +1 @@
+ALTER TABLE wallets ADD COLUMN status TEXT NOT NULL DEFAULT 'active';
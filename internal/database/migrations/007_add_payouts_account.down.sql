@@ -0,0 +1 @@
+DELETE FROM ledger_accounts WHERE id = 'system:payouts:NGN';
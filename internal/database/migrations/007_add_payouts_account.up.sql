@@ -0,0 +1,2 @@
+INSERT OR IGNORE INTO ledger_accounts (id, name, type, currency, wallet_id, created_at) VALUES
+    ('system:payouts:NGN', 'Payouts in transit', 'liability', 'NGN', NULL, CURRENT_TIMESTAMP);
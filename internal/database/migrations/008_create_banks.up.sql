@@ -0,0 +1,11 @@
+CREATE TABLE IF NOT EXISTS banks (
+    currency TEXT NOT NULL,
+    code TEXT NOT NULL,
+    name TEXT NOT NULL,
+    slug TEXT NOT NULL,
+    country TEXT NOT NULL,
+    type TEXT NOT NULL,
+    active INTEGER NOT NULL DEFAULT 1,
+    fetched_at DATETIME NOT NULL,
+    PRIMARY KEY (currency, code)
+);
@@ -0,0 +1,17 @@
+CREATE TABLE IF NOT EXISTS beneficiaries (
+    id TEXT PRIMARY KEY,
+    wallet_id TEXT NOT NULL,
+    type TEXT NOT NULL,
+    nickname TEXT NOT NULL,
+    wallet_number TEXT NOT NULL DEFAULT '',
+    account_number TEXT NOT NULL DEFAULT '',
+    bank_code TEXT NOT NULL DEFAULT '',
+    bank_name TEXT NOT NULL DEFAULT '',
+    account_name TEXT NOT NULL DEFAULT '',
+    created_at DATETIME NOT NULL,
+    updated_at DATETIME NOT NULL,
+    FOREIGN KEY (wallet_id) REFERENCES wallets(id)
+);
+CREATE INDEX IF NOT EXISTS idx_beneficiaries_wallet_id ON beneficiaries(wallet_id);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_beneficiaries_payee
+    ON beneficiaries(wallet_id, type, wallet_number, bank_code, account_number);
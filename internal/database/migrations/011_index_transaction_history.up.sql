@@ -0,0 +1,5 @@
+CREATE INDEX IF NOT EXISTS idx_transactions_wallet_created
+    ON transactions(wallet_id, created_at DESC, id DESC);
+CREATE INDEX IF NOT EXISTS idx_transactions_wallet_counterparty
+    ON transactions(wallet_id, recipient_wallet, created_at DESC);
+DROP INDEX IF EXISTS idx_transactions_wallet_id;
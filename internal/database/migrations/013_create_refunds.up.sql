@@ -0,0 +1,12 @@
+CREATE TABLE IF NOT EXISTS refunds (
+    id TEXT PRIMARY KEY,
+    paystack_id TEXT UNIQUE NOT NULL,
+    deposit_reference TEXT NOT NULL,
+    wallet_id TEXT NOT NULL DEFAULT '',
+    amount INTEGER NOT NULL,
+    currency TEXT NOT NULL,
+    status TEXT NOT NULL,
+    created_at DATETIME NOT NULL,
+    updated_at DATETIME NOT NULL
+);
+CREATE INDEX IF NOT EXISTS idx_refunds_deposit_reference ON refunds(deposit_reference);
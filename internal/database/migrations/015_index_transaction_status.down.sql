@@ -0,0 +1 @@
+DROP INDEX IF EXISTS idx_transactions_type_status_created;
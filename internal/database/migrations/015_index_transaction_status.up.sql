@@ -0,0 +1,2 @@
+CREATE INDEX IF NOT EXISTS idx_transactions_type_status_created
+    ON transactions(type, status, created_at);
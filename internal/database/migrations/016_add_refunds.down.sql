@@ -0,0 +1,2 @@
+ALTER TABLE refunds DROP COLUMN transaction_reference;
+DELETE FROM ledger_accounts WHERE id = 'system:refunds:NGN';
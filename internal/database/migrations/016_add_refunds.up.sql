@@ -0,0 +1,6 @@
+INSERT OR IGNORE INTO ledger_accounts (id, name, type, currency, wallet_id, created_at) VALUES
+    ('system:refunds:NGN', 'Refunds in transit', 'liability', 'NGN', NULL, CURRENT_TIMESTAMP);
+
+-- Refunds started from a wallet point at the refund transaction holding the
+-- funds; refunds started from the Paystack dashboard leave it empty
+ALTER TABLE refunds ADD COLUMN transaction_reference TEXT NOT NULL DEFAULT '';
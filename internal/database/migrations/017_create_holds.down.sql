@@ -0,0 +1,3 @@
+-- Funds returned to wallets for in-flight withdrawals and refunds are not
+-- moved back to the transit accounts; reconcile after rolling back.
+DROP TABLE IF EXISTS holds;
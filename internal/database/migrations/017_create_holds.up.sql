@@ -0,0 +1,46 @@
+CREATE TABLE IF NOT EXISTS holds (
+    id TEXT PRIMARY KEY,
+    wallet_id TEXT NOT NULL,
+    amount INTEGER NOT NULL,
+    captured_amount INTEGER NOT NULL DEFAULT 0,
+    reason TEXT NOT NULL DEFAULT '',
+    reference TEXT NOT NULL DEFAULT '',
+    recipient_wallet TEXT NOT NULL DEFAULT '',
+    status TEXT NOT NULL,
+    expires_at DATETIME,
+    created_at DATETIME NOT NULL,
+    updated_at DATETIME NOT NULL,
+    FOREIGN KEY (wallet_id) REFERENCES wallets(id)
+);
+CREATE INDEX IF NOT EXISTS idx_holds_wallet_status ON holds(wallet_id, status);
+CREATE INDEX IF NOT EXISTS idx_holds_status_expires ON holds(status, expires_at);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_holds_wallet_reference ON holds(wallet_id, reference) WHERE reference != '';
+
+-- Withdrawals and refunds in flight moved their funds out of the wallet into
+-- the payouts and refunds accounts when they started. Return those funds and
+-- reserve them with a hold instead, which is captured when Paystack settles.
+INSERT INTO holds (id, wallet_id, amount, reason, reference, status, created_at, updated_at)
+    SELECT 'hold:' || id, wallet_id, -amount, type, reference, 'active', created_at, CURRENT_TIMESTAMP
+    FROM transactions WHERE type IN ('withdrawal', 'refund') AND status = 'pending';
+
+INSERT INTO journal_entries (id, reference, description, created_at)
+    SELECT 'hold:' || id, reference, 'Moved to wallet hold', CURRENT_TIMESTAMP
+    FROM transactions WHERE type IN ('withdrawal', 'refund') AND status = 'pending';
+
+INSERT INTO postings (id, entry_id, account_id, amount, created_at)
+    SELECT 'hold:' || id || ':transit', 'hold:' || id,
+        CASE type WHEN 'withdrawal' THEN 'system:payouts:NGN' ELSE 'system:refunds:NGN' END,
+        -amount, CURRENT_TIMESTAMP
+    FROM transactions WHERE type IN ('withdrawal', 'refund') AND status = 'pending';
+
+INSERT INTO postings (id, entry_id, account_id, amount, created_at)
+    SELECT 'hold:' || id || ':wallet', 'hold:' || id, 'wallet:' || wallet_id, amount, CURRENT_TIMESTAMP
+    FROM transactions WHERE type IN ('withdrawal', 'refund') AND status = 'pending';
+
+UPDATE wallets SET balance = balance + (
+    SELECT COALESCE(SUM(-amount), 0) FROM transactions
+    WHERE transactions.wallet_id = wallets.id AND type IN ('withdrawal', 'refund') AND status = 'pending'
+);
+
+UPDATE transactions SET journal_entry_id = ''
+    WHERE type IN ('withdrawal', 'refund') AND status = 'pending';
@@ -0,0 +1,4 @@
+-- Wallets in currencies other than NGN and their ledger accounts are kept;
+-- the one-wallet-per-user constraint is not restored while they exist.
+DROP INDEX IF EXISTS idx_wallets_user_currency;
+ALTER TABLE transactions DROP COLUMN currency;
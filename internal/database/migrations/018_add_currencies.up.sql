@@ -0,0 +1,38 @@
+-- Wallets are per currency now: a user holds at most one wallet in each.
+-- SQLite cannot drop the UNIQUE constraint on user_id in place, so the
+-- table is rebuilt.
+CREATE TABLE wallets_new (
+    id TEXT PRIMARY KEY,
+    user_id TEXT NOT NULL,
+    wallet_number TEXT UNIQUE NOT NULL,
+    balance INTEGER NOT NULL DEFAULT 0,
+    currency TEXT NOT NULL DEFAULT 'NGN',
+    status TEXT NOT NULL DEFAULT 'active',
+    created_at DATETIME NOT NULL,
+    updated_at DATETIME NOT NULL,
+    FOREIGN KEY (user_id) REFERENCES users(id)
+);
+
+INSERT INTO wallets_new (id, user_id, wallet_number, balance, currency, status, created_at, updated_at)
+    SELECT id, user_id, wallet_number, balance, 'NGN', status, created_at, updated_at FROM wallets;
+
+DROP TABLE wallets;
+ALTER TABLE wallets_new RENAME TO wallets;
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_wallets_user_currency ON wallets(user_id, currency);
+
+ALTER TABLE transactions ADD COLUMN currency TEXT NOT NULL DEFAULT 'NGN';
+
+INSERT OR IGNORE INTO ledger_accounts (id, name, type, currency, wallet_id, created_at) VALUES
+    ('system:paystack_settlement:GHS', 'Paystack settlement', 'asset', 'GHS', NULL, CURRENT_TIMESTAMP),
+    ('system:fees:GHS', 'Fee income', 'revenue', 'GHS', NULL, CURRENT_TIMESTAMP),
+    ('system:suspense:GHS', 'Suspense', 'liability', 'GHS', NULL, CURRENT_TIMESTAMP),
+    ('system:paystack_settlement:ZAR', 'Paystack settlement', 'asset', 'ZAR', NULL, CURRENT_TIMESTAMP),
+    ('system:fees:ZAR', 'Fee income', 'revenue', 'ZAR', NULL, CURRENT_TIMESTAMP),
+    ('system:suspense:ZAR', 'Suspense', 'liability', 'ZAR', NULL, CURRENT_TIMESTAMP),
+    ('system:paystack_settlement:KES', 'Paystack settlement', 'asset', 'KES', NULL, CURRENT_TIMESTAMP),
+    ('system:fees:KES', 'Fee income', 'revenue', 'KES', NULL, CURRENT_TIMESTAMP),
+    ('system:suspense:KES', 'Suspense', 'liability', 'KES', NULL, CURRENT_TIMESTAMP),
+    ('system:paystack_settlement:USD', 'Paystack settlement', 'asset', 'USD', NULL, CURRENT_TIMESTAMP),
+    ('system:fees:USD', 'Fee income', 'revenue', 'USD', NULL, CURRENT_TIMESTAMP),
+    ('system:suspense:USD', 'Suspense', 'liability', 'USD', NULL, CURRENT_TIMESTAMP);
@@ -0,0 +1,8 @@
+-- FX accounts that already carry postings are kept so the ledger still
+-- balances.
+DELETE FROM ledger_accounts
+    WHERE (id LIKE 'system:fx_clearing:%' OR id LIKE 'system:fx_revenue:%')
+    AND NOT EXISTS (SELECT 1 FROM postings WHERE postings.account_id = ledger_accounts.id);
+
+DROP INDEX IF EXISTS idx_fx_quotes_user_id;
+DROP TABLE IF EXISTS fx_quotes;
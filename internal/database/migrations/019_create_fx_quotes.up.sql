@@ -0,0 +1,29 @@
+CREATE TABLE IF NOT EXISTS fx_quotes (
+    id TEXT PRIMARY KEY,
+    user_id TEXT NOT NULL,
+    source_currency TEXT NOT NULL,
+    source_amount INTEGER NOT NULL,
+    spread_amount INTEGER NOT NULL,
+    target_currency TEXT NOT NULL,
+    target_amount INTEGER NOT NULL,
+    rate REAL NOT NULL,
+    status TEXT NOT NULL,
+    expires_at DATETIME NOT NULL,
+    created_at DATETIME NOT NULL,
+    FOREIGN KEY (user_id) REFERENCES users(id)
+);
+CREATE INDEX IF NOT EXISTS idx_fx_quotes_user_id ON fx_quotes(user_id);
+
+-- Conversions sell one currency and buy another through the clearing
+-- account of each, with the spread credited to revenue in the source currency
+INSERT OR IGNORE INTO ledger_accounts (id, name, type, currency, wallet_id, created_at) VALUES
+    ('system:fx_clearing:NGN', 'FX clearing', 'liability', 'NGN', NULL, CURRENT_TIMESTAMP),
+    ('system:fx_revenue:NGN', 'FX spread income', 'revenue', 'NGN', NULL, CURRENT_TIMESTAMP),
+    ('system:fx_clearing:GHS', 'FX clearing', 'liability', 'GHS', NULL, CURRENT_TIMESTAMP),
+    ('system:fx_revenue:GHS', 'FX spread income', 'revenue', 'GHS', NULL, CURRENT_TIMESTAMP),
+    ('system:fx_clearing:ZAR', 'FX clearing', 'liability', 'ZAR', NULL, CURRENT_TIMESTAMP),
+    ('system:fx_revenue:ZAR', 'FX spread income', 'revenue', 'ZAR', NULL, CURRENT_TIMESTAMP),
+    ('system:fx_clearing:KES', 'FX clearing', 'liability', 'KES', NULL, CURRENT_TIMESTAMP),
+    ('system:fx_revenue:KES', 'FX spread income', 'revenue', 'KES', NULL, CURRENT_TIMESTAMP),
+    ('system:fx_clearing:USD', 'FX clearing', 'liability', 'USD', NULL, CURRENT_TIMESTAMP),
+    ('system:fx_revenue:USD', 'FX spread income', 'revenue', 'USD', NULL, CURRENT_TIMESTAMP);
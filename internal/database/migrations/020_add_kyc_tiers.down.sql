@@ -0,0 +1 @@
+ALTER TABLE users DROP COLUMN kyc_tier;
@@ -0,0 +1,2 @@
+-- KYC tier of the user; new and existing users start unverified
+ALTER TABLE users ADD COLUMN kyc_tier INTEGER NOT NULL DEFAULT 0;
@@ -0,0 +1,5 @@
+-- key_prefix is the non-secret start of the key, shown so users can tell
+-- their keys apart; keys created before it existed have none.
+ALTER TABLE api_keys ADD COLUMN key_prefix TEXT NOT NULL DEFAULT '';
+ALTER TABLE api_keys ADD COLUMN last_used_at DATETIME;
+ALTER TABLE api_keys ADD COLUMN last_used_ip TEXT NOT NULL DEFAULT '';
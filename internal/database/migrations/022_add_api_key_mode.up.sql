@@ -0,0 +1,2 @@
+-- test keys operate on the sandbox database; every existing key is live
+ALTER TABLE api_keys ADD COLUMN mode TEXT NOT NULL DEFAULT 'live';
@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS idx_api_key_audit_key;
+DROP TABLE IF EXISTS api_key_audit;
+ALTER TABLE api_keys DROP COLUMN allowed_ips;
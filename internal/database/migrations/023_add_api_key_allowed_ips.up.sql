@@ -0,0 +1,17 @@
+-- allowed_ips is a JSON array of CIDR ranges; an empty array allows any IP
+ALTER TABLE api_keys ADD COLUMN allowed_ips TEXT NOT NULL DEFAULT '[]';
+
+-- api_key_audit records requests a key was presented with but refused, e.g.
+-- from an IP outside its allowlist
+CREATE TABLE IF NOT EXISTS api_key_audit (
+    id TEXT PRIMARY KEY,
+    api_key_id TEXT NOT NULL,
+    user_id TEXT NOT NULL,
+    event TEXT NOT NULL,
+    ip TEXT NOT NULL,
+    detail TEXT NOT NULL DEFAULT '',
+    created_at DATETIME NOT NULL,
+    FOREIGN KEY (api_key_id) REFERENCES api_keys(id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_api_key_audit_key ON api_key_audit(api_key_id, created_at);
@@ -1,12 +1,15 @@
 package database
 
 import (
+	"context"
 	"database/sql"
 	"embed"
 	"fmt"
 	"os"
 	"path/filepath"
 	"sort"
+	"strings"
+	"time"
 
 	_ "github.com/mattn/go-sqlite3"
 )
@@ -18,7 +21,11 @@ func NewSQLite(dbPath string) (*sql.DB, error) {
 		return nil, fmt.Errorf("failed to create database directory: %w", err)
 	}
 
-	db, err := sql.Open("sqlite3", dbPath)
+	// Writers take the lock when the transaction begins rather than on their
+	// first write, so concurrent balance updates queue instead of deadlocking
+	dsn := fmt.Sprintf("%s?_busy_timeout=5000&_txlock=immediate", dbPath)
+
+	db, err := sql.Open("sqlite3", dsn)
 	if err != nil {
 		return nil, fmt.Errorf("failed to open database: %w", err)
 	}
@@ -49,6 +56,30 @@ func RunMigrations(db *sql.DB, migrationsDir string) error {
 		return fmt.Errorf("failed to read embedded migrations: %w", err)
 	}
 
+	// Run every migration on one connection with foreign key enforcement
+	// off, so migrations can rebuild tables other tables reference. The
+	// pragma is ignored inside a transaction and only affects this
+	// connection.
+	ctx := context.Background()
+	conn, err := db.Conn(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to get migration connection: %w", err)
+	}
+	defer conn.Close()
+
+	if _, err := conn.ExecContext(ctx, "PRAGMA foreign_keys=OFF"); err != nil {
+		return fmt.Errorf("failed to disable foreign keys: %w", err)
+	}
+	defer conn.ExecContext(ctx, "PRAGMA foreign_keys=ON")
+
+	// Track applied migrations so each one runs exactly once
+	if _, err := conn.ExecContext(ctx, `CREATE TABLE IF NOT EXISTS schema_migrations (
+		name TEXT PRIMARY KEY,
+		applied_at DATETIME NOT NULL
+	)`); err != nil {
+		return fmt.Errorf("failed to create schema_migrations table: %w", err)
+	}
+
 	// Sort files to run them in order
 	sort.Slice(entries, func(i, j int) bool {
 		return entries[i].Name() < entries[j].Name()
@@ -60,7 +91,15 @@ func RunMigrations(db *sql.DB, migrationsDir string) error {
 		}
 
 		// Only run .up.sql files (skip .down.sql files)
-		if filepath.Ext(entry.Name()) != ".sql" {
+		if !strings.HasSuffix(entry.Name(), ".up.sql") {
+			continue
+		}
+
+		var applied int
+		if err := conn.QueryRowContext(ctx, `SELECT COUNT(*) FROM schema_migrations WHERE name = ?`, entry.Name()).Scan(&applied); err != nil {
+			return fmt.Errorf("failed to check migration %s: %w", entry.Name(), err)
+		}
+		if applied > 0 {
 			continue
 		}
 
@@ -70,9 +109,24 @@ func RunMigrations(db *sql.DB, migrationsDir string) error {
 			return fmt.Errorf("failed to read migration file %s: %w", entry.Name(), err)
 		}
 
-		if _, err := db.Exec(string(content)); err != nil {
+		tx, err := conn.BeginTx(ctx, nil)
+		if err != nil {
+			return fmt.Errorf("failed to begin migration %s: %w", entry.Name(), err)
+		}
+
+		if _, err := tx.Exec(string(content)); err != nil {
+			tx.Rollback()
 			return fmt.Errorf("failed to execute migration %s: %w", entry.Name(), err)
 		}
+
+		if _, err := tx.Exec(`INSERT INTO schema_migrations (name, applied_at) VALUES (?, ?)`, entry.Name(), time.Now()); err != nil {
+			tx.Rollback()
+			return fmt.Errorf("failed to record migration %s: %w", entry.Name(), err)
+		}
+
+		if err := tx.Commit(); err != nil {
+			return fmt.Errorf("failed to commit migration %s: %w", entry.Name(), err)
+		}
 	}
 
 	return nil
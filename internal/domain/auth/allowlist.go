@@ -0,0 +1,132 @@
+package auth
+
+import (
+	"errors"
+	"fmt"
+	"net/netip"
+	"strings"
+	"time"
+)
+
+var (
+	ErrInvalidAllowedIPs = errors.New("invalid allowed_ips")
+	ErrIPNotAllowed      = errors.New("api key cannot be used from this IP")
+	// ErrAllowedIPsExceedKey is returned when a key sets an allowlist
+	// wider than its own on another key
+	ErrAllowedIPsExceedKey = errors.New("allowed_ips must be within the managing key's own allowlist")
+)
+
+// maxAllowedIPs keeps allowlists short enough to check on every request
+const maxAllowedIPs = 20
+
+// AuditEventIPRejected is recorded when a key is used from outside its
+// allowlist
+const AuditEventIPRejected = "ip_rejected"
+
+// AuditEvent is a request a key was presented with but refused
+type AuditEvent struct {
+	ID        string    `json:"id"`
+	APIKeyID  string    `json:"api_key_id"`
+	UserID    string    `json:"user_id"`
+	Event     string    `json:"event"`
+	IP        string    `json:"ip"`
+	Detail    string    `json:"detail,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+type AuditRepository interface {
+	Create(event *AuditEvent) error
+	// ListByAPIKeyID returns the key's latest events, newest first
+	ListByAPIKeyID(apiKeyID string, limit int) ([]*AuditEvent, error)
+}
+
+// ParseAllowedIPs normalises an allowlist of CIDR ranges. A bare address is
+// taken as a range holding only itself, and IPv4 addresses mapped into IPv6
+// are stored as IPv4, which is how clients are matched.
+func ParseAllowedIPs(entries []string) ([]string, error) {
+	if len(entries) > maxAllowedIPs {
+		return nil, fmt.Errorf("%w: at most %d ranges are allowed", ErrInvalidAllowedIPs, maxAllowedIPs)
+	}
+
+	ranges := make([]string, 0, len(entries))
+	for _, entry := range entries {
+		prefix, err := parseRange(strings.TrimSpace(entry))
+		if err != nil {
+			return nil, fmt.Errorf("%w: %q is not an IP address or CIDR range", ErrInvalidAllowedIPs, entry)
+		}
+		ranges = append(ranges, prefix.String())
+	}
+	return ranges, nil
+}
+
+func parseRange(entry string) (netip.Prefix, error) {
+	if !strings.Contains(entry, "/") {
+		addr, err := netip.ParseAddr(entry)
+		if err != nil {
+			return netip.Prefix{}, err
+		}
+		addr = addr.Unmap()
+		return netip.PrefixFrom(addr, addr.BitLen()), nil
+	}
+
+	prefix, err := netip.ParsePrefix(entry)
+	if err != nil {
+		return netip.Prefix{}, err
+	}
+	if prefix.Addr().Is4In6() && prefix.Bits() >= 96 {
+		prefix = netip.PrefixFrom(prefix.Addr().Unmap(), prefix.Bits()-96)
+	}
+	return prefix.Masked(), nil
+}
+
+// AllowsIP reports whether the key may be used from ip. Keys without an
+// allowlist may be used from anywhere.
+func (k *APIKey) AllowsIP(ip string) bool {
+	if len(k.AllowedIPs) == 0 {
+		return true
+	}
+
+	addr, err := netip.ParseAddr(ip)
+	if err != nil {
+		return false
+	}
+	addr = addr.Unmap()
+	for _, entry := range k.AllowedIPs {
+		if prefix, err := parseRange(entry); err == nil && prefix.Contains(addr) {
+			return true
+		}
+	}
+	return false
+}
+
+// DelegatedIPs returns the allowlist the key k may give another key that
+// asked for entries. Keys with an allowlist can only hand out ranges inside
+// it, and an empty request inherits it rather than allowing any IP.
+func (k *APIKey) DelegatedIPs(entries []string) ([]string, error) {
+	ranges, err := ParseAllowedIPs(entries)
+	if err != nil || len(k.AllowedIPs) == 0 {
+		return ranges, err
+	}
+	if len(ranges) == 0 {
+		return k.AllowedIPs, nil
+	}
+
+	for _, entry := range ranges {
+		prefix, _ := parseRange(entry)
+		if !k.allowsRange(prefix) {
+			return nil, fmt.Errorf("%w: %s", ErrAllowedIPsExceedKey, entry)
+		}
+	}
+	return ranges, nil
+}
+
+// allowsRange reports whether every address in prefix is on k's allowlist
+func (k *APIKey) allowsRange(prefix netip.Prefix) bool {
+	for _, entry := range k.AllowedIPs {
+		own, err := parseRange(entry)
+		if err == nil && own.Bits() <= prefix.Bits() && own.Contains(prefix.Addr()) {
+			return true
+		}
+	}
+	return false
+}
@@ -3,15 +3,48 @@ package auth
 import "time"
 
 type APIKey struct {
-	ID          string       `json:"id"`
-	UserID      string       `json:"user_id"`
-	Name        string       `json:"name"`
-	KeyHash     string       `json:"-"`
+	ID      string `json:"id"`
+	UserID  string `json:"user_id"`
+	Name    string `json:"name"`
+	KeyHash string `json:"-"`
+	// KeyPrefix is the start of the raw key, enough to recognise it but not
+	// to use it
+	KeyPrefix string `json:"key_prefix,omitempty"`
+	// Mode is test for sk_test_ keys, which only reach sandbox wallets
+	Mode        KeyMode      `json:"mode"`
 	Permissions []Permission `json:"permissions"`
-	ExpiresAt   time.Time    `json:"expires_at"`
-	IsRevoked   bool         `json:"is_revoked"`
-	CreatedAt   time.Time    `json:"created_at"`
-	UpdatedAt   time.Time    `json:"updated_at"`
+	// AllowedIPs are the CIDR ranges the key may be used from; any IP
+	// when empty
+	AllowedIPs []string  `json:"allowed_ips"`
+	ExpiresAt  time.Time `json:"expires_at"`
+	IsRevoked  bool      `json:"is_revoked"`
+	// LastUsedAt and LastUsedIP record the latest request the key
+	// authenticated, to within a minute
+	LastUsedAt *time.Time `json:"last_used_at"`
+	LastUsedIP string     `json:"last_used_ip,omitempty"`
+	CreatedAt  time.Time  `json:"created_at"`
+	UpdatedAt  time.Time  `json:"updated_at"`
+}
+
+// KeyStatus summarises whether a key can still be used
+type KeyStatus string
+
+const (
+	KeyStatusActive  KeyStatus = "active"
+	KeyStatusExpired KeyStatus = "expired"
+	KeyStatusRevoked KeyStatus = "revoked"
+)
+
+// KeyMode decides whether a key moves real money or sandbox money
+type KeyMode string
+
+const (
+	KeyModeLive KeyMode = "live"
+	KeyModeTest KeyMode = "test"
+)
+
+func (m KeyMode) Valid() bool {
+	return m == KeyModeLive || m == KeyModeTest
 }
 
 type Permission string
@@ -19,13 +52,25 @@ type Permission string
 const (
 	PermissionDeposit  Permission = "deposit"
 	PermissionTransfer Permission = "transfer"
-	PermissionRead     Permission = "read"
+	// PermissionRead covers everything read-only, including balances and
+	// transactions
+	PermissionRead             Permission = "read"
+	PermissionBalanceRead      Permission = "balance:read"
+	PermissionTransactionsRead Permission = "transactions:read"
+	PermissionWithdraw         Permission = "withdraw"
+	// PermissionKeysManage lets a key list, create, roll over and revoke
+	// its owner's keys
+	PermissionKeysManage Permission = "keys:manage"
 )
 
 var ValidPermissions = map[Permission]bool{
-	PermissionDeposit:  true,
-	PermissionTransfer: true,
-	PermissionRead:     true,
+	PermissionDeposit:          true,
+	PermissionTransfer:         true,
+	PermissionRead:             true,
+	PermissionBalanceRead:      true,
+	PermissionTransactionsRead: true,
+	PermissionWithdraw:         true,
+	PermissionKeysManage:       true,
 }
 
 type ExpiryDuration string
@@ -0,0 +1,278 @@
+package auth
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/BerylCAtieno/paystack-wallet/internal/money"
+)
+
+var (
+	// ErrPermissionDenied matches every error Authorize returns with
+	// errors.Is
+	ErrPermissionDenied = errors.New("insufficient permissions")
+	// ErrGrantExceedsKey matches every error CanGrant returns with
+	// errors.Is
+	ErrGrantExceedsKey = errors.New("api keys can only grant what they hold themselves")
+)
+
+// Constraints a grant can narrow its permission with
+const (
+	ConstraintMaxAmount  = "max_amount"
+	ConstraintCurrency   = "currency"
+	ConstraintRecipients = "recipients"
+)
+
+// allowedConstraints lists the constraints each money-moving permission
+// accepts. Other permissions cannot be constrained.
+var allowedConstraints = map[Permission][]string{
+	PermissionDeposit:  {ConstraintMaxAmount, ConstraintCurrency},
+	PermissionTransfer: {ConstraintMaxAmount, ConstraintCurrency, ConstraintRecipients},
+	PermissionWithdraw: {ConstraintMaxAmount, ConstraintCurrency, ConstraintRecipients},
+}
+
+// impliedBy maps narrow permissions to the broader one that includes them,
+// so keys granted read before the narrow ones existed keep working
+var impliedBy = map[Permission]Permission{
+	PermissionBalanceRead:      PermissionRead,
+	PermissionTransactionsRead: PermissionRead,
+}
+
+// Grant is one entry of a key's permissions: a permission, optionally
+// narrowed by a constraint, as in "transfer:max_amount=50000" or
+// "withdraw:recipients=[0123456789]".
+type Grant struct {
+	Permission Permission
+	// MaxAmount is the most a single request may move, in the minor unit
+	// of the wallet's currency; 0 is no limit
+	MaxAmount int64
+	// Currency restricts the grant to wallets in one currency
+	Currency money.Currency
+	// Recipients are the wallet numbers or bank account numbers money may
+	// be sent to
+	Recipients []string
+}
+
+// ParseGrant reads a permission with an optional constraint
+func ParseGrant(p Permission) (Grant, error) {
+	name, constraint := string(p), ""
+	if i := strings.Index(name, ":"); i >= 0 && strings.Contains(name[i:], "=") {
+		name, constraint = name[:i], name[i+1:]
+	}
+
+	g := Grant{Permission: Permission(name)}
+	if !ValidPermissions[g.Permission] {
+		return Grant{}, fmt.Errorf("%w: unknown permission %q", ErrInvalidPermissions, name)
+	}
+	if constraint == "" {
+		return g, nil
+	}
+
+	key, value, _ := strings.Cut(constraint, "=")
+	if !constraintAllowed(g.Permission, key) {
+		return Grant{}, fmt.Errorf("%w: %s cannot be constrained by %q", ErrInvalidPermissions, name, key)
+	}
+
+	switch key {
+	case ConstraintMaxAmount:
+		amount, err := strconv.ParseInt(value, 10, 64)
+		if err != nil || amount <= 0 {
+			return Grant{}, fmt.Errorf("%w: max_amount must be a positive integer", ErrInvalidPermissions)
+		}
+		g.MaxAmount = amount
+	case ConstraintCurrency:
+		currency, err := money.ParseCurrency(value)
+		if err != nil {
+			return Grant{}, fmt.Errorf("%w: %v", ErrInvalidPermissions, err)
+		}
+		g.Currency = currency
+	case ConstraintRecipients:
+		list := strings.TrimSuffix(strings.TrimPrefix(value, "["), "]")
+		for _, recipient := range strings.Split(list, ",") {
+			if recipient = strings.TrimSpace(recipient); recipient != "" {
+				g.Recipients = append(g.Recipients, recipient)
+			}
+		}
+		if len(g.Recipients) == 0 {
+			return Grant{}, fmt.Errorf("%w: recipients must list at least one recipient", ErrInvalidPermissions)
+		}
+	}
+	return g, nil
+}
+
+func constraintAllowed(p Permission, constraint string) bool {
+	for _, c := range allowedConstraints[p] {
+		if c == constraint {
+			return true
+		}
+	}
+	return false
+}
+
+// Action is what a request wants to do, checked against a key's grants.
+// Amount and Recipient are left empty when the request has not been read
+// yet or does not move money anywhere.
+type Action struct {
+	Permission Permission
+	Amount     money.Money
+	// Recipient is the wallet number or bank account number paid
+	Recipient string
+}
+
+// Authorize checks an action against the key's grants. The key needs a
+// grant of the action's permission, or of a broader one that implies it,
+// and every constraint on the permission's grants applies. It returns an
+// error matching ErrPermissionDenied that names the broken constraint.
+func (k *APIKey) Authorize(a Action) error {
+	grants := grantsFor(k.Permissions, a.Permission)
+	if len(grants) == 0 {
+		return ErrPermissionDenied
+	}
+
+	for _, g := range grants {
+		if err := g.check(a); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (g Grant) check(a Action) error {
+	if a.Amount.Currency != "" {
+		if g.Currency != "" && a.Amount.Currency != g.Currency {
+			return fmt.Errorf("%w: this key can only %s in %s", ErrPermissionDenied, g.Permission, g.Currency)
+		}
+		if g.MaxAmount > 0 && a.Amount.Amount > g.MaxAmount {
+			return fmt.Errorf("%w: this key can %s at most %d at a time", ErrPermissionDenied, g.Permission, g.MaxAmount)
+		}
+	}
+
+	if a.Recipient != "" && len(g.Recipients) > 0 {
+		for _, r := range g.Recipients {
+			if r == a.Recipient {
+				return nil
+			}
+		}
+		return fmt.Errorf("%w: this key cannot pay %s", ErrPermissionDenied, a.Recipient)
+	}
+	return nil
+}
+
+// grantsFor returns the grants among permissions that apply to p, directly
+// or through a broader permission
+func grantsFor(permissions []Permission, p Permission) []Grant {
+	var grants []Grant
+	for _, perm := range permissions {
+		g, err := ParseGrant(perm)
+		if err != nil {
+			continue
+		}
+		if g.Permission == p || g.Permission == impliedBy[p] {
+			grants = append(grants, g)
+		}
+	}
+	return grants
+}
+
+// CanGrant checks that a key given permissions could do nothing the key k
+// cannot, so a key holding keys:manage cannot mint itself a broader one.
+// Every permission granted must be held by k, with each of k's constraints
+// on it repeated at least as strictly, and keys:manage is never granted.
+func (k *APIKey) CanGrant(permissions []Permission) error {
+	for _, p := range permissions {
+		if _, err := ParseGrant(p); err != nil {
+			return err
+		}
+		if p == PermissionKeysManage {
+			return fmt.Errorf("%w: keys:manage can only be granted by a signed in user", ErrGrantExceedsKey)
+		}
+	}
+
+	for p := range ValidPermissions {
+		granted := grantsFor(permissions, p)
+		if len(granted) == 0 {
+			continue
+		}
+		held := grantsFor(k.Permissions, p)
+		if len(held) == 0 {
+			return fmt.Errorf("%w: this key does not hold %s", ErrGrantExceedsKey, p)
+		}
+		if err := newScope(granted).within(newScope(held)); err != nil {
+			return fmt.Errorf("%w: %s %v", ErrGrantExceedsKey, p, err)
+		}
+	}
+	return nil
+}
+
+// scope is what a set of grants for one permission allows together, since
+// every one of them applies to each request
+type scope struct {
+	maxAmount  int64
+	currencies []money.Currency
+	// recipients is nil when any recipient may be paid
+	recipients []string
+}
+
+func newScope(grants []Grant) scope {
+	var s scope
+	for _, g := range grants {
+		if g.MaxAmount > 0 && (s.maxAmount == 0 || g.MaxAmount < s.maxAmount) {
+			s.maxAmount = g.MaxAmount
+		}
+		if g.Currency != "" {
+			s.currencies = append(s.currencies, g.Currency)
+		}
+		if len(g.Recipients) > 0 {
+			if s.recipients == nil {
+				s.recipients = g.Recipients
+			} else {
+				s.recipients = intersect(s.recipients, g.Recipients)
+			}
+		}
+	}
+	return s
+}
+
+// within returns an error naming the first thing s allows that o does not
+func (s scope) within(o scope) error {
+	if o.maxAmount > 0 && (s.maxAmount == 0 || s.maxAmount > o.maxAmount) {
+		return fmt.Errorf("must have max_amount of at most %d", o.maxAmount)
+	}
+	for _, c := range o.currencies {
+		if !contains(s.currencies, c) {
+			return fmt.Errorf("must be limited to currency %s", c)
+		}
+	}
+	if o.recipients != nil {
+		if s.recipients == nil {
+			return errors.New("must be limited to this key's recipients")
+		}
+		for _, r := range s.recipients {
+			if !contains(o.recipients, r) {
+				return fmt.Errorf("cannot pay %s", r)
+			}
+		}
+	}
+	return nil
+}
+
+func intersect(a, b []string) []string {
+	both := []string{}
+	for _, v := range a {
+		if contains(b, v) {
+			both = append(both, v)
+		}
+	}
+	return both
+}
+
+func contains[T comparable](list []T, v T) bool {
+	for _, item := range list {
+		if item == v {
+			return true
+		}
+	}
+	return false
+}
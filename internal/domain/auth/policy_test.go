@@ -0,0 +1,128 @@
+package auth
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/BerylCAtieno/paystack-wallet/internal/money"
+)
+
+func TestParseGrant(t *testing.T) {
+	tests := []struct {
+		name    string
+		p       Permission
+		want    Grant
+		wantErr error
+	}{
+		{"plain", "transfer", Grant{Permission: PermissionTransfer}, nil},
+		{"namespaced", "balance:read", Grant{Permission: PermissionBalanceRead}, nil},
+		{"keys:manage", "keys:manage", Grant{Permission: PermissionKeysManage}, nil},
+		{"max amount", "transfer:max_amount=50000", Grant{Permission: PermissionTransfer, MaxAmount: 50000}, nil},
+		{"currency", "deposit:currency=NGN", Grant{Permission: PermissionDeposit, Currency: money.NGN}, nil},
+		{"recipients", "withdraw:recipients=[0123456789, 9876543210]", Grant{Permission: PermissionWithdraw, Recipients: []string{"0123456789", "9876543210"}}, nil},
+		{"unknown permission", "launch", Grant{}, ErrInvalidPermissions},
+		{"unknown constrained permission", "launch:max_amount=1", Grant{}, ErrInvalidPermissions},
+		{"unknown constraint", "transfer:fee=1", Grant{}, ErrInvalidPermissions},
+		{"constraint not allowed", "read:max_amount=1", Grant{}, ErrInvalidPermissions},
+		{"recipients on deposit", "deposit:recipients=[1]", Grant{}, ErrInvalidPermissions},
+		{"max amount not a number", "transfer:max_amount=lots", Grant{}, ErrInvalidPermissions},
+		{"zero max amount", "transfer:max_amount=0", Grant{}, ErrInvalidPermissions},
+		{"negative max amount", "transfer:max_amount=-5", Grant{}, ErrInvalidPermissions},
+		{"unsupported currency", "transfer:currency=XYZ", Grant{}, ErrInvalidPermissions},
+		{"empty recipients", "transfer:recipients=[]", Grant{}, ErrInvalidPermissions},
+		{"blank recipients", "transfer:recipients=[ , ]", Grant{}, ErrInvalidPermissions},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseGrant(tt.p)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("ParseGrant(%q) error = %v, want %v", tt.p, err, tt.wantErr)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ParseGrant(%q) = %+v, want %+v", tt.p, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestAuthorize(t *testing.T) {
+	ngn := func(amount int64) money.Money { return money.New(amount, money.NGN) }
+
+	tests := []struct {
+		name        string
+		permissions []Permission
+		action      Action
+		wantErr     error
+	}{
+		{"held", []Permission{"transfer"}, Action{Permission: PermissionTransfer, Amount: ngn(1_000_000)}, nil},
+		{"not held", []Permission{"deposit"}, Action{Permission: PermissionTransfer}, ErrPermissionDenied},
+		{"no permissions", nil, Action{Permission: PermissionRead}, ErrPermissionDenied},
+		{"read implies balance:read", []Permission{"read"}, Action{Permission: PermissionBalanceRead}, nil},
+		{"read implies transactions:read", []Permission{"read"}, Action{Permission: PermissionTransactionsRead}, nil},
+		{"balance:read does not imply read", []Permission{"balance:read"}, Action{Permission: PermissionRead}, ErrPermissionDenied},
+		{"balance:read does not imply transactions:read", []Permission{"balance:read"}, Action{Permission: PermissionTransactionsRead}, ErrPermissionDenied},
+		{"read does not imply transfer", []Permission{"read"}, Action{Permission: PermissionTransfer}, ErrPermissionDenied},
+		{"at max amount", []Permission{"transfer:max_amount=500"}, Action{Permission: PermissionTransfer, Amount: ngn(500)}, nil},
+		{"over max amount", []Permission{"transfer:max_amount=500"}, Action{Permission: PermissionTransfer, Amount: ngn(501)}, ErrPermissionDenied},
+		{"amount not read yet", []Permission{"transfer:max_amount=500"}, Action{Permission: PermissionTransfer}, nil},
+		{"matching currency", []Permission{"deposit:currency=NGN"}, Action{Permission: PermissionDeposit, Amount: ngn(100)}, nil},
+		{"other currency", []Permission{"deposit:currency=NGN"}, Action{Permission: PermissionDeposit, Amount: money.New(100, money.USD)}, ErrPermissionDenied},
+		{"listed recipient", []Permission{"withdraw:recipients=[0123456789]"}, Action{Permission: PermissionWithdraw, Recipient: "0123456789"}, nil},
+		{"unlisted recipient", []Permission{"withdraw:recipients=[0123456789]"}, Action{Permission: PermissionWithdraw, Recipient: "9876543210"}, ErrPermissionDenied},
+		{"recipient not read yet", []Permission{"withdraw:recipients=[0123456789]"}, Action{Permission: PermissionWithdraw}, nil},
+		{"every grant applies", []Permission{"transfer:max_amount=500", "transfer:currency=USD"}, Action{Permission: PermissionTransfer, Amount: ngn(100)}, ErrPermissionDenied},
+		{"every grant passes", []Permission{"transfer:max_amount=500", "transfer:currency=NGN"}, Action{Permission: PermissionTransfer, Amount: ngn(100)}, nil},
+		{"invalid grants are ignored", []Permission{"transfer:max_amount=0", "transfer"}, Action{Permission: PermissionTransfer, Amount: ngn(1_000_000)}, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			key := &APIKey{Permissions: tt.permissions}
+			if err := key.Authorize(tt.action); !errors.Is(err, tt.wantErr) {
+				t.Errorf("Authorize(%+v) error = %v, want %v", tt.action, err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestCanGrant(t *testing.T) {
+	tests := []struct {
+		name    string
+		held    []Permission
+		granted []Permission
+		wantErr error
+	}{
+		{"same permissions", []Permission{"keys:manage", "transfer", "read"}, []Permission{"transfer", "read"}, nil},
+		{"nothing", []Permission{"keys:manage"}, nil, nil},
+		{"not held", []Permission{"keys:manage", "read"}, []Permission{"withdraw"}, ErrGrantExceedsKey},
+		{"keys:manage", []Permission{"keys:manage"}, []Permission{"keys:manage"}, ErrGrantExceedsKey},
+		{"invalid permission", []Permission{"keys:manage"}, []Permission{"launch"}, ErrInvalidPermissions},
+		{"narrow read from read", []Permission{"read"}, []Permission{"balance:read"}, nil},
+		{"read from narrow read", []Permission{"balance:read"}, []Permission{"read"}, ErrGrantExceedsKey},
+		{"lower max amount", []Permission{"transfer:max_amount=50000"}, []Permission{"transfer:max_amount=20000"}, nil},
+		{"same max amount", []Permission{"transfer:max_amount=50000"}, []Permission{"transfer:max_amount=50000"}, nil},
+		{"higher max amount", []Permission{"transfer:max_amount=50000"}, []Permission{"transfer:max_amount=50001"}, ErrGrantExceedsKey},
+		{"unconstrained from max amount", []Permission{"transfer:max_amount=50000"}, []Permission{"transfer"}, ErrGrantExceedsKey},
+		{"lowest of several max amounts", []Permission{"transfer:max_amount=50000"}, []Permission{"transfer", "transfer:max_amount=100"}, nil},
+		{"constrained from unconstrained", []Permission{"transfer"}, []Permission{"transfer:max_amount=100", "transfer:currency=NGN"}, nil},
+		{"same currency", []Permission{"deposit:currency=NGN"}, []Permission{"deposit:currency=NGN"}, nil},
+		{"other currency", []Permission{"deposit:currency=NGN"}, []Permission{"deposit:currency=USD"}, ErrGrantExceedsKey},
+		{"unconstrained from currency", []Permission{"deposit:currency=NGN"}, []Permission{"deposit"}, ErrGrantExceedsKey},
+		{"subset of recipients", []Permission{"withdraw:recipients=[1,2]"}, []Permission{"withdraw:recipients=[2]"}, nil},
+		{"other recipients", []Permission{"withdraw:recipients=[1,2]"}, []Permission{"withdraw:recipients=[2,3]"}, ErrGrantExceedsKey},
+		{"unconstrained from recipients", []Permission{"withdraw:recipients=[1,2]"}, []Permission{"withdraw"}, ErrGrantExceedsKey},
+		{"intersected recipients", []Permission{"withdraw:recipients=[1,2]"}, []Permission{"withdraw:recipients=[1,3]", "withdraw:recipients=[1]"}, nil},
+		{"every held constraint", []Permission{"transfer:max_amount=500", "transfer:currency=NGN"}, []Permission{"transfer:max_amount=500"}, ErrGrantExceedsKey},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			key := &APIKey{Permissions: tt.held}
+			if err := key.CanGrant(tt.granted); !errors.Is(err, tt.wantErr) {
+				t.Errorf("CanGrant(%v) with %v error = %v, want %v", tt.granted, tt.held, err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -2,6 +2,7 @@ package auth
 
 import (
 	"errors"
+	"log"
 	"time"
 
 	"github.com/BerylCAtieno/paystack-wallet/internal/security"
@@ -13,14 +14,39 @@ var (
 	ErrKeyNotExpired      = errors.New("key is not expired")
 	ErrKeyNotFound        = errors.New("api key not found")
 	ErrInvalidExpiry      = errors.New("invalid expiry duration")
+	ErrInvalidKeyMode     = errors.New("invalid key mode, use live or test")
 )
 
+// keyPrefixLength covers "sk_live_" or "sk_test_" and four hex characters of the key
+const keyPrefixLength = 12
+
+// lastUsedResolution is how stale a key's last use may be before a request
+// records it again, so busy keys are not written on every request
+const lastUsedResolution = time.Minute
+
+// auditPageSize is how many audit events ListAuditEvents returns
+const auditPageSize = 100
+
 type Service struct {
-	repo APIKeyRepository
+	repo  APIKeyRepository
+	audit AuditRepository
+	// signingSecret is the server secret each key's signing secret is
+	// derived from; it is never stored with the keys
+	signingSecret []byte
+	// maxSkew is how far a signed request's timestamp may be from the
+	// server's clock
+	maxSkew time.Duration
+	nonces  *nonceCache
 }
 
-func NewService(repo APIKeyRepository) *Service {
-	return &Service{repo: repo}
+func NewService(repo APIKeyRepository, audit AuditRepository, signingSecret string, maxSkew time.Duration) *Service {
+	return &Service{
+		repo:          repo,
+		audit:         audit,
+		signingSecret: []byte(signingSecret),
+		maxSkew:       maxSkew,
+		nonces:        newNonceCache(),
+	}
 }
 
 type APIKeyRepository interface {
@@ -29,17 +55,35 @@ type APIKeyRepository interface {
 	GetByKeyHash(hash string) (*APIKey, error)
 	CountActiveByUserID(userID string) (int, error)
 	Update(key *APIKey) error
+	UpdateAllowedIPs(key *APIKey) error
 	ListByUserID(userID string) ([]*APIKey, error)
+	// RecordUse sets the key's last use unless it was already recorded from
+	// ip after staleBefore
+	RecordUse(id string, at time.Time, ip string, staleBefore time.Time) error
 }
 
-func (s *Service) CreateAPIKey(userID, name string, permissions []Permission, expiry ExpiryDuration) (*APIKey, string, error) {
-	// Validate permissions
+// CreateAPIKey issues a key for mode, live when mode is empty, usable from
+// the allowedIPs ranges or from anywhere when there are none
+func (s *Service) CreateAPIKey(userID, name string, mode KeyMode, permissions []Permission, allowedIPs []string, expiry ExpiryDuration) (*APIKey, string, error) {
+	if mode == "" {
+		mode = KeyModeLive
+	}
+	if !mode.Valid() {
+		return nil, "", ErrInvalidKeyMode
+	}
+
+	// Validate permissions and their constraints
 	for _, perm := range permissions {
-		if !ValidPermissions[perm] {
-			return nil, "", ErrInvalidPermissions
+		if _, err := ParseGrant(perm); err != nil {
+			return nil, "", err
 		}
 	}
 
+	allowedIPs, err := ParseAllowedIPs(allowedIPs)
+	if err != nil {
+		return nil, "", err
+	}
+
 	// Check active keys count
 	count, err := s.repo.CountActiveByUserID(userID)
 	if err != nil {
@@ -50,7 +94,7 @@ func (s *Service) CreateAPIKey(userID, name string, permissions []Permission, ex
 	}
 
 	// Generate API key
-	rawKey := security.GenerateAPIKey()
+	rawKey := security.GenerateAPIKey(string(mode))
 	keyHash := security.HashAPIKey(rawKey)
 
 	apiKey := &APIKey{
@@ -58,7 +102,10 @@ func (s *Service) CreateAPIKey(userID, name string, permissions []Permission, ex
 		UserID:      userID,
 		Name:        name,
 		KeyHash:     keyHash,
+		KeyPrefix:   rawKey[:keyPrefixLength],
+		Mode:        mode,
 		Permissions: permissions,
+		AllowedIPs:  allowedIPs,
 		ExpiresAt:   time.Now().Add(expiry.ToDuration()),
 		IsRevoked:   false,
 		CreatedAt:   time.Now(),
@@ -86,8 +133,8 @@ func (s *Service) RolloverAPIKey(userID, expiredKeyID string, newExpiry ExpiryDu
 		return nil, "", ErrKeyNotExpired
 	}
 
-	// Create new key with same permissions
-	return s.CreateAPIKey(userID, oldKey.Name, oldKey.Permissions, newExpiry)
+	// Create new key with same mode, permissions and allowlist
+	return s.CreateAPIKey(userID, oldKey.Name, oldKey.Mode, oldKey.Permissions, oldKey.AllowedIPs, newExpiry)
 }
 
 func (s *Service) ValidateAPIKey(rawKey string) (*APIKey, error) {
@@ -97,26 +144,160 @@ func (s *Service) ValidateAPIKey(rawKey string) (*APIKey, error) {
 		return nil, err
 	}
 
-	if apiKey.IsRevoked {
-		return nil, errors.New("api key is revoked")
+	if err := apiKey.usable(); err != nil {
+		return nil, err
 	}
 
-	if apiKey.IsExpired() {
-		return nil, errors.New("api key is expired")
+	return apiKey, nil
+}
+
+// ListAPIKeys returns the user's keys, newest first, including expired and
+// revoked ones
+func (s *Service) ListAPIKeys(userID string) ([]*APIKey, error) {
+	keys, err := s.repo.ListByUserID(userID)
+	if err != nil {
+		return nil, err
 	}
+	if keys == nil {
+		keys = []*APIKey{}
+	}
+	return keys, nil
+}
 
-	return apiKey, nil
+// GetAPIKey returns one of the user's keys. Keys belonging to other users
+// are reported as not found.
+func (s *Service) GetAPIKey(userID, id string) (*APIKey, error) {
+	key, err := s.repo.GetByID(id)
+	if err != nil || key.UserID != userID {
+		return nil, ErrKeyNotFound
+	}
+	return key, nil
+}
+
+// RevokeAPIKey stops one of the user's keys from authenticating any further
+// requests. Revoking a revoked key returns it unchanged.
+func (s *Service) RevokeAPIKey(userID, id string) (*APIKey, error) {
+	key, err := s.GetAPIKey(userID, id)
+	if err != nil {
+		return nil, err
+	}
+	if key.IsRevoked {
+		return key, nil
+	}
+
+	key.IsRevoked = true
+	key.UpdatedAt = time.Now()
+	if err := s.repo.Update(key); err != nil {
+		return nil, err
+	}
+	return key, nil
+}
+
+// SetAllowedIPs replaces the ranges one of the user's keys may be used
+// from. An empty list lets the key be used from anywhere.
+func (s *Service) SetAllowedIPs(userID, id string, allowedIPs []string) (*APIKey, error) {
+	allowedIPs, err := ParseAllowedIPs(allowedIPs)
+	if err != nil {
+		return nil, err
+	}
+
+	key, err := s.GetAPIKey(userID, id)
+	if err != nil {
+		return nil, err
+	}
+
+	key.AllowedIPs = allowedIPs
+	key.UpdatedAt = time.Now()
+	if err := s.repo.UpdateAllowedIPs(key); err != nil {
+		return nil, err
+	}
+	return key, nil
+}
+
+// CheckIP returns ErrIPNotAllowed if key may not be used from ip, and
+// records the refused attempt in the key's audit trail
+func (s *Service) CheckIP(key *APIKey, ip string) error {
+	if key.AllowsIP(ip) {
+		return nil
+	}
+
+	s.recordAudit(key, AuditEventIPRejected, ip, "")
+	return ErrIPNotAllowed
+}
+
+// recordAudit stores a refused use of key. Failing to store it is logged
+// and does not change the outcome of the request.
+func (s *Service) recordAudit(key *APIKey, event, ip, detail string) {
+	err := s.audit.Create(&AuditEvent{
+		ID:        security.GenerateID(),
+		APIKeyID:  key.ID,
+		UserID:    key.UserID,
+		Event:     event,
+		IP:        ip,
+		Detail:    detail,
+		CreatedAt: time.Now(),
+	})
+	if err != nil {
+		log.Printf("failed to record %s for api key %s: %v", event, key.ID, err)
+	}
+}
+
+// ListAuditEvents returns the latest refused uses of one of the user's keys
+func (s *Service) ListAuditEvents(userID, id string) ([]*AuditEvent, error) {
+	key, err := s.GetAPIKey(userID, id)
+	if err != nil {
+		return nil, err
+	}
+
+	events, err := s.audit.ListByAPIKeyID(key.ID, auditPageSize)
+	if err != nil {
+		return nil, err
+	}
+	if events == nil {
+		events = []*AuditEvent{}
+	}
+	return events, nil
+}
+
+// RecordUse notes that key authenticated a request from ip
+func (s *Service) RecordUse(key *APIKey, ip string) error {
+	now := time.Now()
+	return s.repo.RecordUse(key.ID, now, ip, now.Add(-lastUsedResolution))
+}
+
+// usable returns why the key can no longer authenticate requests, if it
+// cannot
+func (k *APIKey) usable() error {
+	if k.IsRevoked {
+		return errors.New("api key is revoked")
+	}
+	if k.IsExpired() {
+		return errors.New("api key is expired")
+	}
+	return nil
 }
 
 func (k *APIKey) IsExpired() bool {
 	return time.Now().After(k.ExpiresAt)
 }
 
-func (k *APIKey) HasPermission(perm Permission) bool {
-	for _, p := range k.Permissions {
-		if p == perm {
-			return true
-		}
+func (k *APIKey) Status() KeyStatus {
+	switch {
+	case k.IsRevoked:
+		return KeyStatusRevoked
+	case k.IsExpired():
+		return KeyStatusExpired
 	}
-	return false
+	return KeyStatusActive
+}
+
+// IsTest reports whether the key operates on sandbox wallets
+func (k *APIKey) IsTest() bool {
+	return k.Mode == KeyModeTest
+}
+
+// HasPermission reports whether the key may use perm at all, before any
+// constraint on the request's amount or recipient is checked
+func (k *APIKey) HasPermission(perm Permission) bool {
+	return k.Authorize(Action{Permission: perm}) == nil
 }
@@ -0,0 +1,339 @@
+package auth
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/BerylCAtieno/paystack-wallet/client"
+)
+
+// memoryKeys holds keys by ID; the signature path only ever looks keys up
+type memoryKeys map[string]*APIKey
+
+func (m memoryKeys) Create(key *APIKey) error {
+	m[key.ID] = key
+	return nil
+}
+
+func (m memoryKeys) GetByID(id string) (*APIKey, error) {
+	key, ok := m[id]
+	if !ok {
+		return nil, ErrKeyNotFound
+	}
+	return key, nil
+}
+
+func (m memoryKeys) GetByKeyHash(hash string) (*APIKey, error)      { return nil, ErrKeyNotFound }
+func (m memoryKeys) CountActiveByUserID(userID string) (int, error) { return len(m), nil }
+func (m memoryKeys) Update(key *APIKey) error                       { return nil }
+func (m memoryKeys) UpdateAllowedIPs(key *APIKey) error             { return nil }
+func (m memoryKeys) ListByUserID(userID string) ([]*APIKey, error)  { return nil, nil }
+func (m memoryKeys) RecordUse(id string, at time.Time, ip string, staleBefore time.Time) error {
+	return nil
+}
+
+type memoryAudit struct {
+	events []*AuditEvent
+}
+
+func (m *memoryAudit) Create(event *AuditEvent) error {
+	m.events = append(m.events, event)
+	return nil
+}
+
+func (m *memoryAudit) ListByAPIKeyID(apiKeyID string, limit int) ([]*AuditEvent, error) {
+	return m.events, nil
+}
+
+const testMaxSkew = 5 * time.Minute
+
+func newSignatureService(t *testing.T) (*Service, *APIKey, *memoryAudit) {
+	t.Helper()
+
+	key := &APIKey{
+		ID:        "key-1",
+		UserID:    "user-1",
+		KeyHash:   "stored-key-hash",
+		ExpiresAt: time.Now().Add(time.Hour),
+	}
+	audit := &memoryAudit{}
+	s := NewService(memoryKeys{key.ID: key}, audit, "server-secret", testMaxSkew)
+	return s, key, audit
+}
+
+// signedRequest signs a request with the client signer and reads it back the
+// way the signature middleware does
+func signedRequest(t *testing.T, keyID, signingSecret, method, target, body string) SignedRequest {
+	t.Helper()
+
+	req := httptest.NewRequest(method, target, strings.NewReader(body))
+	if err := client.NewSigner(keyID, signingSecret).Sign(req); err != nil {
+		t.Fatalf("Sign() error = %v", err)
+	}
+	return readSignedRequest(req, body)
+}
+
+func readSignedRequest(req *http.Request, body string) SignedRequest {
+	signed := SignedRequest{
+		KeyID:     req.Header.Get(client.HeaderKeyID),
+		Timestamp: req.Header.Get(client.HeaderTimestamp),
+		Nonce:     req.Header.Get(client.HeaderNonce),
+		Signature: req.Header.Get(client.HeaderSignature),
+	}
+	signed.StringToSign = client.StringToSign(signed.KeyID, signed.Timestamp, signed.Nonce,
+		req.Method, req.URL.RequestURI(), []byte(body))
+	return signed
+}
+
+// signedAt signs a request as a client whose clock reads at would
+func signedAt(keyID, signingSecret string, at time.Time, nonce string) SignedRequest {
+	timestamp := strconv.FormatInt(at.Unix(), 10)
+	stringToSign := client.StringToSign(keyID, timestamp, nonce, http.MethodGet, "/wallet/balance", nil)
+	return SignedRequest{
+		KeyID:        keyID,
+		Timestamp:    timestamp,
+		Nonce:        nonce,
+		Signature:    client.Signature([]byte(signingSecret), stringToSign),
+		StringToSign: stringToSign,
+	}
+}
+
+func TestVerifySignature(t *testing.T) {
+	const body = `{"amount":5000}`
+
+	tests := []struct {
+		name    string
+		request func(t *testing.T, s *Service, key *APIKey) SignedRequest
+		wantErr error
+	}{
+		{
+			name: "signed by the client",
+			request: func(t *testing.T, s *Service, key *APIKey) SignedRequest {
+				return signedRequest(t, key.ID, s.SigningSecret(key), http.MethodPost, "/wallet/transfer?dry_run=1", body)
+			},
+		},
+		{
+			name: "unknown key",
+			request: func(t *testing.T, s *Service, key *APIKey) SignedRequest {
+				return signedRequest(t, "key-2", s.SigningSecret(key), http.MethodGet, "/wallet/balance", "")
+			},
+			wantErr: ErrInvalidSignature,
+		},
+		{
+			name: "signed with the stored key hash",
+			request: func(t *testing.T, s *Service, key *APIKey) SignedRequest {
+				return signedRequest(t, key.ID, key.KeyHash, http.MethodGet, "/wallet/balance", "")
+			},
+			wantErr: ErrInvalidSignature,
+		},
+		{
+			name: "signed with another key's secret",
+			request: func(t *testing.T, s *Service, key *APIKey) SignedRequest {
+				other := s.SigningSecret(&APIKey{ID: "key-2"})
+				return signedRequest(t, key.ID, other, http.MethodGet, "/wallet/balance", "")
+			},
+			wantErr: ErrInvalidSignature,
+		},
+		{
+			name: "signed with another server's secret",
+			request: func(t *testing.T, s *Service, key *APIKey) SignedRequest {
+				other := NewService(nil, nil, "other-secret", testMaxSkew).SigningSecret(key)
+				return signedRequest(t, key.ID, other, http.MethodGet, "/wallet/balance", "")
+			},
+			wantErr: ErrInvalidSignature,
+		},
+		{
+			name: "body changed after signing",
+			request: func(t *testing.T, s *Service, key *APIKey) SignedRequest {
+				req := httptest.NewRequest(http.MethodPost, "/wallet/transfer", strings.NewReader(body))
+				if err := client.NewSigner(key.ID, s.SigningSecret(key)).Sign(req); err != nil {
+					t.Fatalf("Sign() error = %v", err)
+				}
+				return readSignedRequest(req, `{"amount":500000}`)
+			},
+			wantErr: ErrInvalidSignature,
+		},
+		{
+			name: "path changed after signing",
+			request: func(t *testing.T, s *Service, key *APIKey) SignedRequest {
+				req := httptest.NewRequest(http.MethodGet, "/wallet/balance", nil)
+				if err := client.NewSigner(key.ID, s.SigningSecret(key)).Sign(req); err != nil {
+					t.Fatalf("Sign() error = %v", err)
+				}
+				req.URL.Path = "/wallet/transactions"
+				return readSignedRequest(req, "")
+			},
+			wantErr: ErrInvalidSignature,
+		},
+		{
+			name: "client clock behind within the skew",
+			request: func(t *testing.T, s *Service, key *APIKey) SignedRequest {
+				return signedAt(key.ID, s.SigningSecret(key), time.Now().Add(-testMaxSkew+time.Minute), "nonce-behind-0001")
+			},
+		},
+		{
+			name: "client clock ahead within the skew",
+			request: func(t *testing.T, s *Service, key *APIKey) SignedRequest {
+				return signedAt(key.ID, s.SigningSecret(key), time.Now().Add(testMaxSkew-time.Minute), "nonce-ahead-00001")
+			},
+		},
+		{
+			name: "client clock too far behind",
+			request: func(t *testing.T, s *Service, key *APIKey) SignedRequest {
+				return signedAt(key.ID, s.SigningSecret(key), time.Now().Add(-testMaxSkew-time.Minute), "nonce-behind-0002")
+			},
+			wantErr: ErrStaleTimestamp,
+		},
+		{
+			name: "client clock too far ahead",
+			request: func(t *testing.T, s *Service, key *APIKey) SignedRequest {
+				return signedAt(key.ID, s.SigningSecret(key), time.Now().Add(testMaxSkew+time.Minute), "nonce-ahead-00002")
+			},
+			wantErr: ErrStaleTimestamp,
+		},
+		{
+			name: "timestamp not a number",
+			request: func(t *testing.T, s *Service, key *APIKey) SignedRequest {
+				req := signedAt(key.ID, s.SigningSecret(key), time.Now(), "nonce-timestamp-1")
+				req.Timestamp = "yesterday"
+				return req
+			},
+			wantErr: ErrStaleTimestamp,
+		},
+		{
+			name: "nonce too short",
+			request: func(t *testing.T, s *Service, key *APIKey) SignedRequest {
+				return signedAt(key.ID, s.SigningSecret(key), time.Now(), "short")
+			},
+			wantErr: ErrInvalidNonce,
+		},
+		{
+			name: "nonce too long",
+			request: func(t *testing.T, s *Service, key *APIKey) SignedRequest {
+				return signedAt(key.ID, s.SigningSecret(key), time.Now(), strings.Repeat("n", maxNonceLength+1))
+			},
+			wantErr: ErrInvalidNonce,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, key, audit := newSignatureService(t)
+
+			got, err := s.VerifySignature(tt.request(t, s, key), "203.0.113.7")
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("VerifySignature() error = %v, want %v", err, tt.wantErr)
+			}
+			if err != nil {
+				return
+			}
+			if got.ID != key.ID {
+				t.Errorf("VerifySignature() key = %s, want %s", got.ID, key.ID)
+			}
+			if len(audit.events) != 0 {
+				t.Errorf("recorded %d audit events for a good signature", len(audit.events))
+			}
+		})
+	}
+}
+
+func TestVerifySignatureAuditsRejections(t *testing.T) {
+	s, key, audit := newSignatureService(t)
+
+	req := signedRequest(t, key.ID, key.KeyHash, http.MethodGet, "/wallet/balance", "")
+	if _, err := s.VerifySignature(req, "203.0.113.7"); !errors.Is(err, ErrInvalidSignature) {
+		t.Fatalf("VerifySignature() error = %v, want %v", err, ErrInvalidSignature)
+	}
+
+	if len(audit.events) != 1 {
+		t.Fatalf("recorded %d audit events, want 1", len(audit.events))
+	}
+	if e := audit.events[0]; e.Event != AuditEventSignatureRejected || e.IP != "203.0.113.7" {
+		t.Errorf("audit event = %s from %s, want %s from 203.0.113.7", e.Event, e.IP, AuditEventSignatureRejected)
+	}
+}
+
+func TestVerifySignatureNonceReuse(t *testing.T) {
+	s, key, _ := newSignatureService(t)
+	secret := s.SigningSecret(key)
+
+	req := signedRequest(t, key.ID, secret, http.MethodGet, "/wallet/balance", "")
+	if _, err := s.VerifySignature(req, ""); err != nil {
+		t.Fatalf("first VerifySignature() error = %v", err)
+	}
+	if _, err := s.VerifySignature(req, ""); !errors.Is(err, ErrNonceReused) {
+		t.Fatalf("replayed VerifySignature() error = %v, want %v", err, ErrNonceReused)
+	}
+
+	// A forged request must not spend the nonce of a request yet to come
+	next := signedAt(key.ID, secret, time.Now(), "nonce-forged-0001")
+	forged := next
+	forged.Signature = client.Signature([]byte(key.KeyHash), forged.StringToSign)
+	if _, err := s.VerifySignature(forged, ""); !errors.Is(err, ErrInvalidSignature) {
+		t.Fatalf("forged VerifySignature() error = %v, want %v", err, ErrInvalidSignature)
+	}
+	if _, err := s.VerifySignature(next, ""); err != nil {
+		t.Fatalf("VerifySignature() after forgery error = %v", err)
+	}
+
+	// Nonces are remembered per key
+	other := &APIKey{ID: "key-2", UserID: key.UserID, ExpiresAt: key.ExpiresAt}
+	s.repo.Create(other)
+	reused := signedAt(other.ID, s.SigningSecret(other), time.Now(), next.Nonce)
+	if _, err := s.VerifySignature(reused, ""); err != nil {
+		t.Fatalf("VerifySignature() for another key error = %v", err)
+	}
+}
+
+func TestNonceCache(t *testing.T) {
+	now := time.Now()
+
+	t.Run("reuse", func(t *testing.T) {
+		n := newNonceCache()
+		if !n.use("a", now.Add(time.Minute)) {
+			t.Fatal("use() of a new nonce = false")
+		}
+		if n.use("a", now.Add(time.Minute)) {
+			t.Fatal("use() of a live nonce = true")
+		}
+		if !n.use("b", now.Add(time.Minute)) {
+			t.Fatal("use() of another nonce = false")
+		}
+	})
+
+	t.Run("expired nonce", func(t *testing.T) {
+		n := newNonceCache()
+		n.use("a", now.Add(-time.Second))
+		if !n.use("a", now.Add(time.Minute)) {
+			t.Fatal("use() of an expired nonce = false")
+		}
+	})
+
+	t.Run("pruning", func(t *testing.T) {
+		n := newNonceCache()
+		n.use("expired", now.Add(-time.Second))
+		n.use("live", now.Add(time.Minute))
+
+		// Pruning runs at most once a minute
+		n.use("next", now.Add(time.Minute))
+		if _, ok := n.seen["expired"]; !ok {
+			t.Fatal("pruned again within a minute")
+		}
+
+		n.lastPrune = now.Add(-2 * time.Minute)
+		n.use("last", now.Add(time.Minute))
+		if _, ok := n.seen["expired"]; ok {
+			t.Error("expired nonce was not pruned")
+		}
+		for _, nonce := range []string{"live", "next", "last"} {
+			if _, ok := n.seen[nonce]; !ok {
+				t.Errorf("live nonce %q was pruned", nonce)
+			}
+		}
+	})
+}
@@ -0,0 +1,21 @@
+package bank
+
+import "time"
+
+type Bank struct {
+	Code      string    `json:"code"`
+	Name      string    `json:"name"`
+	Slug      string    `json:"slug"`
+	Country   string    `json:"country"`
+	Currency  string    `json:"currency"`
+	Type      string    `json:"type"`
+	Active    bool      `json:"active"`
+	FetchedAt time.Time `json:"-"`
+}
+
+type AccountResolution struct {
+	AccountNumber string `json:"account_number"`
+	AccountName   string `json:"account_name"`
+	BankCode      string `json:"bank_code"`
+	BankName      string `json:"bank_name,omitempty"`
+}
@@ -0,0 +1,139 @@
+package bank
+
+import (
+	"errors"
+	"log"
+	"time"
+
+	"github.com/BerylCAtieno/paystack-wallet/internal/paystack"
+)
+
+var (
+	ErrInvalidAccountNumber = errors.New("account number must be 10 digits")
+	ErrAccountNotResolved   = errors.New("could not resolve account")
+)
+
+// NUBAN account numbers, the only kind Paystack resolves, are Nigerian
+const nubanCurrency = "NGN"
+
+type Repository interface {
+	ListByCurrency(currency string) ([]*Bank, error)
+	ReplaceForCurrency(currency string, banks []*Bank) error
+}
+
+// Provider is the upstream source of bank data, normally *paystack.Client
+type Provider interface {
+	ListBanks(currency string) ([]paystack.Bank, error)
+	ResolveAccount(accountNumber, bankCode string) (*paystack.ResolveAccountResponse, error)
+}
+
+type Service struct {
+	repo     Repository
+	provider Provider
+	cacheTTL time.Duration
+}
+
+func NewService(repo Repository, provider Provider, cacheTTL time.Duration) *Service {
+	return &Service{
+		repo:     repo,
+		provider: provider,
+		cacheTTL: cacheTTL,
+	}
+}
+
+// ListBanks serves banks from the SQLite cache, refreshing it from Paystack
+// when it is empty or older than the cache TTL. A stale cache is still
+// served if Paystack is unavailable.
+func (s *Service) ListBanks(currency string) ([]*Bank, error) {
+	cached, err := s.repo.ListByCurrency(currency)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(cached) > 0 && time.Since(cached[0].FetchedAt) < s.cacheTTL {
+		return cached, nil
+	}
+
+	fresh, err := s.refresh(currency)
+	if err != nil {
+		if len(cached) > 0 {
+			log.Printf("bank list refresh failed, serving cached list: %v", err)
+			return cached, nil
+		}
+		return nil, err
+	}
+
+	return fresh, nil
+}
+
+// ResolveAccount verifies a NUBAN account number and returns the holder's
+// name as registered with the bank.
+func (s *Service) ResolveAccount(accountNumber, bankCode string) (*AccountResolution, error) {
+	if !isNUBAN(accountNumber) {
+		return nil, ErrInvalidAccountNumber
+	}
+
+	resp, err := s.provider.ResolveAccount(accountNumber, bankCode)
+	if err != nil {
+		log.Printf("account resolution failed for bank %s: %v", bankCode, err)
+		return nil, ErrAccountNotResolved
+	}
+
+	resolution := &AccountResolution{
+		AccountNumber: resp.Data.AccountNumber,
+		AccountName:   resp.Data.AccountName,
+		BankCode:      bankCode,
+	}
+
+	// Best effort: the bank name comes from the cached list
+	if banks, err := s.repo.ListByCurrency(nubanCurrency); err == nil {
+		for _, b := range banks {
+			if b.Code == bankCode {
+				resolution.BankName = b.Name
+				break
+			}
+		}
+	}
+
+	return resolution, nil
+}
+
+func (s *Service) refresh(currency string) ([]*Bank, error) {
+	upstream, err := s.provider.ListBanks(currency)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	banks := make([]*Bank, 0, len(upstream))
+	for _, b := range upstream {
+		banks = append(banks, &Bank{
+			Code:      b.Code,
+			Name:      b.Name,
+			Slug:      b.Slug,
+			Country:   b.Country,
+			Currency:  currency,
+			Type:      b.Type,
+			Active:    b.Active,
+			FetchedAt: now,
+		})
+	}
+
+	if err := s.repo.ReplaceForCurrency(currency, banks); err != nil {
+		return nil, err
+	}
+
+	return banks, nil
+}
+
+func isNUBAN(accountNumber string) bool {
+	if len(accountNumber) != 10 {
+		return false
+	}
+	for _, r := range accountNumber {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+	return true
+}
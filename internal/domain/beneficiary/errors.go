@@ -0,0 +1,13 @@
+package beneficiary
+
+import "errors"
+
+var (
+	ErrNotFound          = errors.New("beneficiary not found")
+	ErrInvalidType       = errors.New("beneficiary type must be wallet or bank")
+	ErrDuplicate         = errors.New("beneficiary already saved")
+	ErrOwnWallet         = errors.New("cannot save own wallet as a beneficiary")
+	ErrRecipientNotFound = errors.New("recipient wallet not found")
+	ErrMissingDetails    = errors.New("missing beneficiary details")
+	ErrWrongType         = errors.New("beneficiary cannot be used for this operation")
+)
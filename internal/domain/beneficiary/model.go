@@ -0,0 +1,26 @@
+package beneficiary
+
+import "time"
+
+type Type string
+
+const (
+	// TypeWallet pays another wallet on this service
+	TypeWallet Type = "wallet"
+	// TypeBank pays out to an external bank account
+	TypeBank Type = "bank"
+)
+
+type Beneficiary struct {
+	ID            string    `json:"id"`
+	WalletID      string    `json:"wallet_id"`
+	Type          Type      `json:"type"`
+	Nickname      string    `json:"nickname"`
+	WalletNumber  string    `json:"wallet_number,omitempty"`
+	AccountNumber string    `json:"account_number,omitempty"`
+	BankCode      string    `json:"bank_code,omitempty"`
+	BankName      string    `json:"bank_name,omitempty"`
+	AccountName   string    `json:"account_name,omitempty"`
+	CreatedAt     time.Time `json:"created_at"`
+	UpdatedAt     time.Time `json:"updated_at"`
+}
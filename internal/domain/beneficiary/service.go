@@ -0,0 +1,161 @@
+package beneficiary
+
+import (
+	"strings"
+	"time"
+
+	"github.com/BerylCAtieno/paystack-wallet/internal/domain/bank"
+	"github.com/BerylCAtieno/paystack-wallet/internal/domain/wallet"
+	"github.com/BerylCAtieno/paystack-wallet/internal/identifier"
+	"github.com/BerylCAtieno/paystack-wallet/internal/security"
+)
+
+type Repository interface {
+	Create(b *Beneficiary) error
+	GetByID(id string) (*Beneficiary, error)
+	ListByWalletID(walletID string) ([]*Beneficiary, error)
+	FindDuplicate(b *Beneficiary) (*Beneficiary, error)
+	Update(b *Beneficiary) error
+	Delete(id string) error
+}
+
+type WalletLookup interface {
+	GetByWalletNumber(walletNumber string) (*wallet.Wallet, error)
+}
+
+type AccountResolver interface {
+	ResolveAccount(accountNumber, bankCode string) (*bank.AccountResolution, error)
+}
+
+type Service struct {
+	repo     Repository
+	wallets  WalletLookup
+	resolver AccountResolver
+}
+
+func NewService(repo Repository, wallets WalletLookup, resolver AccountResolver) *Service {
+	return &Service{
+		repo:     repo,
+		wallets:  wallets,
+		resolver: resolver,
+	}
+}
+
+type CreateInput struct {
+	Type          Type
+	Nickname      string
+	WalletNumber  string
+	AccountNumber string
+	BankCode      string
+}
+
+// Create saves a payee for the wallet. Wallet payees must exist on this
+// service; bank payees are verified through Paystack and stored with the
+// resolved account name.
+func (s *Service) Create(walletID string, in CreateInput) (*Beneficiary, error) {
+	now := time.Now()
+	b := &Beneficiary{
+		ID:        security.GenerateID(),
+		WalletID:  walletID,
+		Type:      in.Type,
+		Nickname:  strings.TrimSpace(in.Nickname),
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+
+	switch in.Type {
+	case TypeWallet:
+		if in.WalletNumber == "" {
+			return nil, ErrMissingDetails
+		}
+		if !identifier.ValidWalletNumber(in.WalletNumber) {
+			return nil, wallet.ErrInvalidWalletNumber
+		}
+		recipient, err := s.wallets.GetByWalletNumber(in.WalletNumber)
+		if err != nil {
+			return nil, ErrRecipientNotFound
+		}
+		if recipient.ID == walletID {
+			return nil, ErrOwnWallet
+		}
+		b.WalletNumber = recipient.WalletNumber
+
+	case TypeBank:
+		if in.AccountNumber == "" || in.BankCode == "" {
+			return nil, ErrMissingDetails
+		}
+		resolution, err := s.resolver.ResolveAccount(in.AccountNumber, in.BankCode)
+		if err != nil {
+			return nil, err
+		}
+		b.AccountNumber = resolution.AccountNumber
+		b.BankCode = resolution.BankCode
+		b.BankName = resolution.BankName
+		b.AccountName = resolution.AccountName
+
+	default:
+		return nil, ErrInvalidType
+	}
+
+	if b.Nickname == "" {
+		b.Nickname = b.defaultNickname()
+	}
+
+	if existing, err := s.repo.FindDuplicate(b); err == nil && existing != nil {
+		return nil, ErrDuplicate
+	}
+
+	if err := s.repo.Create(b); err != nil {
+		return nil, err
+	}
+
+	return b, nil
+}
+
+func (s *Service) List(walletID string) ([]*Beneficiary, error) {
+	return s.repo.ListByWalletID(walletID)
+}
+
+// Get returns a beneficiary only if it belongs to the wallet
+func (s *Service) Get(walletID, id string) (*Beneficiary, error) {
+	b, err := s.repo.GetByID(id)
+	if err != nil || b.WalletID != walletID {
+		return nil, ErrNotFound
+	}
+	return b, nil
+}
+
+// Rename changes the nickname; payee details are immutable, delete and
+// re-create the beneficiary to change them.
+func (s *Service) Rename(walletID, id, nickname string) (*Beneficiary, error) {
+	b, err := s.Get(walletID, id)
+	if err != nil {
+		return nil, err
+	}
+
+	nickname = strings.TrimSpace(nickname)
+	if nickname == "" {
+		return nil, ErrMissingDetails
+	}
+
+	b.Nickname = nickname
+	b.UpdatedAt = time.Now()
+	if err := s.repo.Update(b); err != nil {
+		return nil, err
+	}
+	return b, nil
+}
+
+func (s *Service) Delete(walletID, id string) error {
+	if _, err := s.Get(walletID, id); err != nil {
+		return err
+	}
+	return s.repo.Delete(id)
+}
+
+func (b *Beneficiary) defaultNickname() string {
+	if b.Type == TypeBank {
+		return b.AccountName
+	}
+	return b.WalletNumber
+}
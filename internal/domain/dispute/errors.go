@@ -0,0 +1,8 @@
+package dispute
+
+import "errors"
+
+var (
+	ErrNotFound      = errors.New("dispute not found")
+	ErrMissingCharge = errors.New("dispute has no transaction reference")
+)
@@ -0,0 +1,19 @@
+package dispute
+
+import "time"
+
+// Dispute is a chargeback a customer raised against a deposit charge
+type Dispute struct {
+	ID               string    `json:"id"`
+	PaystackID       string    `json:"paystack_id"`
+	DepositReference string    `json:"deposit_reference"`
+	WalletID         string    `json:"wallet_id,omitempty"`
+	Amount           int64     `json:"amount"`
+	Currency         string    `json:"currency"`
+	Status           string    `json:"status"`
+	Category         string    `json:"category,omitempty"`
+	Resolution       string    `json:"resolution,omitempty"`
+	DueAt            string    `json:"due_at,omitempty"`
+	CreatedAt        time.Time `json:"created_at"`
+	UpdatedAt        time.Time `json:"updated_at"`
+}
@@ -0,0 +1,64 @@
+package dispute
+
+import (
+	"log"
+	"time"
+
+	"github.com/BerylCAtieno/paystack-wallet/internal/domain/wallet"
+	"github.com/BerylCAtieno/paystack-wallet/internal/security"
+)
+
+type Repository interface {
+	Create(d *Dispute) error
+	GetByPaystackID(paystackID string) (*Dispute, error)
+	Update(d *Dispute) error
+}
+
+type DepositLookup interface {
+	GetByReference(reference string) (*wallet.Transaction, error)
+}
+
+type Service struct {
+	repo     Repository
+	deposits DepositLookup
+}
+
+func NewService(repo Repository, deposits DepositLookup) *Service {
+	return &Service{repo: repo, deposits: deposits}
+}
+
+// Record creates or updates a dispute from a charge.dispute.* event so
+// finance can respond before the due date.
+func (s *Service) Record(d *Dispute) (*Dispute, error) {
+	if d.DepositReference == "" {
+		return nil, ErrMissingCharge
+	}
+
+	now := time.Now()
+	existing, err := s.repo.GetByPaystackID(d.PaystackID)
+	if err == nil {
+		existing.Status = d.Status
+		if d.Resolution != "" {
+			existing.Resolution = d.Resolution
+		}
+		if d.DueAt != "" {
+			existing.DueAt = d.DueAt
+		}
+		existing.UpdatedAt = now
+		return existing, s.repo.Update(existing)
+	}
+
+	d.ID = security.GenerateID()
+	d.CreatedAt = now
+	d.UpdatedAt = now
+	if deposit, err := s.deposits.GetByReference(d.DepositReference); err == nil {
+		d.WalletID = deposit.WalletID
+	}
+
+	if err := s.repo.Create(d); err != nil {
+		return nil, err
+	}
+
+	log.Printf("Dispute %s opened on deposit %s (%d %s), due %s", d.PaystackID, d.DepositReference, d.Amount, d.Currency, d.DueAt)
+	return d, nil
+}
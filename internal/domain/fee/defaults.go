@@ -0,0 +1,49 @@
+package fee
+
+import "github.com/BerylCAtieno/paystack-wallet/internal/money"
+
+// DefaultRules pass on Paystack's local NGN pricing: 1.5% on card and bank
+// charges plus ₦100 from ₦2,500, capped at ₦2,000, and ₦10, ₦25 or ₦50 per
+// payout depending on its size. Wallet transfers and other currencies are
+// free until rules are configured for them.
+var DefaultRules = []Rule{
+	{
+		Name:       "ngn-deposit-small",
+		Operation:  OperationDeposit,
+		Currency:   money.NGN,
+		MaxAmount:  249_999,
+		PercentBps: 150,
+		Cap:        200_000,
+	},
+	{
+		Name:       "ngn-deposit",
+		Operation:  OperationDeposit,
+		Currency:   money.NGN,
+		MinAmount:  250_000,
+		Flat:       10_000,
+		PercentBps: 150,
+		Cap:        200_000,
+	},
+	{
+		Name:      "ngn-withdrawal-small",
+		Operation: OperationWithdrawal,
+		Currency:  money.NGN,
+		MaxAmount: 500_000,
+		Flat:      1_000,
+	},
+	{
+		Name:      "ngn-withdrawal",
+		Operation: OperationWithdrawal,
+		Currency:  money.NGN,
+		MinAmount: 500_001,
+		MaxAmount: 5_000_000,
+		Flat:      2_500,
+	},
+	{
+		Name:      "ngn-withdrawal-large",
+		Operation: OperationWithdrawal,
+		Currency:  money.NGN,
+		MinAmount: 5_000_001,
+		Flat:      5_000,
+	},
+}
@@ -0,0 +1,123 @@
+package fee
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"math/big"
+	"os"
+
+	"github.com/BerylCAtieno/paystack-wallet/internal/money"
+)
+
+// Engine prices operations with the first rule that matches. Operations no
+// rule matches are free.
+type Engine struct {
+	rules []Rule
+}
+
+func NewEngine(rules []Rule) (*Engine, error) {
+	for i, r := range rules {
+		if err := r.validate(); err != nil {
+			return nil, fmt.Errorf("rule %d: %w", i, err)
+		}
+	}
+	return &Engine{rules: rules}, nil
+}
+
+// LoadRules reads a JSON array of rules from path
+func LoadRules(path string) ([]Rule, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("read fee rules: %w", err)
+	}
+
+	var rules []Rule
+	if err := json.Unmarshal(data, &rules); err != nil {
+		return nil, fmt.Errorf("parse fee rules: %w", err)
+	}
+	return rules, nil
+}
+
+func (e *Engine) Calculate(req Request) Breakdown {
+	b := Breakdown{
+		Operation: req.Operation,
+		Amount:    req.Amount,
+		Fee:       money.New(0, req.Amount.Currency),
+		Total:     req.Amount,
+	}
+
+	rule, ok := e.match(req)
+	if !ok {
+		return b
+	}
+
+	b.Flat = rule.Flat
+	b.Percentage = percentage(req.Amount.Amount, rule.PercentBps)
+
+	fee := add(b.Flat, b.Percentage)
+	if rule.Cap > 0 && fee > rule.Cap {
+		fee = rule.Cap
+		b.Capped = true
+	}
+
+	b.Fee = money.New(fee, req.Amount.Currency)
+	b.Total = money.New(add(req.Amount.Amount, fee), req.Amount.Currency)
+	b.Rule = rule.Name
+	return b
+}
+
+// percentage returns bps basis points of amount rounded half up to a whole
+// minor unit. The product can overflow an int64 for large amounts, so it is
+// worked out exactly and a fee too large to represent is clamped rather
+// than wrapping around to a negative one.
+func percentage(amount, bps int64) int64 {
+	p := new(big.Int).Mul(big.NewInt(amount), big.NewInt(bps))
+	p.Add(p, big.NewInt(5000))
+	p.Quo(p, big.NewInt(10000))
+	if !p.IsInt64() {
+		return math.MaxInt64
+	}
+	return p.Int64()
+}
+
+// add sums two non-negative amounts, clamping at the largest int64
+func add(a, b int64) int64 {
+	if a > math.MaxInt64-b {
+		return math.MaxInt64
+	}
+	return a + b
+}
+
+func (e *Engine) match(req Request) (Rule, bool) {
+	for _, r := range e.rules {
+		if r.Operation != req.Operation {
+			continue
+		}
+		if r.Currency != "" && r.Currency != req.Amount.Currency {
+			continue
+		}
+		if req.Amount.Amount < r.MinAmount || (r.MaxAmount > 0 && req.Amount.Amount > r.MaxAmount) {
+			continue
+		}
+		if r.Tier != nil && *r.Tier != req.Tier {
+			continue
+		}
+		return r, true
+	}
+	return Rule{}, false
+}
+
+func (r Rule) validate() error {
+	switch {
+	case !r.Operation.Valid():
+		return fmt.Errorf("%w: unknown operation %q", ErrInvalidRule, r.Operation)
+	case r.Currency != "" && !r.Currency.Valid():
+		return fmt.Errorf("%w: unsupported currency %q", ErrInvalidRule, r.Currency)
+	case r.MinAmount < 0 || r.MaxAmount < 0 || (r.MaxAmount > 0 && r.MaxAmount < r.MinAmount):
+		return fmt.Errorf("%w: bad amount band", ErrInvalidRule)
+	case r.Flat < 0 || r.PercentBps < 0 || r.Cap < 0:
+		return fmt.Errorf("%w: fees must not be negative", ErrInvalidRule)
+	}
+	return nil
+}
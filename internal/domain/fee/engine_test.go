@@ -0,0 +1,145 @@
+package fee
+
+import (
+	"errors"
+	"math"
+	"testing"
+
+	"github.com/BerylCAtieno/paystack-wallet/internal/money"
+)
+
+func TestCalculate(t *testing.T) {
+	vip := 2
+	engine, err := NewEngine([]Rule{
+		{Name: "vip-transfer", Operation: OperationTransfer, Tier: &vip},
+		{Name: "transfer", Operation: OperationTransfer, Flat: 100, PercentBps: 50},
+		{Name: "ngn-withdrawal", Operation: OperationWithdrawal, Currency: money.NGN, MinAmount: 1000, MaxAmount: 100_000, Flat: 500},
+		{Name: "withdrawal", Operation: OperationWithdrawal, PercentBps: 100, Cap: 2000},
+	})
+	if err != nil {
+		t.Fatalf("NewEngine() error = %v", err)
+	}
+
+	tests := []struct {
+		name       string
+		req        Request
+		wantRule   string
+		wantFee    int64
+		wantTotal  int64
+		wantCapped bool
+	}{
+		{"first match wins", Request{OperationTransfer, money.New(1_000_000, money.NGN), 2}, "vip-transfer", 0, 1_000_000, false},
+		{"tier not matched", Request{OperationTransfer, money.New(10_000, money.NGN), 1}, "transfer", 150, 10_150, false},
+		{"percentage rounds half up", Request{OperationTransfer, money.New(100, money.NGN), 1}, "transfer", 101, 201, false},
+		{"percentage rounds down below half", Request{OperationTransfer, money.New(99, money.NGN), 1}, "transfer", 100, 199, false},
+		{"bottom of band", Request{OperationWithdrawal, money.New(1000, money.NGN), 1}, "ngn-withdrawal", 500, 1500, false},
+		{"top of band", Request{OperationWithdrawal, money.New(100_000, money.NGN), 1}, "ngn-withdrawal", 500, 100_500, false},
+		{"below band", Request{OperationWithdrawal, money.New(999, money.NGN), 1}, "withdrawal", 10, 1009, false},
+		{"above band", Request{OperationWithdrawal, money.New(100_001, money.NGN), 1}, "withdrawal", 1000, 101_001, false},
+		{"other currency", Request{OperationWithdrawal, money.New(50_000, money.USD), 1}, "withdrawal", 500, 50_500, false},
+		{"at cap", Request{OperationWithdrawal, money.New(200_000, money.USD), 1}, "withdrawal", 2000, 202_000, false},
+		{"over cap", Request{OperationWithdrawal, money.New(1_000_000, money.USD), 1}, "withdrawal", 2000, 1_002_000, true},
+		{"no rule", Request{OperationDeposit, money.New(5000, money.NGN), 1}, "", 0, 5000, false},
+		{"largest amount", Request{OperationTransfer, money.New(math.MaxInt64, money.NGN), 1}, "transfer", 46116860184273979, math.MaxInt64, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			b := engine.Calculate(tt.req)
+			if b.Rule != tt.wantRule {
+				t.Errorf("rule = %q, want %q", b.Rule, tt.wantRule)
+			}
+			if b.Fee.Amount != tt.wantFee {
+				t.Errorf("fee = %d, want %d", b.Fee.Amount, tt.wantFee)
+			}
+			if b.Total.Amount != tt.wantTotal {
+				t.Errorf("total = %d, want %d", b.Total.Amount, tt.wantTotal)
+			}
+			if b.Capped != tt.wantCapped {
+				t.Errorf("capped = %v, want %v", b.Capped, tt.wantCapped)
+			}
+			if b.Fee.Currency != tt.req.Amount.Currency || b.Total.Currency != tt.req.Amount.Currency {
+				t.Errorf("currencies = %s/%s, want %s", b.Fee.Currency, b.Total.Currency, tt.req.Amount.Currency)
+			}
+		})
+	}
+}
+
+func TestCalculateDefaultRules(t *testing.T) {
+	engine, err := NewEngine(DefaultRules)
+	if err != nil {
+		t.Fatalf("NewEngine() error = %v", err)
+	}
+
+	tests := []struct {
+		name     string
+		req      Request
+		wantRule string
+		wantFee  int64
+	}{
+		{"small deposit", Request{Operation: OperationDeposit, Amount: money.New(100_000, money.NGN)}, "ngn-deposit-small", 1500},
+		{"deposit", Request{Operation: OperationDeposit, Amount: money.New(1_000_000, money.NGN)}, "ngn-deposit", 25_000},
+		{"capped deposit", Request{Operation: OperationDeposit, Amount: money.New(100_000_000, money.NGN)}, "ngn-deposit", 200_000},
+		{"small withdrawal", Request{Operation: OperationWithdrawal, Amount: money.New(500_000, money.NGN)}, "ngn-withdrawal-small", 1000},
+		{"withdrawal", Request{Operation: OperationWithdrawal, Amount: money.New(500_001, money.NGN)}, "ngn-withdrawal", 2500},
+		{"large withdrawal", Request{Operation: OperationWithdrawal, Amount: money.New(5_000_001, money.NGN)}, "ngn-withdrawal-large", 5000},
+		{"free transfer", Request{Operation: OperationTransfer, Amount: money.New(1_000_000, money.NGN)}, "", 0},
+		{"free in other currencies", Request{Operation: OperationDeposit, Amount: money.New(1_000_000, money.USD)}, "", 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			b := engine.Calculate(tt.req)
+			if b.Rule != tt.wantRule || b.Fee.Amount != tt.wantFee {
+				t.Errorf("Calculate() = %d by %q, want %d by %q", b.Fee.Amount, b.Rule, tt.wantFee, tt.wantRule)
+			}
+		})
+	}
+}
+
+func TestPercentage(t *testing.T) {
+	tests := []struct {
+		name   string
+		amount int64
+		bps    int64
+		want   int64
+	}{
+		{"zero", 0, 150, 0},
+		{"whole", 10_000, 150, 150},
+		{"half rounds up", 1, 5000, 1},
+		{"below half rounds down", 1, 4999, 0},
+		{"product overflows", math.MaxInt64, 150, 138350580552821637},
+		{"result overflows", math.MaxInt64, 20_000, math.MaxInt64},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := percentage(tt.amount, tt.bps); got != tt.want {
+				t.Errorf("percentage(%d, %d) = %d, want %d", tt.amount, tt.bps, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNewEngineValidation(t *testing.T) {
+	tests := []struct {
+		name string
+		rule Rule
+	}{
+		{"unknown operation", Rule{Operation: "refund"}},
+		{"unsupported currency", Rule{Operation: OperationDeposit, Currency: "XYZ"}},
+		{"negative min amount", Rule{Operation: OperationDeposit, MinAmount: -1}},
+		{"inverted band", Rule{Operation: OperationDeposit, MinAmount: 100, MaxAmount: 99}},
+		{"negative flat", Rule{Operation: OperationDeposit, Flat: -1}},
+		{"negative percentage", Rule{Operation: OperationDeposit, PercentBps: -1}},
+		{"negative cap", Rule{Operation: OperationDeposit, Cap: -1}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := NewEngine([]Rule{tt.rule}); !errors.Is(err, ErrInvalidRule) {
+				t.Errorf("NewEngine() error = %v, want %v", err, ErrInvalidRule)
+			}
+		})
+	}
+}
@@ -0,0 +1,5 @@
+package fee
+
+import "errors"
+
+var ErrInvalidRule = errors.New("invalid fee rule")
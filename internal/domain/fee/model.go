@@ -0,0 +1,60 @@
+package fee
+
+import "github.com/BerylCAtieno/paystack-wallet/internal/money"
+
+// Operation is the kind of money movement a fee is charged on
+type Operation string
+
+const (
+	OperationDeposit    Operation = "deposit"
+	OperationTransfer   Operation = "transfer"
+	OperationWithdrawal Operation = "withdrawal"
+)
+
+func (o Operation) Valid() bool {
+	switch o {
+	case OperationDeposit, OperationTransfer, OperationWithdrawal:
+		return true
+	}
+	return false
+}
+
+// Rule prices one operation, optionally only in one currency, amount band or
+// user tier. The fee is Flat plus PercentBps of the amount, limited to Cap
+// when it is set; all amounts are in the currency's minor unit.
+type Rule struct {
+	Name      string         `json:"name,omitempty"`
+	Operation Operation      `json:"operation"`
+	Currency  money.Currency `json:"currency,omitempty"`
+	// MinAmount and MaxAmount bound the band inclusively; a zero MaxAmount
+	// leaves it open-ended
+	MinAmount int64 `json:"min_amount,omitempty"`
+	MaxAmount int64 `json:"max_amount,omitempty"`
+	// Tier limits the rule to users of one tier
+	Tier       *int  `json:"tier,omitempty"`
+	Flat       int64 `json:"flat,omitempty"`
+	PercentBps int64 `json:"percent_bps,omitempty"`
+	Cap        int64 `json:"cap,omitempty"`
+}
+
+// Request describes an operation to price
+type Request struct {
+	Operation Operation
+	Amount    money.Money
+	// Tier is the user's tier; rules without a tier match every user
+	Tier int
+}
+
+// Breakdown explains the fee charged on an amount. Total is what the user
+// pays: the amount plus the fee.
+type Breakdown struct {
+	Operation Operation   `json:"operation"`
+	Amount    money.Money `json:"amount"`
+	// Flat and Percentage are the parts of the fee before any cap
+	Flat       int64       `json:"flat"`
+	Percentage int64       `json:"percentage"`
+	Capped     bool        `json:"capped"`
+	Fee        money.Money `json:"fee"`
+	Total      money.Money `json:"total"`
+	Rule       string      `json:"rule,omitempty"`
+}
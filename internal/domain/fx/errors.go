@@ -0,0 +1,17 @@
+package fx
+
+import "errors"
+
+var (
+	ErrQuoteNotFound = errors.New("quote not found")
+	ErrQuoteExpired  = errors.New("quote has expired")
+	ErrQuoteUsed     = errors.New("quote has already been used")
+	ErrSameCurrency  = errors.New("source and target currency are the same")
+	ErrInvalidAmount = errors.New("invalid amount")
+	// ErrAmountTooSmall is returned when the amount is too small to buy a
+	// single minor unit of the target currency after the spread
+	ErrAmountTooSmall = errors.New("amount is too small to convert")
+	// ErrRateUnavailable is returned when the rate provider has no rate for
+	// the currency pair or cannot be reached
+	ErrRateUnavailable = errors.New("exchange rate unavailable")
+)
@@ -0,0 +1,131 @@
+package fx
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/BerylCAtieno/paystack-wallet/internal/money"
+)
+
+// HTTPProvider fetches a rate table from a JSON feed and caches it for
+// cacheTTL. The feed must publish rates against one base currency, as
+// {"base": "USD", "rates": {...}} or {"base_code": "USD", "rates": {...}}.
+type HTTPProvider struct {
+	url      string
+	cacheTTL time.Duration
+	// maxAge is how long a cached table is served while the feed is
+	// unavailable, after which conversions stop rather than use old rates
+	maxAge time.Duration
+	client *http.Client
+
+	// fetchMu is held by the one caller refreshing the table, so mu is
+	// never held across the request to the feed
+	fetchMu   sync.Mutex
+	mu        sync.Mutex
+	table     *RateTable
+	fetchedAt time.Time
+}
+
+func NewHTTPProvider(url string, cacheTTL, maxAge time.Duration) *HTTPProvider {
+	if maxAge < cacheTTL {
+		maxAge = cacheTTL
+	}
+	return &HTTPProvider{
+		url:      url,
+		cacheTTL: cacheTTL,
+		maxAge:   maxAge,
+		client:   &http.Client{Timeout: 10 * time.Second},
+	}
+}
+
+// Rate serves from the cached table, refreshing it once it is older than
+// the cache TTL. While the feed is unavailable the cached table is served
+// until it is older than maxAge, and ErrRateUnavailable is returned after.
+func (p *HTTPProvider) Rate(base, quote money.Currency) (float64, error) {
+	table, err := p.current()
+	if err != nil {
+		return 0, err
+	}
+	return table.Rate(base, quote)
+}
+
+func (p *HTTPProvider) current() (*RateTable, error) {
+	table, age := p.cached()
+	if table != nil && age < p.cacheTTL {
+		return table, nil
+	}
+
+	// One caller refreshes the table while the others keep serving the
+	// cached one, or wait for the refresh if it is too old to serve
+	if !p.fetchMu.TryLock() {
+		if table != nil && age < p.maxAge {
+			return table, nil
+		}
+		p.fetchMu.Lock()
+	}
+	defer p.fetchMu.Unlock()
+
+	// Another caller may have refreshed the table while we waited
+	table, age = p.cached()
+	if table != nil && age < p.cacheTTL {
+		return table, nil
+	}
+
+	fetched, err := p.fetch()
+	if err != nil {
+		if table != nil && age < p.maxAge {
+			log.Printf("rate feed refresh failed, serving cached rates: %v", err)
+			return table, nil
+		}
+		return nil, fmt.Errorf("%w: %v", ErrRateUnavailable, err)
+	}
+
+	p.mu.Lock()
+	p.table = fetched
+	p.fetchedAt = time.Now()
+	p.mu.Unlock()
+	return fetched, nil
+}
+
+// cached returns the cached table, if any, and how long ago it was fetched
+func (p *HTTPProvider) cached() (*RateTable, time.Duration) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.table, time.Since(p.fetchedAt)
+}
+
+type feedResponse struct {
+	Base     money.Currency             `json:"base"`
+	BaseCode money.Currency             `json:"base_code"`
+	Rates    map[money.Currency]float64 `json:"rates"`
+}
+
+func (p *HTTPProvider) fetch() (*RateTable, error) {
+	resp, err := p.client.Get(p.url)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("rate feed returned %s", resp.Status)
+	}
+
+	var feed feedResponse
+	if err := json.NewDecoder(resp.Body).Decode(&feed); err != nil {
+		return nil, err
+	}
+
+	table := &RateTable{Base: feed.Base, Rates: feed.Rates}
+	if table.Base == "" {
+		table.Base = feed.BaseCode
+	}
+	if table.Base == "" || len(table.Rates) == 0 {
+		return nil, fmt.Errorf("rate feed returned no rates")
+	}
+	return table, nil
+}
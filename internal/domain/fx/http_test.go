@@ -0,0 +1,54 @@
+package fx
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/BerylCAtieno/paystack-wallet/internal/money"
+)
+
+func TestHTTPProviderStaleness(t *testing.T) {
+	var down atomic.Bool
+	feed := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if down.Load() {
+			http.Error(w, "unavailable", http.StatusServiceUnavailable)
+			return
+		}
+		w.Write([]byte(`{"base_code": "USD", "rates": {"NGN": 1500}}`))
+	}))
+	defer feed.Close()
+
+	p := NewHTTPProvider(feed.URL, time.Minute, time.Hour)
+	if rate, err := p.Rate(money.USD, money.NGN); err != nil || rate != 1500 {
+		t.Fatalf("Rate() = %v, %v, want 1500", rate, err)
+	}
+
+	down.Store(true)
+
+	tests := []struct {
+		name    string
+		age     time.Duration
+		wantErr error
+	}{
+		{"fresh", 30 * time.Second, nil},
+		{"past the cache TTL", 30 * time.Minute, nil},
+		{"past the max age", 2 * time.Hour, ErrRateUnavailable},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p.mu.Lock()
+			p.fetchedAt = time.Now().Add(-tt.age)
+			p.mu.Unlock()
+
+			_, err := p.Rate(money.USD, money.NGN)
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("Rate() error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
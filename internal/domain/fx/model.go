@@ -0,0 +1,36 @@
+package fx
+
+import (
+	"time"
+
+	"github.com/BerylCAtieno/paystack-wallet/internal/money"
+)
+
+// Quote locks an exchange rate for a short time. Source is what leaves the
+// sending wallet; Spread is the part of it kept as revenue and Target is
+// what the rest buys at the mid-market Rate.
+type Quote struct {
+	ID     string      `json:"id"`
+	UserID string      `json:"user_id"`
+	Source money.Money `json:"source"`
+	Spread money.Money `json:"spread"`
+	Target money.Money `json:"target"`
+	// Rate is how many major units of the target currency one major unit
+	// of the source currency buys, before the spread
+	Rate      float64     `json:"rate"`
+	Status    QuoteStatus `json:"status"`
+	ExpiresAt time.Time   `json:"expires_at"`
+	CreatedAt time.Time   `json:"created_at"`
+}
+
+type QuoteStatus string
+
+const (
+	QuoteStatusOpen QuoteStatus = "open"
+	// QuoteStatusUsed marks a quote that has moved money; a quote is used
+	// at most once
+	QuoteStatusUsed QuoteStatus = "used"
+	// QuoteStatusExpired is reported for open quotes past their expiry; it
+	// is never stored
+	QuoteStatusExpired QuoteStatus = "expired"
+)
@@ -0,0 +1,67 @@
+package fx
+
+import (
+	"fmt"
+
+	"github.com/BerylCAtieno/paystack-wallet/internal/money"
+)
+
+// RateProvider is the source of mid-market exchange rates
+type RateProvider interface {
+	// Rate returns how many major units of quote one major unit of base
+	// buys
+	Rate(base, quote money.Currency) (float64, error)
+}
+
+// RateTable holds every rate against a single base currency, the shape most
+// rate feeds publish. Cross rates are derived through the base.
+type RateTable struct {
+	Base  money.Currency             `json:"base"`
+	Rates map[money.Currency]float64 `json:"rates"`
+}
+
+func (t *RateTable) Rate(base, quote money.Currency) (float64, error) {
+	from, ok := t.against(base)
+	if !ok {
+		return 0, fmt.Errorf("%w: no rate for %s", ErrRateUnavailable, base)
+	}
+	to, ok := t.against(quote)
+	if !ok {
+		return 0, fmt.Errorf("%w: no rate for %s", ErrRateUnavailable, quote)
+	}
+	return to / from, nil
+}
+
+// against returns how many units of c one unit of the table's base buys
+func (t *RateTable) against(c money.Currency) (float64, bool) {
+	if c == t.Base {
+		return 1, true
+	}
+	rate, ok := t.Rates[c]
+	return rate, ok && rate > 0
+}
+
+// LocalProvider serves fixed rates from memory. It stands in for a real
+// feed in development and tests.
+type LocalProvider struct {
+	table RateTable
+}
+
+// DefaultLocalRates are indicative USD rates for the supported currencies
+var DefaultLocalRates = RateTable{
+	Base: money.USD,
+	Rates: map[money.Currency]float64{
+		money.NGN: 1500,
+		money.GHS: 15,
+		money.ZAR: 18,
+		money.KES: 130,
+	},
+}
+
+func NewLocalProvider(table RateTable) *LocalProvider {
+	return &LocalProvider{table: table}
+}
+
+func (p *LocalProvider) Rate(base, quote money.Currency) (float64, error) {
+	return p.table.Rate(base, quote)
+}
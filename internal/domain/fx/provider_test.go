@@ -0,0 +1,48 @@
+package fx
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/BerylCAtieno/paystack-wallet/internal/money"
+)
+
+func TestRateTableRate(t *testing.T) {
+	table := &RateTable{
+		Base: money.USD,
+		Rates: map[money.Currency]float64{
+			money.NGN: 1500,
+			money.GHS: 15,
+			money.ZAR: 0,
+		},
+	}
+
+	tests := []struct {
+		name        string
+		base, quote money.Currency
+		want        float64
+		wantErr     error
+	}{
+		{"from the base", money.USD, money.NGN, 1500, nil},
+		{"to the base", money.NGN, money.USD, 1.0 / 1500, nil},
+		{"cross rate", money.NGN, money.GHS, 15.0 / 1500, nil},
+		{"inverse cross rate", money.GHS, money.NGN, 1500.0 / 15, nil},
+		{"same currency", money.NGN, money.NGN, 1, nil},
+		{"base to itself", money.USD, money.USD, 1, nil},
+		{"missing base", money.KES, money.NGN, 0, ErrRateUnavailable},
+		{"missing quote", money.NGN, money.KES, 0, ErrRateUnavailable},
+		{"zero rate", money.USD, money.ZAR, 0, ErrRateUnavailable},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := table.Rate(tt.base, tt.quote)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Rate(%s, %s) error = %v, want %v", tt.base, tt.quote, err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("Rate(%s, %s) = %v, want %v", tt.base, tt.quote, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,147 @@
+package fx
+
+import (
+	"math/big"
+	"time"
+
+	"github.com/BerylCAtieno/paystack-wallet/internal/identifier"
+	"github.com/BerylCAtieno/paystack-wallet/internal/money"
+)
+
+// QuoteRepository stores quotes. Quotes are marked used through the wallet
+// unit of work that moves the money, so a quote can only be spent once.
+type QuoteRepository interface {
+	Create(q *Quote) error
+	GetByID(id string) (*Quote, error)
+}
+
+type Service struct {
+	provider RateProvider
+	repo     QuoteRepository
+	// spreadBps is the share of the source amount kept as revenue, in basis
+	// points
+	spreadBps int64
+	quoteTTL  time.Duration
+}
+
+func NewService(provider RateProvider, repo QuoteRepository, spreadBps int64, quoteTTL time.Duration) *Service {
+	return &Service{
+		provider:  provider,
+		repo:      repo,
+		spreadBps: spreadBps,
+		quoteTTL:  quoteTTL,
+	}
+}
+
+// Quote prices converting source into the to currency and locks the rate
+// for the quote TTL. The spread is taken from the source amount before it
+// is converted, and the target amount is rounded down to a whole minor unit.
+func (s *Service) Quote(userID string, source money.Money, to money.Currency) (*Quote, error) {
+	if !source.IsPositive() {
+		return nil, ErrInvalidAmount
+	}
+	if !source.Currency.Valid() || !to.Valid() {
+		return nil, money.ErrUnsupportedCurrency
+	}
+	if source.Currency == to {
+		return nil, ErrSameCurrency
+	}
+
+	rate, err := s.provider.Rate(source.Currency, to)
+	if err != nil {
+		return nil, err
+	}
+	if rate <= 0 {
+		return nil, ErrRateUnavailable
+	}
+
+	// Round the spread half up so small amounts still carry one
+	spread := (source.Amount*s.spreadBps + 5000) / 10000
+	converted := source.Amount - spread
+
+	target := convert(converted, source.Currency, to, rate)
+	if target <= 0 {
+		return nil, ErrAmountTooSmall
+	}
+
+	now := time.Now()
+	q := &Quote{
+		ID:        identifier.NewReference(identifier.PrefixQuote),
+		UserID:    userID,
+		Source:    source,
+		Spread:    money.New(spread, source.Currency),
+		Target:    money.New(target, to),
+		Rate:      rate,
+		Status:    QuoteStatusOpen,
+		ExpiresAt: now.Add(s.quoteTTL),
+		CreatedAt: now,
+	}
+
+	if err := s.repo.Create(q); err != nil {
+		return nil, err
+	}
+	return q, nil
+}
+
+// GetQuote returns one of the user's quotes, reporting open quotes past
+// their expiry as expired.
+func (s *Service) GetQuote(userID, id string) (*Quote, error) {
+	q, err := s.repo.GetByID(id)
+	if err != nil || q.UserID != userID {
+		return nil, ErrQuoteNotFound
+	}
+	if q.Status == QuoteStatusOpen && !q.ExpiresAt.After(time.Now()) {
+		q.Status = QuoteStatusExpired
+	}
+	return q, nil
+}
+
+// OpenQuote returns one of the user's quotes if it can still be used
+func (s *Service) OpenQuote(userID, id string) (*Quote, error) {
+	q, err := s.GetQuote(userID, id)
+	if err != nil {
+		return nil, err
+	}
+
+	switch q.Status {
+	case QuoteStatusUsed:
+		return nil, ErrQuoteUsed
+	case QuoteStatusExpired:
+		return nil, ErrQuoteExpired
+	}
+	return q, nil
+}
+
+// convert returns amount minor units of from in minor units of to, rounded
+// down. Rational arithmetic keeps the float rate from rounding the result
+// up past what the source amount buys.
+func convert(amount int64, from, to money.Currency, rate float64) int64 {
+	r := new(big.Rat).SetFloat64(rate)
+	if r == nil {
+		return 0
+	}
+
+	result := new(big.Rat).Mul(new(big.Rat).SetInt64(amount), r)
+
+	scale := new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(abs(to.Exponent()-from.Exponent()))), nil)
+	if to.Exponent() >= from.Exponent() {
+		result.Mul(result, new(big.Rat).SetInt(scale))
+	} else {
+		result.Quo(result, new(big.Rat).SetInt(scale))
+	}
+
+	// Rat numerators and denominators are positive here, so integer
+	// division rounds down
+	floor := new(big.Int).Quo(result.Num(), result.Denom())
+	if !floor.IsInt64() {
+		return 0
+	}
+	return floor.Int64()
+}
+
+func abs(n int) int {
+	if n < 0 {
+		return -n
+	}
+	return n
+}
@@ -0,0 +1,102 @@
+package fx
+
+import (
+	"errors"
+	"math"
+	"testing"
+	"time"
+
+	"github.com/BerylCAtieno/paystack-wallet/internal/money"
+)
+
+// jpy has no minor unit, which none of the supported currencies exercise
+const jpy money.Currency = "JPY"
+
+func TestConvert(t *testing.T) {
+	tests := []struct {
+		name     string
+		amount   int64
+		from, to money.Currency
+		rate     float64
+		want     int64
+	}{
+		{"same exponent", 100, money.USD, money.NGN, 1500, 150000},
+		{"fractional rate", 7, money.NGN, money.GHS, 1.5, 10},
+		{"rounds down below one minor unit", 3, money.NGN, money.GHS, 0.25, 0},
+		{"float rate above its decimal value", 10, money.NGN, money.GHS, 0.1, 1},
+		{"to fewer decimal places", 12345, money.USD, jpy, 150, 18517},
+		{"to more decimal places", 100, jpy, money.USD, 0.0078125, 78},
+		{"zero amount", 0, money.USD, money.NGN, 1500, 0},
+		{"overflow", math.MaxInt64, money.USD, money.NGN, 1e6, 0},
+		{"not a number", 100, money.USD, money.NGN, math.NaN(), 0},
+		{"infinite rate", 100, money.USD, money.NGN, math.Inf(1), 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := convert(tt.amount, tt.from, tt.to, tt.rate); got != tt.want {
+				t.Errorf("convert(%d, %s, %s, %v) = %d, want %d", tt.amount, tt.from, tt.to, tt.rate, got, tt.want)
+			}
+		})
+	}
+}
+
+type memoryQuotes map[string]*Quote
+
+func (m memoryQuotes) Create(q *Quote) error {
+	m[q.ID] = q
+	return nil
+}
+
+func (m memoryQuotes) GetByID(id string) (*Quote, error) {
+	q, ok := m[id]
+	if !ok {
+		return nil, ErrQuoteNotFound
+	}
+	return q, nil
+}
+
+func TestQuoteSpread(t *testing.T) {
+	tests := []struct {
+		name       string
+		source     money.Money
+		to         money.Currency
+		spreadBps  int64
+		wantSpread int64
+		wantTarget int64
+		wantErr    error
+	}{
+		{"whole spread", money.New(1000, money.USD), money.NGN, 100, 10, 1485000, nil},
+		{"half rounds up", money.New(50, money.USD), money.NGN, 100, 1, 73500, nil},
+		{"below half rounds down", money.New(49, money.USD), money.NGN, 100, 0, 73500, nil},
+		{"one and a half rounds up", money.New(150, money.USD), money.NGN, 100, 2, 222000, nil},
+		{"no spread", money.New(1000, money.USD), money.NGN, 0, 0, 1500000, nil},
+		{"cross rate", money.New(150000, money.NGN), money.GHS, 100, 1500, 1485, nil},
+		{"too small after spread", money.New(1, money.NGN), money.USD, 100, 0, 0, ErrAmountTooSmall},
+		{"same currency", money.New(1000, money.NGN), money.NGN, 100, 0, 0, ErrSameCurrency},
+		{"zero amount", money.New(0, money.NGN), money.USD, 100, 0, 0, ErrInvalidAmount},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := NewService(NewLocalProvider(DefaultLocalRates), memoryQuotes{}, tt.spreadBps, time.Minute)
+
+			q, err := s.Quote("user", tt.source, tt.to)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Quote() error = %v, want %v", err, tt.wantErr)
+			}
+			if err != nil {
+				return
+			}
+			if q.Spread.Amount != tt.wantSpread {
+				t.Errorf("spread = %d, want %d", q.Spread.Amount, tt.wantSpread)
+			}
+			if q.Target.Amount != tt.wantTarget {
+				t.Errorf("target = %d, want %d", q.Target.Amount, tt.wantTarget)
+			}
+			if q.Spread.Currency != tt.source.Currency || q.Target.Currency != tt.to {
+				t.Errorf("currencies = %s/%s, want %s/%s", q.Spread.Currency, q.Target.Currency, tt.source.Currency, tt.to)
+			}
+		})
+	}
+}
@@ -0,0 +1,37 @@
+package fx
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/BerylCAtieno/paystack-wallet/internal/money"
+)
+
+// StaticProvider serves rates read once from a JSON file such as
+//
+//	{"base": "USD", "rates": {"NGN": 1500, "GHS": 15}}
+type StaticProvider struct {
+	table RateTable
+}
+
+func NewStaticProvider(path string) (*StaticProvider, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("read rates file: %w", err)
+	}
+
+	var table RateTable
+	if err := json.Unmarshal(data, &table); err != nil {
+		return nil, fmt.Errorf("parse rates file: %w", err)
+	}
+	if !table.Base.Valid() {
+		return nil, fmt.Errorf("rates file: unsupported base currency %q", table.Base)
+	}
+
+	return &StaticProvider{table: table}, nil
+}
+
+func (p *StaticProvider) Rate(base, quote money.Currency) (float64, error) {
+	return p.table.Rate(base, quote)
+}
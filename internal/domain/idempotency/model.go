@@ -0,0 +1,19 @@
+package idempotency
+
+import "time"
+
+// Record is the stored outcome of a request made with an Idempotency-Key.
+// A StatusCode of zero means the original request is still in flight.
+type Record struct {
+	UserID       string    `json:"user_id"`
+	Key          string    `json:"key"`
+	RequestHash  string    `json:"request_hash"`
+	StatusCode   int       `json:"status_code"`
+	ResponseBody []byte    `json:"-"`
+	CreatedAt    time.Time `json:"created_at"`
+	ExpiresAt    time.Time `json:"expires_at"`
+}
+
+func (r *Record) InFlight() bool {
+	return r.StatusCode == 0
+}
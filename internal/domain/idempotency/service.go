@@ -0,0 +1,104 @@
+package idempotency
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"time"
+)
+
+var (
+	ErrKeyReused  = errors.New("idempotency key was used with a different request")
+	ErrInProgress = errors.New("a request with this idempotency key is still in progress")
+)
+
+type Repository interface {
+	// Insert stores the record unless one already exists for (user, key),
+	// reporting whether it was inserted.
+	Insert(record *Record) (bool, error)
+	Get(userID, key string) (*Record, error)
+	SaveResponse(userID, key string, statusCode int, body []byte) error
+	Delete(userID, key string) error
+	DeleteExpired(now time.Time) (int64, error)
+}
+
+type Service struct {
+	repo Repository
+	ttl  time.Duration
+}
+
+func NewService(repo Repository, ttl time.Duration) *Service {
+	return &Service{repo: repo, ttl: ttl}
+}
+
+// HashRequest fingerprints a request so a key cannot be replayed against a
+// different operation or payload.
+func HashRequest(method, path string, body []byte) string {
+	h := sha256.New()
+	h.Write([]byte(method))
+	h.Write([]byte{0})
+	h.Write([]byte(path))
+	h.Write([]byte{0})
+	h.Write(body)
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// Begin claims the key for a new request. If the key was already used it
+// returns the stored record to replay, ErrInProgress while the original is
+// still running, or ErrKeyReused if the request differs.
+func (s *Service) Begin(userID, key, requestHash string) (*Record, error) {
+	now := time.Now()
+	record := &Record{
+		UserID:      userID,
+		Key:         key,
+		RequestHash: requestHash,
+		CreatedAt:   now,
+		ExpiresAt:   now.Add(s.ttl),
+	}
+
+	inserted, err := s.repo.Insert(record)
+	if err != nil {
+		return nil, err
+	}
+	if inserted {
+		return nil, nil
+	}
+
+	existing, err := s.repo.Get(userID, key)
+	if err != nil {
+		return nil, err
+	}
+
+	// An expired key is free to be claimed again
+	if now.After(existing.ExpiresAt) {
+		if err := s.repo.Delete(userID, key); err != nil {
+			return nil, err
+		}
+		return s.Begin(userID, key, requestHash)
+	}
+
+	if existing.RequestHash != requestHash {
+		return nil, ErrKeyReused
+	}
+
+	if existing.InFlight() {
+		return nil, ErrInProgress
+	}
+
+	return existing, nil
+}
+
+// Complete stores the response so retries with the same key replay it
+func (s *Service) Complete(userID, key string, statusCode int, body []byte) error {
+	return s.repo.SaveResponse(userID, key, statusCode, body)
+}
+
+// Release frees the key without storing a response, letting the client
+// retry a request that failed before it could take effect.
+func (s *Service) Release(userID, key string) error {
+	return s.repo.Delete(userID, key)
+}
+
+func (s *Service) PurgeExpired() (int64, error) {
+	return s.repo.DeleteExpired(time.Now())
+}
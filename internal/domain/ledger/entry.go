@@ -0,0 +1,69 @@
+package ledger
+
+import (
+	"time"
+
+	"github.com/BerylCAtieno/paystack-wallet/internal/security"
+)
+
+func NewEntry(reference, description string) *JournalEntry {
+	return &JournalEntry{
+		ID:          security.GenerateID(),
+		Reference:   reference,
+		Description: description,
+		CreatedAt:   time.Now(),
+	}
+}
+
+// Debit adds a posting that increases asset/expense accounts and decreases
+// liability/revenue accounts such as wallets.
+func (e *JournalEntry) Debit(accountID string, amount int64) *JournalEntry {
+	return e.add(accountID, amount)
+}
+
+// Credit adds a posting that increases liability/revenue accounts such as
+// wallets and decreases asset/expense accounts.
+func (e *JournalEntry) Credit(accountID string, amount int64) *JournalEntry {
+	return e.add(accountID, -amount)
+}
+
+func (e *JournalEntry) add(accountID string, amount int64) *JournalEntry {
+	e.Postings = append(e.Postings, Posting{
+		ID:        security.GenerateID(),
+		EntryID:   e.ID,
+		AccountID: accountID,
+		Amount:    amount,
+		CreatedAt: e.CreatedAt,
+	})
+	return e
+}
+
+// Validate checks the entry is well formed. Per-currency balancing needs the
+// account currencies and is enforced where the entry is persisted.
+func (e *JournalEntry) Validate() error {
+	if len(e.Postings) < 2 {
+		return ErrEmptyEntry
+	}
+
+	var sum int64
+	for _, p := range e.Postings {
+		if p.Amount == 0 {
+			return ErrInvalidPosting
+		}
+		sum += p.Amount
+	}
+
+	if sum != 0 {
+		return ErrUnbalancedEntry
+	}
+	return nil
+}
+
+// Balance converts a raw posting sum into the account's natural balance,
+// e.g. a wallet (liability) with net credits of 500 has a balance of 500.
+func Balance(accountType AccountType, postingSum int64) int64 {
+	if accountType.DebitNormal() {
+		return postingSum
+	}
+	return -postingSum
+}
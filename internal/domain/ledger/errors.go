@@ -0,0 +1,10 @@
+package ledger
+
+import "errors"
+
+var (
+	ErrUnbalancedEntry = errors.New("journal entry postings do not sum to zero")
+	ErrEmptyEntry      = errors.New("journal entry needs at least two postings")
+	ErrInvalidPosting  = errors.New("posting amount must be non-zero")
+	ErrAccountNotFound = errors.New("ledger account not found")
+)
@@ -0,0 +1,79 @@
+package ledger
+
+import "time"
+
+type AccountType string
+
+const (
+	AccountTypeAsset     AccountType = "asset"
+	AccountTypeLiability AccountType = "liability"
+	AccountTypeRevenue   AccountType = "revenue"
+	AccountTypeExpense   AccountType = "expense"
+)
+
+// DebitNormal reports whether debits increase the account's balance.
+func (t AccountType) DebitNormal() bool {
+	return t == AccountTypeAsset || t == AccountTypeExpense
+}
+
+type Account struct {
+	ID        string      `json:"id"`
+	Name      string      `json:"name"`
+	Type      AccountType `json:"type"`
+	Currency  string      `json:"currency"`
+	WalletID  string      `json:"wallet_id,omitempty"`
+	CreatedAt time.Time   `json:"created_at"`
+}
+
+// SystemAccount identifies one of the platform-owned accounts that sit on
+// the other side of wallet postings.
+type SystemAccount string
+
+const (
+	// SystemPaystackSettlement holds funds collected through Paystack (asset)
+	SystemPaystackSettlement SystemAccount = "paystack_settlement"
+	// SystemFees accumulates fees charged to users (revenue)
+	SystemFees SystemAccount = "fees"
+	// SystemSuspense parks funds that cannot yet be attributed (liability)
+	SystemSuspense SystemAccount = "suspense"
+	// SystemPayouts held withdrawals sent to Paystack but not yet settled
+	// (liability). Pending withdrawals now reserve funds with wallet holds;
+	// the account only carries entries posted before holds existed.
+	SystemPayouts SystemAccount = "payouts"
+	// SystemRefunds held deposit refunds sent to Paystack but not yet
+	// processed (liability), and like SystemPayouts was replaced by holds
+	SystemRefunds SystemAccount = "refunds"
+	// SystemFXClearing is the platform's position in a currency from
+	// conversions: credited when users sell the currency and debited when
+	// they buy it (liability)
+	SystemFXClearing SystemAccount = "fx_clearing"
+	// SystemFXRevenue accumulates the spread kept on conversions (revenue)
+	SystemFXRevenue SystemAccount = "fx_revenue"
+)
+
+func SystemAccountID(account SystemAccount, currency string) string {
+	return "system:" + string(account) + ":" + currency
+}
+
+func WalletAccountID(walletID string) string {
+	return "wallet:" + walletID
+}
+
+// JournalEntry is a balanced set of postings. Posting amounts are signed:
+// positive amounts are debits, negative amounts are credits, and the
+// postings of an entry always sum to zero.
+type JournalEntry struct {
+	ID          string    `json:"id"`
+	Reference   string    `json:"reference"`
+	Description string    `json:"description,omitempty"`
+	Postings    []Posting `json:"postings"`
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+type Posting struct {
+	ID        string    `json:"id"`
+	EntryID   string    `json:"entry_id"`
+	AccountID string    `json:"account_id"`
+	Amount    int64     `json:"amount"`
+	CreatedAt time.Time `json:"created_at"`
+}
@@ -0,0 +1,33 @@
+package limit
+
+import (
+	"github.com/BerylCAtieno/paystack-wallet/internal/domain/user"
+	"github.com/BerylCAtieno/paystack-wallet/internal/money"
+)
+
+// DefaultRules follow the CBN's tiered KYC limits for NGN: ₦50,000 per
+// transaction and day and ₦300,000 a month unverified, ₦200,000, ₦500,000
+// and ₦5m with basic KYC, and ₦5m per transaction and ₦25m a day with full
+// KYC. Other currencies get roughly the same limits at the default FX
+// rates. Each rule covers deposits, transfers and withdrawals alike.
+var DefaultRules = []Rule{
+	{Tier: user.TierUnverified, Currency: money.NGN, Limits: Limits{Single: 5_000_000, Daily: 5_000_000, Monthly: 30_000_000}},
+	{Tier: user.TierBasic, Currency: money.NGN, Limits: Limits{Single: 20_000_000, Daily: 50_000_000, Monthly: 500_000_000}},
+	{Tier: user.TierFull, Currency: money.NGN, Limits: Limits{Single: 500_000_000, Daily: 2_500_000_000}},
+
+	{Tier: user.TierUnverified, Currency: money.GHS, Limits: Limits{Single: 50_000, Daily: 50_000, Monthly: 300_000}},
+	{Tier: user.TierBasic, Currency: money.GHS, Limits: Limits{Single: 200_000, Daily: 500_000, Monthly: 5_000_000}},
+	{Tier: user.TierFull, Currency: money.GHS, Limits: Limits{Single: 5_000_000, Daily: 25_000_000}},
+
+	{Tier: user.TierUnverified, Currency: money.ZAR, Limits: Limits{Single: 60_000, Daily: 60_000, Monthly: 360_000}},
+	{Tier: user.TierBasic, Currency: money.ZAR, Limits: Limits{Single: 240_000, Daily: 600_000, Monthly: 6_000_000}},
+	{Tier: user.TierFull, Currency: money.ZAR, Limits: Limits{Single: 6_000_000, Daily: 30_000_000}},
+
+	{Tier: user.TierUnverified, Currency: money.KES, Limits: Limits{Single: 430_000, Daily: 430_000, Monthly: 2_600_000}},
+	{Tier: user.TierBasic, Currency: money.KES, Limits: Limits{Single: 1_700_000, Daily: 4_300_000, Monthly: 43_000_000}},
+	{Tier: user.TierFull, Currency: money.KES, Limits: Limits{Single: 43_000_000, Daily: 215_000_000}},
+
+	{Tier: user.TierUnverified, Currency: money.USD, Limits: Limits{Single: 3_300, Daily: 3_300, Monthly: 20_000}},
+	{Tier: user.TierBasic, Currency: money.USD, Limits: Limits{Single: 13_300, Daily: 33_300, Monthly: 333_000}},
+	{Tier: user.TierFull, Currency: money.USD, Limits: Limits{Single: 333_000, Daily: 1_666_000}},
+}
@@ -0,0 +1,36 @@
+package limit
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/BerylCAtieno/paystack-wallet/internal/domain/fee"
+	"github.com/BerylCAtieno/paystack-wallet/internal/money"
+)
+
+var (
+	ErrInvalidRule = errors.New("invalid limit rule")
+	// ErrLimitExceeded matches every *ExceededError with errors.Is
+	ErrLimitExceeded = errors.New("limit exceeded")
+)
+
+// ExceededError is returned when an operation would go over one of the
+// user's limits. Remaining is the most the user can still move in the
+// operation right now, across all of their limits.
+type ExceededError struct {
+	Operation fee.Operation `json:"operation"`
+	Period    Period        `json:"period"`
+	Limit     money.Money   `json:"limit"`
+	Remaining money.Money   `json:"remaining"`
+	// ResetsAt is when the exceeded daily or monthly window starts over
+	ResetsAt *time.Time `json:"resets_at,omitempty"`
+}
+
+func (e *ExceededError) Error() string {
+	return fmt.Sprintf("%s %s limit of %s exceeded; %s remaining", e.Period, e.Operation, e.Limit, e.Remaining)
+}
+
+func (e *ExceededError) Is(target error) bool {
+	return target == ErrLimitExceeded
+}
@@ -0,0 +1,52 @@
+package limit
+
+import (
+	"time"
+
+	"github.com/BerylCAtieno/paystack-wallet/internal/domain/fee"
+	"github.com/BerylCAtieno/paystack-wallet/internal/domain/user"
+	"github.com/BerylCAtieno/paystack-wallet/internal/money"
+)
+
+// Period is the window a limit applies over
+type Period string
+
+const (
+	// PeriodSingle limits one transaction
+	PeriodSingle  Period = "single"
+	PeriodDaily   Period = "daily"
+	PeriodMonthly Period = "monthly"
+)
+
+// Limits caps the amount of an operation in a currency's minor unit. A zero
+// limit is no limit.
+type Limits struct {
+	Single  int64 `json:"single,omitempty"`
+	Daily   int64 `json:"daily,omitempty"`
+	Monthly int64 `json:"monthly,omitempty"`
+}
+
+// Rule sets the limits of one tier in one currency, for one operation or
+// for all of them when Operation is empty.
+type Rule struct {
+	Tier      user.Tier      `json:"tier"`
+	Operation fee.Operation  `json:"operation,omitempty"`
+	Currency  money.Currency `json:"currency"`
+	Limits
+}
+
+// Usage is what a wallet has already moved in an operation since the start
+// of the current day and month
+type Usage struct {
+	Daily   int64
+	Monthly int64
+}
+
+// Windows returns the start of the UTC day and month containing now. Daily
+// and monthly usage is counted from them and resets when the next begins.
+func Windows(now time.Time) (day, month time.Time) {
+	now = now.UTC()
+	day = time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+	month = time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, time.UTC)
+	return day, month
+}
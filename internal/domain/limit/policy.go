@@ -0,0 +1,120 @@
+package limit
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/BerylCAtieno/paystack-wallet/internal/domain/fee"
+	"github.com/BerylCAtieno/paystack-wallet/internal/domain/user"
+	"github.com/BerylCAtieno/paystack-wallet/internal/money"
+)
+
+// Policy looks up limits with the first rule that matches. Operations no
+// rule covers are not limited.
+type Policy struct {
+	rules []Rule
+}
+
+func NewPolicy(rules []Rule) (*Policy, error) {
+	for i, r := range rules {
+		if err := r.validate(); err != nil {
+			return nil, fmt.Errorf("rule %d: %w", i, err)
+		}
+	}
+	return &Policy{rules: rules}, nil
+}
+
+// LoadRules reads a JSON array of rules from path
+func LoadRules(path string) ([]Rule, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("read limit rules: %w", err)
+	}
+
+	var rules []Rule
+	if err := json.Unmarshal(data, &rules); err != nil {
+		return nil, fmt.Errorf("parse limit rules: %w", err)
+	}
+	return rules, nil
+}
+
+func (p *Policy) Limits(tier user.Tier, op fee.Operation, currency money.Currency) Limits {
+	for _, r := range p.rules {
+		if r.Tier != tier || r.Currency != currency {
+			continue
+		}
+		if r.Operation != "" && r.Operation != op {
+			continue
+		}
+		return r.Limits
+	}
+	return Limits{}
+}
+
+// Check returns an *ExceededError if moving amount on top of what was
+// already used would go over one of the tier's limits. Limits are checked
+// single, daily then monthly and the first one exceeded is reported.
+func (p *Policy) Check(tier user.Tier, op fee.Operation, amount money.Money, used Usage, now time.Time) error {
+	l := p.Limits(tier, op, amount.Currency)
+	day, month := Windows(now)
+	nextDay := day.AddDate(0, 0, 1)
+	nextMonth := month.AddDate(0, 1, 0)
+
+	var period Period
+	var limit int64
+	var resetsAt *time.Time
+	switch {
+	case l.Single > 0 && amount.Amount > l.Single:
+		period, limit = PeriodSingle, l.Single
+	case l.Daily > 0 && used.Daily+amount.Amount > l.Daily:
+		period, limit, resetsAt = PeriodDaily, l.Daily, &nextDay
+	case l.Monthly > 0 && used.Monthly+amount.Amount > l.Monthly:
+		period, limit, resetsAt = PeriodMonthly, l.Monthly, &nextMonth
+	default:
+		return nil
+	}
+
+	remaining, _ := l.Remaining(used)
+	return &ExceededError{
+		Operation: op,
+		Period:    period,
+		Limit:     money.New(limit, amount.Currency),
+		Remaining: money.New(remaining, amount.Currency),
+		ResetsAt:  resetsAt,
+	}
+}
+
+// Remaining returns the most a single transaction can move given what was
+// already used, and false when no limit applies.
+func (l Limits) Remaining(used Usage) (int64, bool) {
+	remaining := int64(-1)
+	consider := func(limit, used int64) {
+		if limit == 0 {
+			return
+		}
+		if left := max(limit-used, 0); remaining < 0 || left < remaining {
+			remaining = left
+		}
+	}
+
+	consider(l.Single, 0)
+	consider(l.Daily, used.Daily)
+	consider(l.Monthly, used.Monthly)
+	return remaining, remaining >= 0
+}
+
+func (r Rule) validate() error {
+	switch {
+	case !r.Tier.Valid():
+		return fmt.Errorf("%w: unknown tier %d", ErrInvalidRule, r.Tier)
+	case r.Operation != "" && !r.Operation.Valid():
+		return fmt.Errorf("%w: unknown operation %q", ErrInvalidRule, r.Operation)
+	case !r.Currency.Valid():
+		return fmt.Errorf("%w: unsupported currency %q", ErrInvalidRule, r.Currency)
+	case r.Single < 0 || r.Daily < 0 || r.Monthly < 0:
+		return fmt.Errorf("%w: limits must not be negative", ErrInvalidRule)
+	}
+	return nil
+}
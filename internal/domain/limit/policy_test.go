@@ -0,0 +1,172 @@
+package limit
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/BerylCAtieno/paystack-wallet/internal/domain/fee"
+	"github.com/BerylCAtieno/paystack-wallet/internal/domain/user"
+	"github.com/BerylCAtieno/paystack-wallet/internal/money"
+)
+
+func TestCheck(t *testing.T) {
+	policy, err := NewPolicy(DefaultRules)
+	if err != nil {
+		t.Fatalf("NewPolicy() error = %v", err)
+	}
+
+	now := time.Date(2026, time.October, 17, 12, 0, 0, 0, time.UTC)
+	nextDay := time.Date(2026, time.October, 18, 0, 0, 0, 0, time.UTC)
+	nextMonth := time.Date(2026, time.November, 1, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name          string
+		tier          user.Tier
+		amount        int64
+		used          Usage
+		wantPeriod    Period
+		wantLimit     int64
+		wantRemaining int64
+		wantResetsAt  time.Time
+	}{
+		{"unverified at single limit", user.TierUnverified, 5_000_000, Usage{}, "", 0, 0, time.Time{}},
+		{"unverified over single limit", user.TierUnverified, 5_000_001, Usage{}, PeriodSingle, 5_000_000, 5_000_000, time.Time{}},
+		{"unverified at daily limit", user.TierUnverified, 1_000_000, Usage{Daily: 4_000_000, Monthly: 4_000_000}, "", 0, 0, time.Time{}},
+		{"unverified over daily limit", user.TierUnverified, 1_000_001, Usage{Daily: 4_000_000, Monthly: 4_000_000}, PeriodDaily, 5_000_000, 1_000_000, nextDay},
+		{"unverified at monthly limit", user.TierUnverified, 1_000_000, Usage{Monthly: 29_000_000}, "", 0, 0, time.Time{}},
+		{"unverified over monthly limit", user.TierUnverified, 1_000_001, Usage{Monthly: 29_000_000}, PeriodMonthly, 30_000_000, 1_000_000, nextMonth},
+		{"basic at single limit", user.TierBasic, 20_000_000, Usage{}, "", 0, 0, time.Time{}},
+		{"basic over single limit", user.TierBasic, 20_000_001, Usage{}, PeriodSingle, 20_000_000, 20_000_000, time.Time{}},
+		{"basic at daily limit", user.TierBasic, 20_000_000, Usage{Daily: 30_000_000, Monthly: 30_000_000}, "", 0, 0, time.Time{}},
+		{"basic over daily limit", user.TierBasic, 20_000_000, Usage{Daily: 30_000_001, Monthly: 30_000_001}, PeriodDaily, 50_000_000, 19_999_999, nextDay},
+		{"basic at monthly limit", user.TierBasic, 20_000_000, Usage{Monthly: 480_000_000}, "", 0, 0, time.Time{}},
+		{"basic over monthly limit", user.TierBasic, 20_000_000, Usage{Monthly: 480_000_001}, PeriodMonthly, 500_000_000, 19_999_999, nextMonth},
+		{"full at single limit", user.TierFull, 500_000_000, Usage{}, "", 0, 0, time.Time{}},
+		{"full over single limit", user.TierFull, 500_000_001, Usage{}, PeriodSingle, 500_000_000, 500_000_000, time.Time{}},
+		{"full at daily limit", user.TierFull, 500_000_000, Usage{Daily: 2_000_000_000}, "", 0, 0, time.Time{}},
+		{"full over daily limit", user.TierFull, 500_000_000, Usage{Daily: 2_000_000_001}, PeriodDaily, 2_500_000_000, 499_999_999, nextDay},
+		{"full has no monthly limit", user.TierFull, 500_000_000, Usage{Monthly: 100_000_000_000}, "", 0, 0, time.Time{}},
+		{"single limit reported first", user.TierUnverified, 6_000_000, Usage{Daily: 5_000_000, Monthly: 30_000_000}, PeriodSingle, 5_000_000, 0, time.Time{}},
+		{"unknown tier", user.Tier(7), 100_000_000_000, Usage{Daily: 100_000_000_000}, "", 0, 0, time.Time{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := policy.Check(tt.tier, fee.OperationTransfer, money.New(tt.amount, money.NGN), tt.used, now)
+			if tt.wantPeriod == "" {
+				if err != nil {
+					t.Fatalf("Check() error = %v, want nil", err)
+				}
+				return
+			}
+
+			var exceeded *ExceededError
+			if !errors.As(err, &exceeded) {
+				t.Fatalf("Check() error = %v, want an *ExceededError", err)
+			}
+			if !errors.Is(err, ErrLimitExceeded) {
+				t.Errorf("Check() error does not match ErrLimitExceeded")
+			}
+			if exceeded.Period != tt.wantPeriod {
+				t.Errorf("period = %s, want %s", exceeded.Period, tt.wantPeriod)
+			}
+			if exceeded.Limit != money.New(tt.wantLimit, money.NGN) {
+				t.Errorf("limit = %s, want %d", exceeded.Limit, tt.wantLimit)
+			}
+			if exceeded.Remaining != money.New(tt.wantRemaining, money.NGN) {
+				t.Errorf("remaining = %s, want %d", exceeded.Remaining, tt.wantRemaining)
+			}
+
+			switch {
+			case tt.wantResetsAt.IsZero() && exceeded.ResetsAt != nil:
+				t.Errorf("resets at %s, want nil", exceeded.ResetsAt)
+			case !tt.wantResetsAt.IsZero() && (exceeded.ResetsAt == nil || !exceeded.ResetsAt.Equal(tt.wantResetsAt)):
+				t.Errorf("resets at %v, want %s", exceeded.ResetsAt, tt.wantResetsAt)
+			}
+		})
+	}
+}
+
+func TestLimitsPerOperation(t *testing.T) {
+	policy, err := NewPolicy([]Rule{
+		{Tier: user.TierBasic, Operation: fee.OperationWithdrawal, Currency: money.NGN, Limits: Limits{Single: 1000}},
+		{Tier: user.TierBasic, Currency: money.NGN, Limits: Limits{Single: 5000}},
+	})
+	if err != nil {
+		t.Fatalf("NewPolicy() error = %v", err)
+	}
+
+	tests := []struct {
+		name     string
+		tier     user.Tier
+		op       fee.Operation
+		currency money.Currency
+		want     Limits
+	}{
+		{"operation rule", user.TierBasic, fee.OperationWithdrawal, money.NGN, Limits{Single: 1000}},
+		{"rule for every operation", user.TierBasic, fee.OperationTransfer, money.NGN, Limits{Single: 5000}},
+		{"other currency", user.TierBasic, fee.OperationTransfer, money.USD, Limits{}},
+		{"other tier", user.TierFull, fee.OperationTransfer, money.NGN, Limits{}},
+		{"unknown tier", user.Tier(-1), fee.OperationTransfer, money.NGN, Limits{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := policy.Limits(tt.tier, tt.op, tt.currency); got != tt.want {
+				t.Errorf("Limits() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRemaining(t *testing.T) {
+	limits := Limits{Single: 100, Daily: 500, Monthly: 1000}
+
+	tests := []struct {
+		name   string
+		limits Limits
+		used   Usage
+		want   int64
+		wantOK bool
+	}{
+		{"single is the tightest", limits, Usage{}, 100, true},
+		{"daily is the tightest", limits, Usage{Daily: 450, Monthly: 450}, 50, true},
+		{"monthly is the tightest", limits, Usage{Daily: 0, Monthly: 980}, 20, true},
+		{"daily used up", limits, Usage{Daily: 500, Monthly: 500}, 0, true},
+		{"daily overdrawn", limits, Usage{Daily: 600, Monthly: 600}, 0, true},
+		{"only a daily limit", Limits{Daily: 500}, Usage{Daily: 200}, 300, true},
+		{"no limits", Limits{}, Usage{Daily: 1_000_000}, -1, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := tt.limits.Remaining(tt.used)
+			if got != tt.want || ok != tt.wantOK {
+				t.Errorf("Remaining(%+v) = %d, %v, want %d, %v", tt.used, got, ok, tt.want, tt.wantOK)
+			}
+		})
+	}
+}
+
+func TestNewPolicyValidation(t *testing.T) {
+	tests := []struct {
+		name string
+		rule Rule
+	}{
+		{"unknown tier", Rule{Tier: user.Tier(3), Currency: money.NGN}},
+		{"negative tier", Rule{Tier: user.Tier(-1), Currency: money.NGN}},
+		{"unknown operation", Rule{Tier: user.TierBasic, Operation: "refund", Currency: money.NGN}},
+		{"unsupported currency", Rule{Tier: user.TierBasic, Currency: "XYZ"}},
+		{"missing currency", Rule{Tier: user.TierBasic}},
+		{"negative limit", Rule{Tier: user.TierBasic, Currency: money.NGN, Limits: Limits{Daily: -1}}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := NewPolicy([]Rule{tt.rule}); !errors.Is(err, ErrInvalidRule) {
+				t.Errorf("NewPolicy() error = %v, want %v", err, ErrInvalidRule)
+			}
+		})
+	}
+}
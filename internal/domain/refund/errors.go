@@ -0,0 +1,8 @@
+package refund
+
+import "errors"
+
+var (
+	ErrNotFound      = errors.New("refund not found")
+	ErrMissingCharge = errors.New("refund has no transaction reference")
+)
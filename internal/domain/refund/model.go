@@ -0,0 +1,33 @@
+package refund
+
+import "time"
+
+// Refund tracks a Paystack refund of a deposit charge
+type Refund struct {
+	ID               string `json:"id"`
+	PaystackID       string `json:"paystack_id"`
+	DepositReference string `json:"deposit_reference"`
+	WalletID         string `json:"wallet_id,omitempty"`
+	// TransactionReference is the wallet refund holding the funds, empty for
+	// refunds started from the Paystack dashboard
+	TransactionReference string    `json:"transaction_reference,omitempty"`
+	Amount               int64     `json:"amount"`
+	Currency             string    `json:"currency"`
+	Status               Status    `json:"status"`
+	CreatedAt            time.Time `json:"created_at"`
+	UpdatedAt            time.Time `json:"updated_at"`
+}
+
+type Status string
+
+const (
+	StatusPending    Status = "pending"
+	StatusProcessing Status = "processing"
+	StatusProcessed  Status = "processed"
+	StatusFailed     Status = "failed"
+)
+
+// Final reports whether Paystack will send no further updates
+func (s Status) Final() bool {
+	return s == StatusProcessed || s == StatusFailed
+}
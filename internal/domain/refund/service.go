@@ -0,0 +1,119 @@
+package refund
+
+import (
+	"time"
+
+	"github.com/BerylCAtieno/paystack-wallet/internal/domain/wallet"
+	"github.com/BerylCAtieno/paystack-wallet/internal/security"
+)
+
+type Repository interface {
+	Create(r *Refund) error
+	GetByPaystackID(paystackID string) (*Refund, error)
+	Update(r *Refund) error
+}
+
+type DepositLookup interface {
+	GetByReference(reference string) (*wallet.Transaction, error)
+	// GetUnlinkedRefund returns a pending wallet refund of amount against
+	// the deposit that is not linked to a Paystack refund yet
+	GetUnlinkedRefund(depositReference string, amount int64) (*wallet.Transaction, error)
+}
+
+type Service struct {
+	repo     Repository
+	deposits DepositLookup
+}
+
+func NewService(repo Repository, deposits DepositLookup) *Service {
+	return &Service{repo: repo, deposits: deposits}
+}
+
+// Attach links a refund requested from a wallet to the Paystack refund it
+// created. The refund's webhook may already have been recorded, in which
+// case the stored refund is linked and returned with the latest status
+// Paystack reported, which may already be final.
+func (s *Service) Attach(paystackID, depositReference, transactionReference, walletID string, amount int64, currency string, status Status) (*Refund, error) {
+	link := func(existing *Refund) (*Refund, error) {
+		existing.TransactionReference = transactionReference
+		existing.WalletID = walletID
+		existing.UpdatedAt = time.Now()
+		return existing, s.repo.Update(existing)
+	}
+
+	if existing, err := s.repo.GetByPaystackID(paystackID); err == nil {
+		return link(existing)
+	}
+
+	now := time.Now()
+	r := &Refund{
+		ID:                   security.GenerateID(),
+		PaystackID:           paystackID,
+		DepositReference:     depositReference,
+		WalletID:             walletID,
+		TransactionReference: transactionReference,
+		Amount:               amount,
+		Currency:             currency,
+		Status:               status,
+		CreatedAt:            now,
+		UpdatedAt:            now,
+	}
+
+	if err := s.repo.Create(r); err != nil {
+		// A webhook for the refund may have been recorded in the meantime
+		if existing, getErr := s.repo.GetByPaystackID(paystackID); getErr == nil {
+			return link(existing)
+		}
+		return nil, err
+	}
+	return r, nil
+}
+
+// RecordEvent stores the latest state Paystack reported for a refund. Updates
+// that arrive after the refund reached a final status are ignored, since
+// Paystack does not guarantee delivery order.
+func (s *Service) RecordEvent(paystackID, depositReference string, amount int64, currency string, status Status) (*Refund, error) {
+	if depositReference == "" {
+		return nil, ErrMissingCharge
+	}
+
+	now := time.Now()
+	existing, err := s.repo.GetByPaystackID(paystackID)
+	if err == nil {
+		if existing.Status.Final() || existing.Status == status {
+			return existing, nil
+		}
+		existing.Status = status
+		existing.UpdatedAt = now
+		return existing, s.repo.Update(existing)
+	}
+
+	r := &Refund{
+		ID:               security.GenerateID(),
+		PaystackID:       paystackID,
+		DepositReference: depositReference,
+		Amount:           amount,
+		Currency:         currency,
+		Status:           status,
+		CreatedAt:        now,
+		UpdatedAt:        now,
+	}
+
+	// Refunds can also be started from the Paystack dashboard for charges
+	// we never saw, so a missing deposit is not an error
+	if deposit, err := s.deposits.GetByReference(depositReference); err == nil {
+		r.WalletID = deposit.WalletID
+	}
+
+	// A wallet refund whose request to Paystack timed out never learned its
+	// Paystack ID, so it is matched on the deposit and amount instead
+	if pending, err := s.deposits.GetUnlinkedRefund(depositReference, amount); err == nil {
+		r.WalletID = pending.WalletID
+		r.TransactionReference = pending.Reference
+	}
+
+	if err := s.repo.Create(r); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
@@ -3,10 +3,30 @@ package user
 import "time"
 
 type User struct {
-	ID        string    `json:"id"`
-	Email     string    `json:"email"`
-	Name      string    `json:"name"`
-	GoogleID  string    `json:"google_id"`
+	ID       string `json:"id"`
+	Email    string `json:"email"`
+	Name     string `json:"name"`
+	GoogleID string `json:"google_id"`
+	// Tier is how far the user's identity has been verified, which sets
+	// their transaction limits
+	Tier      Tier      `json:"kyc_tier"`
 	CreatedAt time.Time `json:"created_at"`
 	UpdatedAt time.Time `json:"updated_at"`
 }
+
+// Tier is a KYC level. Users sign up unverified and are moved up by an
+// admin once their documents are checked.
+type Tier int
+
+const (
+	// TierUnverified is a Google sign-in with no further checks
+	TierUnverified Tier = 0
+	// TierBasic has a verified phone number and BVN
+	TierBasic Tier = 1
+	// TierFull has a verified ID document and address
+	TierFull Tier = 2
+)
+
+func (t Tier) Valid() bool {
+	return t >= TierUnverified && t <= TierFull
+}
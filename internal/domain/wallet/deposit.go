@@ -0,0 +1,324 @@
+package wallet
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/BerylCAtieno/paystack-wallet/internal/domain/ledger"
+)
+
+// DepositPayment is what Paystack reports for a successful deposit charge
+type DepositPayment struct {
+	PaystackID    string
+	Amount        int64
+	Currency      string
+	CustomerEmail string
+	Channel       string
+	PaidAt        string
+}
+
+// SettleDeposit applies a successful Paystack charge to its pending deposit.
+// The wallet is credited with what was actually paid less the deposit fee,
+// which may be less than requested. Overpayments, foreign currencies and
+// charges paid by a different customer are moved to review instead of
+// being credited. Deposits that were already settled are returned
+// unchanged, so webhooks, verification and the sweeper can all settle the
+// same deposit safely.
+func (s *Service) SettleDeposit(reference string, payment DepositPayment) (*Transaction, error) {
+	tx, err := s.transactionRepo.GetByReference(reference)
+	if err != nil {
+		return nil, err
+	}
+	if tx.Type != TransactionTypeDeposit {
+		return nil, ErrTransactionNotFound
+	}
+
+	switch tx.Status {
+	case TransactionStatusPending:
+	case TransactionStatusFailed, TransactionStatusExpired:
+		// The customer paid after we gave up on the deposit; the money
+		// arrived, so someone has to decide what happens to it
+	default:
+		return tx, nil // Already settled (idempotency)
+	}
+
+	details, err := tx.DepositDetails()
+	if err != nil {
+		return nil, err
+	}
+	details.PaidAmount = payment.Amount
+	details.PaidCurrency = payment.Currency
+	details.PaystackID = payment.PaystackID
+	details.Channel = payment.Channel
+	details.PaidAt = payment.PaidAt
+
+	reason := reviewReason(tx, details, payment)
+	if tx.Status != TransactionStatusPending {
+		reason = fmt.Sprintf("paid after the deposit was marked %s", tx.Status)
+	}
+	if reason != "" {
+		details.ReviewReason = reason
+		log.Printf("Deposit %s moved to review: %s", tx.Reference, reason)
+		return tx, s.moveDepositToReview(tx, details)
+	}
+
+	if credited := payment.Amount - feeAmount(details.Fee); credited < tx.Amount {
+		details.RequestedAmount = tx.Amount
+		tx.Amount = credited
+	}
+
+	return tx, s.creditDeposit(tx, TransactionStatusPending, details)
+}
+
+func reviewReason(tx *Transaction, details *DepositDetails, payment DepositPayment) string {
+	charged := tx.Amount + feeAmount(details.Fee)
+
+	switch {
+	case !strings.EqualFold(payment.Currency, string(tx.Currency)):
+		return fmt.Sprintf("paid in %s, wallet holds %s", payment.Currency, tx.Currency)
+	case payment.Amount <= 0:
+		return "no amount was paid"
+	case payment.Amount <= feeAmount(details.Fee):
+		return fmt.Sprintf("paid %d, which does not cover the %d fee", payment.Amount, feeAmount(details.Fee))
+	case payment.Amount > charged:
+		return fmt.Sprintf("paid %d, more than the %d requested", payment.Amount, charged)
+	case details.CustomerEmail != "" && !strings.EqualFold(details.CustomerEmail, payment.CustomerEmail):
+		return fmt.Sprintf("paid by %s, deposit was started for %s", payment.CustomerEmail, details.CustomerEmail)
+	}
+	return ""
+}
+
+// FailDeposit marks a pending deposit as failed when Paystack reports the
+// charge did not go through. Nothing was credited, so no entry is posted.
+func (s *Service) FailDeposit(reference string) error {
+	return s.closeDeposit(reference, TransactionStatusFailed)
+}
+
+// ExpireDeposit gives up on a pending deposit that was never paid. A payment
+// that still arrives later is moved to review by SettleDeposit.
+func (s *Service) ExpireDeposit(reference string) error {
+	return s.closeDeposit(reference, TransactionStatusExpired)
+}
+
+// ListStaleDeposits returns deposits still pending after olderThan, oldest
+// first, continuing after the cursor when one is given.
+func (s *Service) ListStaleDeposits(olderThan time.Duration, after *TransactionCursor, limit int) ([]*Transaction, error) {
+	return s.transactionRepo.ListByStatus(TransactionTypeDeposit, TransactionStatusPending, time.Now().Add(-olderThan), after, limit)
+}
+
+func (s *Service) closeDeposit(reference string, to TransactionStatus) error {
+	tx, err := s.transactionRepo.GetByReference(reference)
+	if err != nil {
+		return err
+	}
+
+	if tx.Type != TransactionTypeDeposit {
+		return ErrTransactionNotFound
+	}
+
+	if tx.Status != TransactionStatusPending {
+		return nil // Already settled (idempotency)
+	}
+
+	uow, err := s.walletRepo.BeginTx()
+	if err != nil {
+		return err
+	}
+	defer uow.Rollback()
+
+	if _, err := uow.UpdateTransactionStatus(tx.ID, TransactionStatusPending, to); err != nil {
+		return err
+	}
+
+	return uow.Commit()
+}
+
+// ApproveDeposit credits a deposit in review with the amount that was
+// actually paid less the deposit fee.
+func (s *Service) ApproveDeposit(reference string) (*Transaction, error) {
+	tx, details, err := s.getDepositInReview(reference)
+	if err != nil {
+		return nil, err
+	}
+
+	if !strings.EqualFold(details.PaidCurrency, string(tx.Currency)) {
+		return nil, ErrCurrencyMismatch
+	}
+	credited := details.PaidAmount - feeAmount(details.Fee)
+	if credited <= 0 {
+		return nil, ErrInvalidAmount
+	}
+
+	if credited != tx.Amount {
+		details.RequestedAmount = tx.Amount
+		tx.Amount = credited
+	}
+
+	if err := s.creditDeposit(tx, TransactionStatusReview, details); err != nil {
+		return nil, err
+	}
+	tx.Status = TransactionStatusSuccess
+	return tx, nil
+}
+
+// RejectDeposit fails a deposit in review without crediting the wallet.
+// Returning the payment to the customer is handled in Paystack.
+func (s *Service) RejectDeposit(reference string) (*Transaction, error) {
+	tx, _, err := s.getDepositInReview(reference)
+	if err != nil {
+		return nil, err
+	}
+
+	uow, err := s.walletRepo.BeginTx()
+	if err != nil {
+		return nil, err
+	}
+	defer uow.Rollback()
+
+	updated, err := uow.UpdateTransactionStatus(tx.ID, TransactionStatusReview, TransactionStatusFailed)
+	if err != nil {
+		return nil, err
+	}
+	if !updated {
+		return nil, ErrNotInReview
+	}
+
+	if err := uow.Commit(); err != nil {
+		return nil, err
+	}
+	tx.Status = TransactionStatusFailed
+	return tx, nil
+}
+
+func (s *Service) ListDepositsInReview(limit int) ([]*Transaction, error) {
+	if limit <= 0 {
+		limit = DefaultPageSize
+	}
+	if limit > MaxPageSize {
+		limit = MaxPageSize
+	}
+	return s.transactionRepo.ListByStatus(TransactionTypeDeposit, TransactionStatusReview, time.Now(), nil, limit)
+}
+
+// GetDeposit returns a deposit of any wallet by reference
+func (s *Service) GetDeposit(reference string) (*Transaction, error) {
+	tx, err := s.transactionRepo.GetByReference(reference)
+	if err != nil || tx.Type != TransactionTypeDeposit {
+		return nil, ErrTransactionNotFound
+	}
+	return tx, nil
+}
+
+func (s *Service) getDepositInReview(reference string) (*Transaction, *DepositDetails, error) {
+	tx, err := s.transactionRepo.GetByReference(reference)
+	if err != nil || tx.Type != TransactionTypeDeposit {
+		return nil, nil, ErrTransactionNotFound
+	}
+	if tx.Status != TransactionStatusReview {
+		return nil, nil, ErrNotInReview
+	}
+
+	details, err := tx.DepositDetails()
+	if err != nil {
+		return nil, nil, err
+	}
+	return tx, details, nil
+}
+
+// creditDeposit moves a deposit from the given status to success and posts
+// the credit, with the fee collected alongside it going to the fee account.
+// Only the caller that wins the status change credits the wallet.
+func (s *Service) creditDeposit(tx *Transaction, from TransactionStatus, details *DepositDetails) error {
+	metadata, err := json.Marshal(details)
+	if err != nil {
+		return err
+	}
+
+	uow, err := s.walletRepo.BeginTx()
+	if err != nil {
+		return err
+	}
+	defer uow.Rollback()
+
+	updated, err := uow.UpdateTransactionStatus(tx.ID, from, TransactionStatusSuccess)
+	if err != nil {
+		return err
+	}
+	if !updated {
+		return nil
+	}
+
+	if err := uow.UpdateTransactionDetails(tx.ID, tx.Amount, string(metadata)); err != nil {
+		return err
+	}
+
+	charge := feeAmount(details.Fee)
+	entry := ledger.NewEntry(tx.Reference, "Paystack deposit").
+		Debit(ledger.SystemAccountID(ledger.SystemPaystackSettlement, string(tx.Currency)), tx.Amount+charge).
+		Credit(ledger.WalletAccountID(tx.WalletID), tx.Amount)
+	if charge > 0 {
+		entry.Credit(ledger.SystemAccountID(ledger.SystemFees, string(tx.Currency)), charge)
+	}
+
+	if err := uow.PostEntry(entry); err != nil {
+		return err
+	}
+
+	if err := uow.LinkJournalEntry(tx.ID, entry.ID); err != nil {
+		return err
+	}
+
+	if err := uow.Commit(); err != nil {
+		return err
+	}
+	tx.Status = TransactionStatusSuccess
+	tx.Metadata = string(metadata)
+	return nil
+}
+
+func (s *Service) moveDepositToReview(tx *Transaction, details *DepositDetails) error {
+	metadata, err := json.Marshal(details)
+	if err != nil {
+		return err
+	}
+
+	uow, err := s.walletRepo.BeginTx()
+	if err != nil {
+		return err
+	}
+	defer uow.Rollback()
+
+	updated, err := uow.UpdateTransactionStatus(tx.ID, tx.Status, TransactionStatusReview)
+	if err != nil {
+		return err
+	}
+	if !updated {
+		return nil
+	}
+
+	if err := uow.UpdateTransactionDetails(tx.ID, tx.Amount, string(metadata)); err != nil {
+		return err
+	}
+
+	if err := uow.Commit(); err != nil {
+		return err
+	}
+	tx.Status = TransactionStatusReview
+	tx.Metadata = string(metadata)
+	return nil
+}
+
+// DepositDetails decodes the Paystack payment details stored on a deposit
+func (t *Transaction) DepositDetails() (*DepositDetails, error) {
+	details := &DepositDetails{}
+	if t.Metadata == "" {
+		return details, nil
+	}
+	if err := json.Unmarshal([]byte(t.Metadata), details); err != nil {
+		return nil, err
+	}
+	return details, nil
+}
@@ -8,4 +8,49 @@ var (
 	ErrInvalidAmount       = errors.New("invalid amount")
 	ErrTransactionNotFound = errors.New("transaction not found")
 	ErrDuplicateReference  = errors.New("duplicate transaction reference")
+	ErrSelfTransfer        = errors.New("cannot transfer to own wallet")
+	ErrWalletFrozen        = errors.New("wallet is frozen")
+	// ErrDuplicateWalletNumber is returned when a generated wallet number
+	// collides with an existing wallet
+	ErrDuplicateWalletNumber = errors.New("duplicate wallet number")
+	ErrInvalidWalletNumber   = errors.New("invalid wallet number")
+	ErrInvalidCursor         = errors.New("invalid cursor")
+	ErrInvalidFilter         = errors.New("invalid transaction filter")
+	ErrNotInReview           = errors.New("deposit is not awaiting review")
+	ErrWalletExists          = errors.New("user already has a wallet in this currency")
+	// ErrCurrencyMismatch is returned when approving a deposit that was paid
+	// in a currency the wallet cannot hold
+	ErrCurrencyMismatch = errors.New("deposit was paid in a different currency")
+	// ErrWrongCurrency is returned when an amount is not in the currency of
+	// the wallet it is applied to
+	ErrWrongCurrency = errors.New("amount is not in the wallet's currency")
+	// ErrCrossCurrencyTransfer is returned when the recipient wallet holds a
+	// different currency and the caller did not ask for a conversion
+	ErrCrossCurrencyTransfer = errors.New("recipient wallet holds a different currency; request a conversion to transfer")
+	// ErrQuoteUnavailable is returned when a conversion's quote expired or
+	// was already used before the money moved
+	ErrQuoteUnavailable = errors.New("quote has expired or was already used")
+	// ErrQuoteMismatch is returned when a conversion's quote does not cover
+	// the amount or currencies of the wallets it is applied to
+	ErrQuoteMismatch = errors.New("quote does not match the amount or wallets")
+	// ErrRefundExceedsDeposit is returned when a refund would return more
+	// than what is left of the deposit after earlier refunds
+	ErrRefundExceedsDeposit = errors.New("refund exceeds the refundable amount of the deposit")
+	ErrNotRefundable        = errors.New("only successful deposits can be refunded")
+	ErrHoldNotFound         = errors.New("hold not found")
+	ErrHoldNotActive        = errors.New("hold is no longer active")
+	ErrHoldExpired          = errors.New("hold has expired")
+	ErrInvalidHoldExpiry    = errors.New("hold expiry must be between 1 second and 30 days")
+	// ErrHoldReferenceConflict is returned when a hold reference is used
+	// again with a different amount, recipient or expiry
+	ErrHoldReferenceConflict = errors.New("hold reference was already used with different parameters")
+	// ErrDuplicateHoldReference is returned when a hold is created with a
+	// reference another hold on the wallet already has
+	ErrDuplicateHoldReference = errors.New("duplicate hold reference")
+	// ErrHoldNotCapturable is returned for holds reserving a withdrawal or
+	// refund, which only Paystack settles
+	ErrHoldNotCapturable = errors.New("hold is settled by Paystack and cannot be captured or released")
+	// ErrNotPending is returned when a withdrawal or refund was settled
+	// before the change to it could be stored
+	ErrNotPending = errors.New("transaction is no longer pending")
 )
@@ -0,0 +1,148 @@
+package wallet
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/BerylCAtieno/paystack-wallet/internal/domain/ledger"
+	"github.com/BerylCAtieno/paystack-wallet/internal/identifier"
+	"github.com/BerylCAtieno/paystack-wallet/internal/security"
+)
+
+// Exchange converts money between two of the same user's wallets at the
+// conversion's quoted rate and returns the debit from the source wallet.
+func (s *Service) Exchange(fromWalletID, toWalletID string, conversion Conversion) (*Transaction, error) {
+	if !conversion.Source.IsPositive() || !conversion.Target.IsPositive() {
+		return nil, ErrInvalidAmount
+	}
+
+	from, err := s.walletRepo.GetByID(fromWalletID)
+	if err != nil {
+		return nil, ErrWalletNotFound
+	}
+	to, err := s.walletRepo.GetByID(toWalletID)
+	if err != nil || to.UserID != from.UserID {
+		return nil, ErrWalletNotFound
+	}
+	if from.ID == to.ID {
+		return nil, ErrSelfTransfer
+	}
+	if from.Status == WalletStatusFrozen || to.Status == WalletStatusFrozen {
+		return nil, ErrWalletFrozen
+	}
+
+	uow, err := s.walletRepo.BeginTx()
+	if err != nil {
+		return nil, err
+	}
+	defer uow.Rollback()
+
+	debitTx, err := postConversion(uow, from, to, conversion, conversionLegs{
+		prefix:      identifier.PrefixExchange,
+		description: "Currency exchange",
+		debitType:   TransactionTypeExchange,
+		creditType:  TransactionTypeExchange,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if err := uow.Commit(); err != nil {
+		return nil, err
+	}
+	return debitTx, nil
+}
+
+// conversionLegs names the transactions a conversion records on each side
+type conversionLegs struct {
+	prefix      string
+	description string
+	debitType   TransactionType
+	creditType  TransactionType
+}
+
+// postConversion uses the conversion's quote and records money moving from
+// sender to recipient in different currencies in uow. The source currency is
+// sold into its FX clearing account less the spread, which goes to FX
+// revenue, and the target currency is bought out of its clearing account,
+// so the entry balances in each currency. It returns the sender's
+// transaction.
+func postConversion(uow TransactionInterface, sender, recipient *Wallet, conversion Conversion, legs conversionLegs) (*Transaction, error) {
+	if conversion.Source.Currency != sender.Currency || conversion.Target.Currency != recipient.Currency ||
+		conversion.Spread.Currency != sender.Currency {
+		return nil, ErrQuoteMismatch
+	}
+
+	// Spend the quote first so it moves money at most once
+	consumed, err := uow.ConsumeQuote(conversion.QuoteID)
+	if err != nil {
+		return nil, err
+	}
+	if !consumed {
+		return nil, ErrQuoteUnavailable
+	}
+
+	metadata, err := json.Marshal(conversion)
+	if err != nil {
+		return nil, err
+	}
+
+	reference := identifier.NewReference(legs.prefix)
+	now := time.Now()
+
+	source, spread, target := conversion.Source.Amount, conversion.Spread.Amount, conversion.Target.Amount
+	sourceCurrency, targetCurrency := string(sender.Currency), string(recipient.Currency)
+
+	entry := ledger.NewEntry(reference, legs.description).
+		Debit(ledger.WalletAccountID(sender.ID), source).
+		Credit(ledger.SystemAccountID(ledger.SystemFXClearing, sourceCurrency), source-spread)
+	if spread > 0 {
+		entry.Credit(ledger.SystemAccountID(ledger.SystemFXRevenue, sourceCurrency), spread)
+	}
+	entry.Debit(ledger.SystemAccountID(ledger.SystemFXClearing, targetCurrency), target).
+		Credit(ledger.WalletAccountID(recipient.ID), target)
+
+	debitTx := &Transaction{
+		ID:              security.GenerateID(),
+		WalletID:        sender.ID,
+		Type:            legs.debitType,
+		Amount:          -source,
+		Currency:        sender.Currency,
+		Status:          TransactionStatusSuccess,
+		Reference:       reference,
+		RecipientWallet: recipient.WalletNumber,
+		Metadata:        string(metadata),
+		JournalEntryID:  entry.ID,
+		CreatedAt:       now,
+		UpdatedAt:       now,
+	}
+
+	creditTx := &Transaction{
+		ID:              security.GenerateID(),
+		WalletID:        recipient.ID,
+		Type:            legs.creditType,
+		Amount:          target,
+		Currency:        recipient.Currency,
+		Status:          TransactionStatusSuccess,
+		Reference:       reference + "_CR",
+		RecipientWallet: sender.WalletNumber,
+		Metadata:        string(metadata),
+		JournalEntryID:  entry.ID,
+		CreatedAt:       now,
+		UpdatedAt:       now,
+	}
+
+	if err := uow.PostEntry(entry); err != nil {
+		return nil, err
+	}
+
+	if err := uow.CreateTransaction(debitTx); err != nil {
+		return nil, err
+	}
+
+	if err := uow.CreateTransaction(creditTx); err != nil {
+		return nil, err
+	}
+
+	return debitTx, nil
+}
@@ -0,0 +1,88 @@
+package wallet
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/BerylCAtieno/paystack-wallet/internal/domain/fee"
+	"github.com/BerylCAtieno/paystack-wallet/internal/domain/ledger"
+	"github.com/BerylCAtieno/paystack-wallet/internal/domain/user"
+	"github.com/BerylCAtieno/paystack-wallet/internal/money"
+	"github.com/BerylCAtieno/paystack-wallet/internal/security"
+)
+
+// QuoteFee previews the fee an operation on the wallet would be charged.
+// amount must be in the wallet's currency.
+func (s *Service) QuoteFee(walletID string, op fee.Operation, amount money.Money) (*fee.Breakdown, error) {
+	if !amount.IsPositive() {
+		return nil, ErrInvalidAmount
+	}
+
+	w, err := s.walletRepo.GetByID(walletID)
+	if err != nil {
+		return nil, ErrWalletNotFound
+	}
+	if amount.Currency != w.Currency {
+		return nil, ErrWrongCurrency
+	}
+
+	tier, err := s.tier(w)
+	if err != nil {
+		return nil, err
+	}
+
+	breakdown := s.price(tier, op, amount)
+	return &breakdown, nil
+}
+
+// price works out the fee for a user of tier
+func (s *Service) price(tier user.Tier, op fee.Operation, amount money.Money) fee.Breakdown {
+	return s.fees.Calculate(fee.Request{Operation: op, Amount: amount, Tier: int(tier)})
+}
+
+// chargeFee debits a fee from the wallet to the fee account in uow and
+// records it as a fee transaction against reference. Zero fees are skipped.
+func chargeFee(uow TransactionInterface, w *Wallet, breakdown *fee.Breakdown, reference string) error {
+	amount := feeAmount(breakdown)
+	if amount == 0 {
+		return nil
+	}
+
+	metadata, err := json.Marshal(FeeDetails{Reference: reference, Breakdown: *breakdown})
+	if err != nil {
+		return err
+	}
+
+	feeReference := reference + "_FEE"
+	entry := ledger.NewEntry(feeReference, "Fee").
+		Debit(ledger.WalletAccountID(w.ID), amount).
+		Credit(ledger.SystemAccountID(ledger.SystemFees, string(w.Currency)), amount)
+
+	if err := uow.PostEntry(entry); err != nil {
+		return err
+	}
+
+	now := time.Now()
+	return uow.CreateTransaction(&Transaction{
+		ID:             security.GenerateID(),
+		WalletID:       w.ID,
+		Type:           TransactionTypeFee,
+		Amount:         -amount,
+		Currency:       w.Currency,
+		Status:         TransactionStatusSuccess,
+		Reference:      feeReference,
+		Metadata:       string(metadata),
+		JournalEntryID: entry.ID,
+		CreatedAt:      now,
+		UpdatedAt:      now,
+	})
+}
+
+// feeAmount returns the fee of a breakdown stored on a transaction, zero
+// for transactions created before fees were charged
+func feeAmount(breakdown *fee.Breakdown) int64 {
+	if breakdown == nil {
+		return 0
+	}
+	return breakdown.Fee.Amount
+}
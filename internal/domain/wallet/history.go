@@ -0,0 +1,83 @@
+package wallet
+
+import (
+	"encoding/base64"
+	"strings"
+	"time"
+)
+
+const (
+	DefaultPageSize = 20
+	MaxPageSize     = 100
+)
+
+// TransactionFilter narrows a wallet's transaction history. Zero values
+// leave the corresponding field unconstrained.
+type TransactionFilter struct {
+	Type   TransactionType
+	Status TransactionStatus
+	From   time.Time
+	To     time.Time
+	// MinAmount and MaxAmount bound the absolute amount in the minor unit,
+	// so debits and credits are filtered alike
+	MinAmount int64
+	MaxAmount int64
+	// Counterparty is the wallet number of the other side of a transfer
+	Counterparty string
+}
+
+func (f TransactionFilter) Validate() error {
+	if f.Type != "" && !f.Type.Valid() {
+		return ErrInvalidFilter
+	}
+	if f.Status != "" && !f.Status.Valid() {
+		return ErrInvalidFilter
+	}
+	if f.MinAmount < 0 || f.MaxAmount < 0 {
+		return ErrInvalidFilter
+	}
+	if f.MaxAmount > 0 && f.MinAmount > f.MaxAmount {
+		return ErrInvalidFilter
+	}
+	if !f.From.IsZero() && !f.To.IsZero() && f.From.After(f.To) {
+		return ErrInvalidFilter
+	}
+	return nil
+}
+
+// TransactionCursor marks the last transaction of a page ordered by
+// (created_at, id); the next page starts strictly after it. Wallet history is
+// paged newest first, admin and background scans oldest first.
+type TransactionCursor struct {
+	CreatedAt time.Time
+	ID        string
+}
+
+func (c TransactionCursor) Encode() string {
+	raw := c.CreatedAt.Format(time.RFC3339Nano) + "|" + c.ID
+	return base64.RawURLEncoding.EncodeToString([]byte(raw))
+}
+
+func DecodeTransactionCursor(s string) (*TransactionCursor, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return nil, ErrInvalidCursor
+	}
+
+	createdAt, id, ok := strings.Cut(string(raw), "|")
+	if !ok || id == "" {
+		return nil, ErrInvalidCursor
+	}
+
+	t, err := time.Parse(time.RFC3339Nano, createdAt)
+	if err != nil {
+		return nil, ErrInvalidCursor
+	}
+
+	return &TransactionCursor{CreatedAt: t, ID: id}, nil
+}
+
+type TransactionPage struct {
+	Transactions []*Transaction `json:"transactions"`
+	NextCursor   string         `json:"next_cursor,omitempty"`
+}
@@ -0,0 +1,523 @@
+package wallet
+
+import (
+	"encoding/json"
+	"errors"
+	"log"
+	"time"
+
+	"github.com/BerylCAtieno/paystack-wallet/internal/domain/fee"
+	"github.com/BerylCAtieno/paystack-wallet/internal/domain/ledger"
+	"github.com/BerylCAtieno/paystack-wallet/internal/money"
+	"github.com/BerylCAtieno/paystack-wallet/internal/security"
+)
+
+// Hold reserves part of a wallet's balance. Held funds stay in the ledger
+// balance but cannot be spent until the hold is captured, released or
+// expires.
+type Hold struct {
+	ID       string `json:"id"`
+	WalletID string `json:"wallet_id"`
+	Amount   int64  `json:"amount"`
+	// CapturedAmount is what was taken when the hold was captured; the rest
+	// of the hold was returned to the available balance
+	CapturedAmount int64  `json:"captured_amount,omitempty"`
+	Reason         string `json:"reason,omitempty"`
+	// Reference links the hold to what it reserves funds for: a withdrawal
+	// or refund reference, or the caller's own reference for authorizations
+	Reference string `json:"reference,omitempty"`
+	// RecipientWallet is the wallet number an authorization pays on capture
+	RecipientWallet string     `json:"recipient_wallet,omitempty"`
+	Status          HoldStatus `json:"status"`
+	// ExpiresAt is nil for holds that only close when Paystack settles
+	ExpiresAt *time.Time `json:"expires_at,omitempty"`
+	CreatedAt time.Time  `json:"created_at"`
+	UpdatedAt time.Time  `json:"updated_at"`
+}
+
+type HoldStatus string
+
+const (
+	HoldStatusActive   HoldStatus = "active"
+	HoldStatusCaptured HoldStatus = "captured"
+	HoldStatusReleased HoldStatus = "released"
+	HoldStatusExpired  HoldStatus = "expired"
+)
+
+func (s HoldStatus) Valid() bool {
+	switch s {
+	case HoldStatusActive, HoldStatusCaptured, HoldStatusReleased, HoldStatusExpired:
+		return true
+	}
+	return false
+}
+
+// Balance splits a wallet's balance into what the ledger holds and what can
+// still be spent
+type Balance struct {
+	Currency         money.Currency `json:"currency"`
+	LedgerBalance    int64          `json:"ledger_balance"`
+	HeldAmount       int64          `json:"held_amount"`
+	AvailableBalance int64          `json:"available_balance"`
+}
+
+// TransferDetails is stored as the metadata of transfers that capture a hold
+type TransferDetails struct {
+	HoldID        string `json:"hold_id,omitempty"`
+	HoldReference string `json:"hold_reference,omitempty"`
+}
+
+// HoldRequest describes an authorization placed with PlaceHold
+type HoldRequest struct {
+	Amount          int64
+	Reason          string
+	Reference       string
+	RecipientWallet string
+	TTL             time.Duration
+}
+
+// MaxHoldTTL bounds how long an authorization can reserve funds
+const MaxHoldTTL = 30 * 24 * time.Hour
+
+// PlaceHold reserves funds for a payment to another wallet that will be
+// captured later, such as a merchant authorization. The hold expires after
+// req.TTL unless it is captured or released first. Placing a hold with a
+// reference the wallet already used returns the existing hold if the amount,
+// recipient and expiry match, and ErrHoldReferenceConflict otherwise.
+func (s *Service) PlaceHold(walletID string, req HoldRequest) (*Hold, error) {
+	if req.Amount <= 0 {
+		return nil, ErrInvalidAmount
+	}
+	if req.TTL <= 0 || req.TTL > MaxHoldTTL {
+		return nil, ErrInvalidHoldExpiry
+	}
+
+	if req.Reference != "" {
+		if existing, err := s.holdRepo.GetByReference(walletID, req.Reference); err == nil {
+			return existing.retried(req)
+		}
+	}
+
+	w, err := s.walletRepo.GetByID(walletID)
+	if err != nil {
+		return nil, ErrWalletNotFound
+	}
+
+	recipient, err := s.walletRepo.GetByWalletNumber(req.RecipientWallet)
+	if err != nil {
+		return nil, ErrWalletNotFound
+	}
+	if recipient.ID == w.ID {
+		return nil, ErrSelfTransfer
+	}
+	if recipient.Currency != w.Currency {
+		return nil, ErrCrossCurrencyTransfer
+	}
+	if w.Status == WalletStatusFrozen || recipient.Status == WalletStatusFrozen {
+		return nil, ErrWalletFrozen
+	}
+
+	now := time.Now()
+	expiresAt := now.Add(req.TTL)
+	hold := &Hold{
+		ID:              security.GenerateID(),
+		WalletID:        w.ID,
+		Amount:          req.Amount,
+		Reason:          req.Reason,
+		Reference:       req.Reference,
+		RecipientWallet: recipient.WalletNumber,
+		Status:          HoldStatusActive,
+		ExpiresAt:       &expiresAt,
+		CreatedAt:       now,
+		UpdatedAt:       now,
+	}
+
+	uow, err := s.walletRepo.BeginTx()
+	if err != nil {
+		return nil, err
+	}
+	defer uow.Rollback()
+
+	if err := uow.CreateHold(hold); err != nil {
+		if err == ErrDuplicateHoldReference {
+			// A retry with the same reference placed its hold first
+			uow.Rollback()
+			existing, getErr := s.holdRepo.GetByReference(walletID, req.Reference)
+			if getErr != nil {
+				return nil, getErr
+			}
+			return existing.retried(req)
+		}
+		return nil, err
+	}
+
+	if err := uow.Commit(); err != nil {
+		return nil, err
+	}
+	return hold, nil
+}
+
+// retried returns h for a request reusing its reference, or
+// ErrHoldReferenceConflict if the request asks for a different hold
+func (h *Hold) retried(req HoldRequest) (*Hold, error) {
+	if !h.matches(req) {
+		return nil, ErrHoldReferenceConflict
+	}
+	return h, nil
+}
+
+// matches reports whether req asks for the same hold as h. The expiry is
+// compared as the TTL requested, to the second, since a retry is sent some
+// time after the original request.
+func (h *Hold) matches(req HoldRequest) bool {
+	if h.Amount != req.Amount || h.RecipientWallet != req.RecipientWallet || h.ExpiresAt == nil {
+		return false
+	}
+	ttl := h.ExpiresAt.Sub(h.CreatedAt).Round(time.Second)
+	return ttl == req.TTL.Round(time.Second)
+}
+
+// CaptureHold pays up to the held amount to the hold's recipient wallet and
+// returns whatever was not captured to the available balance. A zero amount
+// captures the whole hold. The payment counts as a transfer towards the
+// sender's limits, checked when the hold is captured rather than placed.
+func (s *Service) CaptureHold(walletID, holdID string, amount int64) (*Transaction, error) {
+	hold, err := s.GetHold(walletID, holdID)
+	if err != nil {
+		return nil, err
+	}
+	if err := hold.checkOpen(); err != nil {
+		return nil, err
+	}
+	if hold.RecipientWallet == "" {
+		// Withdrawal and refund holds are captured when Paystack settles
+		return nil, ErrHoldNotCapturable
+	}
+
+	if amount == 0 {
+		amount = hold.Amount
+	}
+	if amount < 0 || amount > hold.Amount {
+		return nil, ErrInvalidAmount
+	}
+
+	sender, err := s.walletRepo.GetByID(hold.WalletID)
+	if err != nil {
+		return nil, ErrWalletNotFound
+	}
+	recipient, err := s.walletRepo.GetByWalletNumber(hold.RecipientWallet)
+	if err != nil {
+		return nil, ErrWalletNotFound
+	}
+	if sender.Status == WalletStatusFrozen || recipient.Status == WalletStatusFrozen {
+		return nil, ErrWalletFrozen
+	}
+
+	tier, err := s.tier(sender)
+	if err != nil {
+		return nil, err
+	}
+
+	metadata, err := json.Marshal(TransferDetails{HoldID: hold.ID, HoldReference: hold.Reference})
+	if err != nil {
+		return nil, err
+	}
+
+	uow, err := s.walletRepo.BeginTx()
+	if err != nil {
+		return nil, err
+	}
+	defer uow.Rollback()
+
+	if err := s.checkLimit(uow, sender, tier, fee.OperationTransfer, amount); err != nil {
+		return nil, err
+	}
+
+	// Close the hold first so the captured funds are no longer reserved
+	// when the transfer debits them
+	closed, err := uow.CloseHold(hold.ID, HoldStatusCaptured, amount)
+	if err != nil {
+		return nil, err
+	}
+	if !closed {
+		return nil, ErrHoldNotActive
+	}
+
+	debitTx, err := postTransfer(uow, sender, recipient, amount, string(metadata))
+	if err != nil {
+		return nil, err
+	}
+
+	if err := uow.Commit(); err != nil {
+		return nil, err
+	}
+	return debitTx, nil
+}
+
+// ReleaseHold returns an authorization's funds to the available balance
+// without paying anything.
+func (s *Service) ReleaseHold(walletID, holdID string) (*Hold, error) {
+	hold, err := s.GetHold(walletID, holdID)
+	if err != nil {
+		return nil, err
+	}
+	if err := hold.checkOpen(); err != nil {
+		return nil, err
+	}
+	if hold.RecipientWallet == "" {
+		// Withdrawal and refund holds are released when Paystack fails them
+		return nil, ErrHoldNotCapturable
+	}
+
+	if err := s.closeHold(hold, HoldStatusReleased); err != nil {
+		return nil, err
+	}
+	return hold, nil
+}
+
+// ExpireHolds releases active holds whose expiry has passed and returns how
+// many were expired.
+func (s *Service) ExpireHolds(limit int) (int, error) {
+	holds, err := s.holdRepo.ListExpired(time.Now(), limit)
+	if err != nil {
+		return 0, err
+	}
+
+	expired := 0
+	for _, hold := range holds {
+		err := s.closeHold(hold, HoldStatusExpired)
+		if err == ErrHoldNotActive {
+			continue // Captured or released in the meantime
+		}
+		if err != nil {
+			return expired, err
+		}
+		expired++
+	}
+	return expired, nil
+}
+
+func (s *Service) GetHold(walletID, holdID string) (*Hold, error) {
+	hold, err := s.holdRepo.GetByID(holdID)
+	if err != nil || hold.WalletID != walletID {
+		return nil, ErrHoldNotFound
+	}
+	return hold, nil
+}
+
+// GetUserHold returns a hold on any of the user's wallets
+func (s *Service) GetUserHold(userID, holdID string) (*Hold, error) {
+	hold, err := s.holdRepo.GetByID(holdID)
+	if err != nil {
+		return nil, ErrHoldNotFound
+	}
+
+	w, err := s.walletRepo.GetByID(hold.WalletID)
+	if err != nil || w.UserID != userID {
+		return nil, ErrHoldNotFound
+	}
+	return hold, nil
+}
+
+// ListHolds returns a wallet's holds, newest first, optionally only those in
+// one status.
+func (s *Service) ListHolds(walletID string, status HoldStatus) ([]*Hold, error) {
+	if status != "" && !status.Valid() {
+		return nil, ErrInvalidFilter
+	}
+	return s.holdRepo.ListByWalletID(walletID, status)
+}
+
+// GetBalance returns the wallet's ledger balance and what is left of it
+// after active holds.
+func (s *Service) GetBalance(walletID string) (*Balance, error) {
+	w, err := s.walletRepo.GetByID(walletID)
+	if err != nil {
+		return nil, ErrWalletNotFound
+	}
+
+	held, err := s.holdRepo.SumActive(walletID, time.Now())
+	if err != nil {
+		return nil, err
+	}
+
+	return &Balance{
+		Currency:         w.Currency,
+		LedgerBalance:    w.Balance,
+		HeldAmount:       held,
+		AvailableBalance: w.Balance - held,
+	}, nil
+}
+
+// placeSettlementHold reserves amount for a withdrawal or refund in the same
+// unit of work that records it; for withdrawals this includes the fee. These
+// holds do not expire; they are captured or released when Paystack reports
+// the outcome.
+func placeSettlementHold(uow TransactionInterface, tx *Transaction, amount int64, reason string) error {
+	hold := &Hold{
+		ID:        security.GenerateID(),
+		WalletID:  tx.WalletID,
+		Amount:    amount,
+		Reason:    reason,
+		Reference: tx.Reference,
+		Status:    HoldStatusActive,
+		CreatedAt: tx.CreatedAt,
+		UpdatedAt: tx.CreatedAt,
+	}
+	return uow.CreateHold(hold)
+}
+
+// captureSettlementHold closes the hold of a withdrawal or refund that
+// Paystack settled and posts the transaction's amount leaving the wallet for
+// the Paystack balance. Any fee held with it is charged by the caller.
+func (s *Service) captureSettlementHold(uow TransactionInterface, tx *Transaction, description string) error {
+	hold, err := s.holdRepo.GetByReference(tx.WalletID, tx.Reference)
+	if err != nil {
+		return err
+	}
+
+	closed, err := uow.CloseHold(hold.ID, HoldStatusCaptured, hold.Amount)
+	if err != nil {
+		return err
+	}
+	if !closed {
+		return ErrHoldNotActive
+	}
+
+	entry := ledger.NewEntry(tx.Reference, description).
+		Debit(ledger.WalletAccountID(tx.WalletID), -tx.Amount).
+		Credit(ledger.SystemAccountID(ledger.SystemPaystackSettlement, string(tx.Currency)), -tx.Amount)
+
+	if err := uow.PostEntry(entry); err != nil {
+		return err
+	}
+
+	return uow.LinkJournalEntry(tx.ID, entry.ID)
+}
+
+// settleFailedPayout handles Paystack paying out a withdrawal or refund
+// that was marked failed and had its hold released, e.g. because its
+// request timed out and the outcome was only learned later. The money has
+// left the Paystack balance, so the wallet is debited after all, with any
+// fee. If the wallet no longer holds enough the transaction moves to review
+// and an alert is logged for an admin to settle it.
+func (s *Service) settleFailedPayout(tx *Transaction, breakdown *fee.Breakdown, description string) error {
+	err := s.debitFailedPayout(tx, breakdown, description)
+	if !errors.Is(err, ErrInsufficientBalance) {
+		return err
+	}
+
+	uow, err := s.walletRepo.BeginTx()
+	if err != nil {
+		return err
+	}
+	defer uow.Rollback()
+
+	updated, err := uow.UpdateTransactionStatus(tx.ID, TransactionStatusFailed, TransactionStatusReview)
+	if err != nil {
+		return err
+	}
+	if !updated {
+		return nil
+	}
+	if err := uow.Commit(); err != nil {
+		return err
+	}
+
+	log.Printf("ALERT: %s %s was paid out by Paystack after it failed and wallet %s cannot cover %d %s; moved to review",
+		tx.Type, tx.Reference, tx.WalletID, -tx.Amount+feeAmount(breakdown), tx.Currency)
+	return nil
+}
+
+func (s *Service) debitFailedPayout(tx *Transaction, breakdown *fee.Breakdown, description string) error {
+	uow, err := s.walletRepo.BeginTx()
+	if err != nil {
+		return err
+	}
+	defer uow.Rollback()
+
+	updated, err := uow.UpdateTransactionStatus(tx.ID, TransactionStatusFailed, TransactionStatusSuccess)
+	if err != nil {
+		return err
+	}
+	if !updated {
+		return nil
+	}
+
+	entry := ledger.NewEntry(tx.Reference, description).
+		Debit(ledger.WalletAccountID(tx.WalletID), -tx.Amount).
+		Credit(ledger.SystemAccountID(ledger.SystemPaystackSettlement, string(tx.Currency)), -tx.Amount)
+
+	if err := uow.PostEntry(entry); err != nil {
+		return err
+	}
+	if err := uow.LinkJournalEntry(tx.ID, entry.ID); err != nil {
+		return err
+	}
+
+	w, err := uow.GetWallet(tx.WalletID)
+	if err != nil {
+		return err
+	}
+	if err := chargeFee(uow, w, breakdown, tx.Reference); err != nil {
+		return err
+	}
+
+	if err := uow.Commit(); err != nil {
+		return err
+	}
+
+	log.Printf("%s %s was paid out by Paystack after it failed; wallet %s debited", tx.Type, tx.Reference, tx.WalletID)
+	return nil
+}
+
+// releaseSettlementHold closes the hold of a withdrawal or refund that
+// Paystack failed, making the funds available again.
+func (s *Service) releaseSettlementHold(uow TransactionInterface, tx *Transaction) error {
+	hold, err := s.holdRepo.GetByReference(tx.WalletID, tx.Reference)
+	if err != nil {
+		return err
+	}
+
+	closed, err := uow.CloseHold(hold.ID, HoldStatusReleased, 0)
+	if err != nil {
+		return err
+	}
+	if !closed {
+		return ErrHoldNotActive
+	}
+	return nil
+}
+
+func (s *Service) closeHold(hold *Hold, to HoldStatus) error {
+	uow, err := s.walletRepo.BeginTx()
+	if err != nil {
+		return err
+	}
+	defer uow.Rollback()
+
+	closed, err := uow.CloseHold(hold.ID, to, 0)
+	if err != nil {
+		return err
+	}
+	if !closed {
+		return ErrHoldNotActive
+	}
+
+	if err := uow.Commit(); err != nil {
+		return err
+	}
+	hold.Status = to
+	return nil
+}
+
+// checkOpen reports why an active-looking hold can no longer be used
+func (h *Hold) checkOpen() error {
+	if h.Status != HoldStatusActive {
+		return ErrHoldNotActive
+	}
+	if h.ExpiresAt != nil && !h.ExpiresAt.After(time.Now()) {
+		return ErrHoldExpired
+	}
+	return nil
+}
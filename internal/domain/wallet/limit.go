@@ -0,0 +1,145 @@
+package wallet
+
+import (
+	"time"
+
+	"github.com/BerylCAtieno/paystack-wallet/internal/domain/fee"
+	"github.com/BerylCAtieno/paystack-wallet/internal/domain/limit"
+	"github.com/BerylCAtieno/paystack-wallet/internal/domain/user"
+	"github.com/BerylCAtieno/paystack-wallet/internal/money"
+)
+
+// LimitStatus shows a wallet owner's limits in the wallet's currency and how
+// much of them is used
+type LimitStatus struct {
+	Tier       user.Tier        `json:"tier"`
+	Currency   money.Currency   `json:"currency"`
+	Operations []OperationLimit `json:"operations"`
+}
+
+// OperationLimit is one operation's limits. Limits and remaining amounts
+// are null when nothing limits them.
+type OperationLimit struct {
+	Operation fee.Operation `json:"operation"`
+	Single    *int64        `json:"single"`
+	Daily     PeriodLimit   `json:"daily"`
+	Monthly   PeriodLimit   `json:"monthly"`
+	// Remaining is the most one transaction can move right now
+	Remaining *int64 `json:"remaining"`
+}
+
+type PeriodLimit struct {
+	Limit     *int64    `json:"limit"`
+	Used      int64     `json:"used"`
+	Remaining *int64    `json:"remaining"`
+	ResetsAt  time.Time `json:"resets_at"`
+}
+
+var limitedOperations = []fee.Operation{fee.OperationDeposit, fee.OperationTransfer, fee.OperationWithdrawal}
+
+// countedType is the type of the transactions that count towards an
+// operation's limits. Transfers count what the wallet sent, not received.
+func countedType(op fee.Operation) TransactionType {
+	switch op {
+	case fee.OperationTransfer:
+		return TransactionTypeTransfer
+	case fee.OperationWithdrawal:
+		return TransactionTypeWithdrawal
+	}
+	return TransactionTypeDeposit
+}
+
+// Limits returns the limits of the wallet's owner in the wallet's currency
+// and how much of each was used this UTC day and month.
+func (s *Service) Limits(walletID string) (*LimitStatus, error) {
+	w, err := s.walletRepo.GetByID(walletID)
+	if err != nil {
+		return nil, ErrWalletNotFound
+	}
+
+	tier, err := s.tier(w)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	day, month := limit.Windows(now)
+
+	status := &LimitStatus{Tier: tier, Currency: w.Currency}
+	for _, op := range limitedOperations {
+		used, err := usage(s.transactionRepo, w.ID, countedType(op), now)
+		if err != nil {
+			return nil, err
+		}
+
+		l := s.limits.Limits(tier, op, w.Currency)
+		ol := OperationLimit{
+			Operation: op,
+			Single:    optionalLimit(l.Single),
+			Daily:     periodLimit(l.Daily, used.Daily, day.AddDate(0, 0, 1)),
+			Monthly:   periodLimit(l.Monthly, used.Monthly, month.AddDate(0, 1, 0)),
+		}
+		if remaining, ok := l.Remaining(used); ok {
+			ol.Remaining = &remaining
+		}
+		status.Operations = append(status.Operations, ol)
+	}
+
+	return status, nil
+}
+
+// tier returns the KYC tier of the wallet's owner
+func (s *Service) tier(w *Wallet) (user.Tier, error) {
+	u, err := s.userRepo.GetByID(w.UserID)
+	if err != nil {
+		return 0, err
+	}
+	return u.Tier, nil
+}
+
+// checkLimit returns a *limit.ExceededError if moving amount would take the
+// wallet over its owner's limits for op. Reading usage through the unit of
+// work that records the operation keeps concurrent requests from both
+// spending the same allowance.
+func (s *Service) checkLimit(r usageReader, w *Wallet, tier user.Tier, op fee.Operation, amount int64) error {
+	now := time.Now()
+	used, err := usage(r, w.ID, countedType(op), now)
+	if err != nil {
+		return err
+	}
+
+	return s.limits.Check(tier, op, money.New(amount, w.Currency), used, now)
+}
+
+// usage sums a wallet's transactions of one type in the current UTC day and
+// month
+func usage(r usageReader, walletID string, txType TransactionType, now time.Time) (limit.Usage, error) {
+	day, month := limit.Windows(now)
+
+	daily, err := r.SumUsage(walletID, txType, day)
+	if err != nil {
+		return limit.Usage{}, err
+	}
+	monthly, err := r.SumUsage(walletID, txType, month)
+	if err != nil {
+		return limit.Usage{}, err
+	}
+
+	return limit.Usage{Daily: daily, Monthly: monthly}, nil
+}
+
+func optionalLimit(amount int64) *int64 {
+	if amount == 0 {
+		return nil
+	}
+	return &amount
+}
+
+func periodLimit(amount, used int64, resetsAt time.Time) PeriodLimit {
+	p := PeriodLimit{Limit: optionalLimit(amount), Used: used, ResetsAt: resetsAt}
+	if p.Limit != nil {
+		remaining := max(amount-used, 0)
+		p.Remaining = &remaining
+	}
+	return p
+}
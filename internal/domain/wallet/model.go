@@ -1,14 +1,21 @@
 package wallet
 
-import "time"
+import (
+	"time"
+
+	"github.com/BerylCAtieno/paystack-wallet/internal/domain/fee"
+	"github.com/BerylCAtieno/paystack-wallet/internal/money"
+)
 
 type Wallet struct {
-	ID           string    `json:"id"`
-	UserID       string    `json:"user_id"`
-	WalletNumber string    `json:"wallet_number"`
-	Balance      int64     `json:"balance"` // in cents
-	CreatedAt    time.Time `json:"created_at"`
-	UpdatedAt    time.Time `json:"updated_at"`
+	ID           string         `json:"id"`
+	UserID       string         `json:"user_id"`
+	WalletNumber string         `json:"wallet_number"`
+	Balance      int64          `json:"balance"` // in the minor unit, cached projection of ledger postings
+	Currency     money.Currency `json:"currency"`
+	Status       WalletStatus   `json:"status"`
+	CreatedAt    time.Time      `json:"created_at"`
+	UpdatedAt    time.Time      `json:"updated_at"`
 }
 
 type Transaction struct {
@@ -16,26 +23,130 @@ type Transaction struct {
 	WalletID        string            `json:"wallet_id"`
 	Type            TransactionType   `json:"type"`
 	Amount          int64             `json:"amount"`
+	Currency        money.Currency    `json:"currency"`
 	Status          TransactionStatus `json:"status"`
 	Reference       string            `json:"reference"`
 	RecipientWallet string            `json:"recipient_wallet,omitempty"`
 	Metadata        string            `json:"metadata,omitempty"`
+	JournalEntryID  string            `json:"journal_entry_id,omitempty"`
 	CreatedAt       time.Time         `json:"created_at"`
 	UpdatedAt       time.Time         `json:"updated_at"`
 }
 
+type WalletStatus string
+
+const (
+	WalletStatusActive WalletStatus = "active"
+	// WalletStatusFrozen blocks transfers and new deposits, e.g. after
+	// reconciliation finds balance drift
+	WalletStatusFrozen WalletStatus = "frozen"
+)
+
 type TransactionType string
 
 const (
-	TransactionTypeDeposit  TransactionType = "deposit"
-	TransactionTypeTransfer TransactionType = "transfer"
-	TransactionTypeReceived TransactionType = "received"
+	TransactionTypeDeposit    TransactionType = "deposit"
+	TransactionTypeTransfer   TransactionType = "transfer"
+	TransactionTypeReceived   TransactionType = "received"
+	TransactionTypeWithdrawal TransactionType = "withdrawal"
+	// TransactionTypeRefund returns part or all of a deposit to the card or
+	// account it was paid from
+	TransactionTypeRefund TransactionType = "refund"
+	// TransactionTypeExchange moves money between two of a user's wallets
+	// in different currencies
+	TransactionTypeExchange TransactionType = "exchange"
+	// TransactionTypeFee is a fee debited from the wallet for a transfer or
+	// withdrawal; its reference is that transaction's with a _FEE suffix
+	TransactionTypeFee TransactionType = "fee"
 )
 
+func (t TransactionType) Valid() bool {
+	switch t {
+	case TransactionTypeDeposit, TransactionTypeTransfer, TransactionTypeReceived, TransactionTypeWithdrawal, TransactionTypeRefund, TransactionTypeExchange, TransactionTypeFee:
+		return true
+	}
+	return false
+}
+
 type TransactionStatus string
 
 const (
 	TransactionStatusPending TransactionStatus = "pending"
 	TransactionStatusSuccess TransactionStatus = "success"
 	TransactionStatusFailed  TransactionStatus = "failed"
+	// TransactionStatusReversed marks a settled payout that Paystack later
+	// returned to the wallet
+	TransactionStatusReversed TransactionStatus = "reversed"
+	// TransactionStatusReview holds a deposit whose payment did not match
+	// what was requested until an admin approves or rejects it, or a
+	// withdrawal or refund Paystack paid out after it had failed that the
+	// wallet could no longer cover
+	TransactionStatusReview TransactionStatus = "review"
+	// TransactionStatusExpired marks a deposit that was never paid within
+	// the expiry window
+	TransactionStatusExpired TransactionStatus = "expired"
 )
+
+func (s TransactionStatus) Valid() bool {
+	switch s {
+	case TransactionStatusPending, TransactionStatusSuccess, TransactionStatusFailed, TransactionStatusReversed, TransactionStatusReview, TransactionStatusExpired:
+		return true
+	}
+	return false
+}
+
+// DepositDetails is stored as the metadata of deposit transactions. The
+// Paystack fields are filled in when the charge settles.
+type DepositDetails struct {
+	CustomerEmail string `json:"customer_email,omitempty"`
+	// RequestedAmount is kept when less than requested was paid and the
+	// transaction amount was lowered to what was actually paid
+	RequestedAmount int64  `json:"requested_amount,omitempty"`
+	PaidAmount      int64  `json:"paid_amount,omitempty"`
+	PaidCurrency    string `json:"paid_currency,omitempty"`
+	PaystackID      string `json:"paystack_id,omitempty"`
+	Channel         string `json:"channel,omitempty"`
+	PaidAt          string `json:"paid_at,omitempty"`
+	ReviewReason    string `json:"review_reason,omitempty"`
+	// Fee is charged on top of the deposit, so Paystack collects the
+	// amount plus the fee and the wallet is credited with the amount
+	Fee *fee.Breakdown `json:"fee,omitempty"`
+}
+
+// WithdrawalDetails is stored as the metadata of withdrawal transactions
+type WithdrawalDetails struct {
+	RecipientCode string `json:"recipient_code"`
+	AccountNumber string `json:"account_number"`
+	AccountName   string `json:"account_name"`
+	BankCode      string `json:"bank_code"`
+	TransferCode  string `json:"transfer_code,omitempty"`
+	Reason        string `json:"reason,omitempty"`
+	// Fee is held with the withdrawal and charged when it settles
+	Fee *fee.Breakdown `json:"fee,omitempty"`
+}
+
+// FeeDetails is stored as the metadata of fee transactions
+type FeeDetails struct {
+	// Reference is the transaction the fee was charged on
+	Reference string `json:"reference"`
+	fee.Breakdown
+}
+
+// RefundDetails is stored as the metadata of refund transactions
+type RefundDetails struct {
+	DepositReference string `json:"deposit_reference"`
+	PaystackID       string `json:"paystack_id,omitempty"`
+	Reason           string `json:"reason,omitempty"`
+}
+
+// Conversion is a locked exchange rate applied to money moving between
+// wallets in different currencies. Source leaves the sending wallet, Spread
+// is the part of it kept as revenue and Target reaches the receiving wallet.
+// It is stored as the metadata of exchanges and converted transfers.
+type Conversion struct {
+	QuoteID string      `json:"quote_id"`
+	Source  money.Money `json:"source"`
+	Spread  money.Money `json:"spread"`
+	Target  money.Money `json:"target"`
+	Rate    float64     `json:"rate"`
+}
@@ -0,0 +1,206 @@
+package wallet
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/BerylCAtieno/paystack-wallet/internal/identifier"
+	"github.com/BerylCAtieno/paystack-wallet/internal/security"
+)
+
+// RefundDeposit holds amount of a successful deposit for a refund to the
+// card or account it was paid from, so the funds cannot be spent while
+// Paystack processes the refund. A zero amount refunds whatever is left of
+// the deposit. Refunds never exceed the wallet's available balance or the
+// deposit less earlier refunds that have not failed.
+func (s *Service) RefundDeposit(walletID, depositReference string, amount int64, reason string) (*Transaction, error) {
+	if amount < 0 {
+		return nil, ErrInvalidAmount
+	}
+
+	deposit, err := s.GetTransaction(walletID, depositReference)
+	if err != nil || deposit.Type != TransactionTypeDeposit {
+		return nil, ErrTransactionNotFound
+	}
+	if deposit.Status != TransactionStatusSuccess {
+		return nil, ErrNotRefundable
+	}
+
+	w, err := s.walletRepo.GetByID(walletID)
+	if err != nil {
+		return nil, ErrWalletNotFound
+	}
+	if w.Status == WalletStatusFrozen {
+		return nil, ErrWalletFrozen
+	}
+
+	metadata, err := json.Marshal(RefundDetails{
+		DepositReference: deposit.Reference,
+		Reason:           reason,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	uow, err := s.walletRepo.BeginTx()
+	if err != nil {
+		return nil, err
+	}
+	defer uow.Rollback()
+
+	// Read inside the unit of work so concurrent refunds of the same deposit
+	// cannot both pass the check
+	refunded, err := uow.RefundedAmount(deposit.Reference)
+	if err != nil {
+		return nil, err
+	}
+
+	refundable := deposit.Amount - refunded
+	if amount == 0 {
+		amount = refundable
+	}
+	if amount <= 0 || amount > refundable {
+		return nil, ErrRefundExceedsDeposit
+	}
+
+	now := time.Now()
+	tx := &Transaction{
+		ID:        security.GenerateID(),
+		WalletID:  w.ID,
+		Type:      TransactionTypeRefund,
+		Amount:    -amount,
+		Currency:  w.Currency,
+		Status:    TransactionStatusPending,
+		Reference: identifier.NewReference(identifier.PrefixRefund),
+		Metadata:  string(metadata),
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+
+	if err := placeSettlementHold(uow, tx, amount, "refund"); err != nil {
+		return nil, err
+	}
+
+	if err := uow.CreateTransaction(tx); err != nil {
+		return nil, err
+	}
+
+	if err := uow.Commit(); err != nil {
+		return nil, err
+	}
+
+	return tx, nil
+}
+
+// AttachRefundID records the Paystack refund ID on a refund transaction
+// that is still pending. The refund webhook may settle it first, in which
+// case ErrNotPending is returned and its status is left alone.
+func (s *Service) AttachRefundID(reference, paystackID string) error {
+	tx, err := s.GetRefund(reference)
+	if err != nil {
+		return err
+	}
+	if tx.Status != TransactionStatusPending {
+		return ErrNotPending
+	}
+
+	details, err := tx.RefundDetails()
+	if err != nil {
+		return err
+	}
+	details.PaystackID = paystackID
+
+	metadata, err := json.Marshal(details)
+	if err != nil {
+		return err
+	}
+
+	updated, err := s.transactionRepo.UpdateMetadata(tx.ID, TransactionStatusPending, string(metadata))
+	if err != nil {
+		return err
+	}
+	if !updated {
+		return ErrNotPending
+	}
+	return nil
+}
+
+// CompleteRefund settles a pending refund once Paystack has processed it,
+// capturing its hold against the settlement balance the money left from.
+// A refund that had already failed was paid out all the same, so it is
+// debited after all; see settleFailedPayout.
+func (s *Service) CompleteRefund(reference string) error {
+	return s.closeRefund(reference, TransactionStatusSuccess)
+}
+
+// FailRefund releases the held funds when Paystack could not process the
+// refund or it was never accepted.
+func (s *Service) FailRefund(reference string) error {
+	return s.closeRefund(reference, TransactionStatusFailed)
+}
+
+// closeRefund moves a pending refund to its final status and captures or
+// releases its hold. Only the caller that wins the status change touches
+// the hold.
+func (s *Service) closeRefund(reference string, to TransactionStatus) error {
+	tx, err := s.GetRefund(reference)
+	if err != nil {
+		return err
+	}
+
+	switch {
+	case tx.Status == TransactionStatusPending:
+	case tx.Status == TransactionStatusFailed && to == TransactionStatusSuccess:
+		return s.settleFailedPayout(tx, nil, "Refund processed after failing")
+	default:
+		return nil // Already settled (idempotency)
+	}
+
+	uow, err := s.walletRepo.BeginTx()
+	if err != nil {
+		return err
+	}
+	defer uow.Rollback()
+
+	updated, err := uow.UpdateTransactionStatus(tx.ID, TransactionStatusPending, to)
+	if err != nil {
+		return err
+	}
+	if !updated {
+		return nil
+	}
+
+	if to == TransactionStatusSuccess {
+		err = s.captureSettlementHold(uow, tx, "Refund processed")
+	} else {
+		err = s.releaseSettlementHold(uow, tx)
+	}
+	if err != nil {
+		return err
+	}
+
+	return uow.Commit()
+}
+
+func (s *Service) GetRefund(reference string) (*Transaction, error) {
+	tx, err := s.transactionRepo.GetByReference(reference)
+	if err != nil {
+		return nil, err
+	}
+	if tx.Type != TransactionTypeRefund {
+		return nil, ErrTransactionNotFound
+	}
+	return tx, nil
+}
+
+// RefundDetails decodes the deposit and Paystack refund stored on a refund
+func (t *Transaction) RefundDetails() (*RefundDetails, error) {
+	details := &RefundDetails{}
+	if t.Metadata == "" {
+		return details, nil
+	}
+	if err := json.Unmarshal([]byte(t.Metadata), details); err != nil {
+		return nil, err
+	}
+	return details, nil
+}
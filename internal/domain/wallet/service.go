@@ -1,142 +1,350 @@
 package wallet
 
 import (
-	"fmt"
+	"encoding/json"
 	"time"
 
+	"github.com/BerylCAtieno/paystack-wallet/internal/domain/fee"
+	"github.com/BerylCAtieno/paystack-wallet/internal/domain/ledger"
+	"github.com/BerylCAtieno/paystack-wallet/internal/domain/limit"
+	"github.com/BerylCAtieno/paystack-wallet/internal/domain/user"
+	"github.com/BerylCAtieno/paystack-wallet/internal/identifier"
+	"github.com/BerylCAtieno/paystack-wallet/internal/money"
 	"github.com/BerylCAtieno/paystack-wallet/internal/security"
 )
 
 type Service struct {
 	walletRepo      WalletRepository
 	transactionRepo TransactionRepository
+	holdRepo        HoldRepository
+	userRepo        UserRepository
+	fees            *fee.Engine
+	limits          *limit.Policy
 }
 
-func NewService(walletRepo WalletRepository, transactionRepo TransactionRepository) *Service {
+func NewService(walletRepo WalletRepository, transactionRepo TransactionRepository, holdRepo HoldRepository, userRepo UserRepository, fees *fee.Engine, limits *limit.Policy) *Service {
 	return &Service{
 		walletRepo:      walletRepo,
 		transactionRepo: transactionRepo,
+		holdRepo:        holdRepo,
+		userRepo:        userRepo,
+		fees:            fees,
+		limits:          limits,
 	}
 }
 
 type WalletRepository interface {
 	Create(wallet *Wallet) error
-	GetByUserID(userID string) (*Wallet, error)
+	GetByID(id string) (*Wallet, error)
+	// GetByUserID returns the user's wallet in one currency
+	GetByUserID(userID string, currency money.Currency) (*Wallet, error)
+	// ListByUserID returns all of a user's wallets, oldest first
+	ListByUserID(userID string) ([]*Wallet, error)
 	GetByWalletNumber(walletNumber string) (*Wallet, error)
-	UpdateBalance(walletID string, amount int64) error
-	BeginTx() (Transaction, error)
+	BeginTx() (TransactionInterface, error)
 }
 
 type TransactionRepository interface {
 	Create(tx *Transaction) error
 	GetByReference(reference string) (*Transaction, error)
-	Update(tx *Transaction) error
-	ListByWalletID(walletID string) ([]*Transaction, error)
+	// UpdateMetadata rewrites the metadata of a transaction still in the
+	// given status and reports whether it was
+	UpdateMetadata(id string, status TransactionStatus, metadata string) (bool, error)
+	// ListByWalletID returns up to limit transactions matching filter,
+	// newest first, starting after the cursor when one is given.
+	ListByWalletID(walletID string, filter TransactionFilter, after *TransactionCursor, limit int) ([]*Transaction, error)
+	// ListByStatus returns transactions of one type and status across all
+	// wallets created before the given time, oldest first, starting after
+	// the cursor when one is given.
+	ListByStatus(txType TransactionType, status TransactionStatus, createdBefore time.Time, after *TransactionCursor, limit int) ([]*Transaction, error)
+	usageReader
 }
 
+// usageReader sums what a wallet has moved for its limits, either directly
+// or inside a unit of work
+type usageReader interface {
+	// SumUsage adds up the absolute amounts of a wallet's transactions of
+	// one type created since a time that are pending, in review or
+	// successful
+	SumUsage(walletID string, txType TransactionType, since time.Time) (int64, error)
+}
+
+// UserRepository looks up wallet owners for their KYC tier
+type UserRepository interface {
+	GetByID(id string) (*user.User, error)
+}
+
+// HoldRepository reads holds; holds are created and closed through a
+// TransactionInterface so they change together with balances.
+type HoldRepository interface {
+	GetByID(id string) (*Hold, error)
+	GetByReference(walletID, reference string) (*Hold, error)
+	// ListByWalletID returns a wallet's holds newest first, all of them when
+	// status is empty
+	ListByWalletID(walletID string, status HoldStatus) ([]*Hold, error)
+	// SumActive returns the amount reserved by a wallet's active holds that
+	// have not expired by now
+	SumActive(walletID string, now time.Time) (int64, error)
+	// ListExpired returns up to limit active holds that expired before now
+	ListExpired(now time.Time, limit int) ([]*Hold, error)
+}
+
+// TransactionInterface is a unit of work spanning the ledger, wallet balances
+// and the transactions table. Nothing is persisted until Commit is called.
 type TransactionInterface interface {
 	Commit() error
 	Rollback() error
+	// UpdateWalletBalance overwrites the cached balance; it is only meant
+	// for rebuilding the projection from postings.
 	UpdateWalletBalance(walletID string, newBalance int64) error
 	GetWallet(walletID string) (*Wallet, error)
+	// PostEntry records a balanced journal entry and updates the cached
+	// balance of every wallet it touches, returning ErrInsufficientBalance
+	// if a debit exceeds a wallet's available balance.
+	PostEntry(entry *ledger.JournalEntry) error
+	CreateTransaction(tx *Transaction) error
+	LinkJournalEntry(transactionID, entryID string) error
+	// UpdateTransactionStatus moves a transaction from one status to another
+	// and reports whether it was still in the expected status.
+	UpdateTransactionStatus(id string, from, to TransactionStatus) (bool, error)
+	// UpdateTransactionDetails rewrites a transaction's amount and metadata
+	UpdateTransactionDetails(id string, amount int64, metadata string) error
+	// RefundedAmount sums the refunds of a deposit that are pending or
+	// processed
+	RefundedAmount(depositReference string) (int64, error)
+	// CreateHold reserves funds, returning ErrInsufficientBalance if the
+	// wallet's available balance does not cover the hold
+	CreateHold(hold *Hold) error
+	// CloseHold moves an active hold to a final status and reports whether
+	// it was still active
+	CloseHold(id string, to HoldStatus, capturedAmount int64) (bool, error)
+	// ConsumeQuote marks an open, unexpired FX quote as used and reports
+	// whether it was still usable
+	ConsumeQuote(id string) (bool, error)
+	usageReader
 }
 
-func (s *Service) CreateWallet(userID string) (*Wallet, error) {
-	walletNumber := generateWalletNumber()
-	wallet := &Wallet{
-		ID:           security.GenerateID(),
-		UserID:       userID,
-		WalletNumber: walletNumber,
-		Balance:      0,
-		CreatedAt:    time.Now(),
-		UpdatedAt:    time.Now(),
+// maxWalletNumberAttempts bounds retries when a generated wallet number is
+// already taken
+const maxWalletNumberAttempts = 5
+
+// CreateWallet opens a wallet for the user in currency. A user holds at most
+// one wallet per currency; ErrWalletExists is returned for a second one.
+func (s *Service) CreateWallet(userID string, currency money.Currency) (*Wallet, error) {
+	if !currency.Valid() {
+		return nil, money.ErrUnsupportedCurrency
 	}
 
-	if err := s.walletRepo.Create(wallet); err != nil {
-		return nil, err
+	for attempt := 0; attempt < maxWalletNumberAttempts; attempt++ {
+		wallet := &Wallet{
+			ID:           security.GenerateID(),
+			UserID:       userID,
+			WalletNumber: identifier.NewWalletNumber(),
+			Balance:      0,
+			Currency:     currency,
+			Status:       WalletStatusActive,
+			CreatedAt:    time.Now(),
+			UpdatedAt:    time.Now(),
+		}
+
+		err := s.walletRepo.Create(wallet)
+		if err == ErrDuplicateWalletNumber {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		return wallet, nil
 	}
 
-	return wallet, nil
+	return nil, ErrDuplicateWalletNumber
 }
 
-func (s *Service) GetOrCreateWallet(userID string) (*Wallet, error) {
-	wallet, err := s.walletRepo.GetByUserID(userID)
+// GetOrCreateWallet returns the user's wallet in currency, opening it first
+// if the user does not have one yet.
+func (s *Service) GetOrCreateWallet(userID string, currency money.Currency) (*Wallet, error) {
+	wallet, err := s.walletRepo.GetByUserID(userID, currency)
 	if err == nil {
 		return wallet, nil
 	}
 
-	return s.CreateWallet(userID)
+	wallet, err = s.CreateWallet(userID, currency)
+	if err == ErrWalletExists {
+		// Opened by a concurrent request
+		return s.walletRepo.GetByUserID(userID, currency)
+	}
+	return wallet, err
+}
+
+func (s *Service) ListWallets(userID string) ([]*Wallet, error) {
+	wallets, err := s.walletRepo.ListByUserID(userID)
+	if err != nil {
+		return nil, err
+	}
+	if wallets == nil {
+		wallets = []*Wallet{}
+	}
+	return wallets, nil
 }
 
-func (s *Service) InitiateDeposit(walletID string, amount int64, reference string) (*Transaction, error) {
-	if amount <= 0 {
+// InitiateDeposit records a pending deposit and prices its fee, which is
+// collected on top of amount. amount must be in the wallet's currency and
+// within the owner's deposit limits; deposits count towards them until they
+// fail or expire.
+// customerEmail is the email the Paystack charge is initialized with,
+// checked again when it settles.
+func (s *Service) InitiateDeposit(walletID string, amount money.Money, reference, customerEmail string) (*Transaction, error) {
+	if !amount.IsPositive() {
 		return nil, ErrInvalidAmount
 	}
 
+	w, err := s.walletRepo.GetByID(walletID)
+	if err != nil {
+		return nil, ErrWalletNotFound
+	}
+	if amount.Currency != w.Currency {
+		return nil, ErrWrongCurrency
+	}
+	if w.Status == WalletStatusFrozen {
+		return nil, ErrWalletFrozen
+	}
+
+	tier, err := s.tier(w)
+	if err != nil {
+		return nil, err
+	}
+
+	breakdown := s.price(tier, fee.OperationDeposit, amount)
+	metadata, err := json.Marshal(DepositDetails{CustomerEmail: customerEmail, Fee: &breakdown})
+	if err != nil {
+		return nil, err
+	}
+
 	tx := &Transaction{
 		ID:        security.GenerateID(),
 		WalletID:  walletID,
 		Type:      TransactionTypeDeposit,
-		Amount:    amount,
+		Amount:    amount.Amount,
+		Currency:  w.Currency,
 		Status:    TransactionStatusPending,
 		Reference: reference,
+		Metadata:  string(metadata),
 		CreatedAt: time.Now(),
 		UpdatedAt: time.Now(),
 	}
 
-	if err := s.transactionRepo.Create(tx); err != nil {
+	uow, err := s.walletRepo.BeginTx()
+	if err != nil {
+		return nil, err
+	}
+	defer uow.Rollback()
+
+	if err := s.checkLimit(uow, w, tier, fee.OperationDeposit, amount.Amount); err != nil {
+		return nil, err
+	}
+
+	if err := uow.CreateTransaction(tx); err != nil {
+		return nil, err
+	}
+
+	if err := uow.Commit(); err != nil {
 		return nil, err
 	}
 
 	return tx, nil
 }
 
-func (s *Service) CompleteDeposit(reference string) error {
-	tx, err := s.transactionRepo.GetByReference(reference)
-	if err != nil {
-		return err
+// Transfer moves amount from the sender's available balance to another
+// wallet and charges the sender the transfer fee on top, returning the fee.
+// amount must be in the sender's currency and within the sender's transfer
+// limits. Transfers to a wallet in another currency need a conversion whose
+// quote covers amount and pays out in the recipient's currency.
+func (s *Service) Transfer(senderWalletID, recipientWalletNumber string, amount money.Money, conversion *Conversion) (*fee.Breakdown, error) {
+	if !amount.IsPositive() {
+		return nil, ErrInvalidAmount
 	}
 
-	if tx.Status == TransactionStatusSuccess {
-		return nil // Already processed (idempotency)
+	senderWallet, err := s.walletRepo.GetByID(senderWalletID)
+	if err != nil {
+		return nil, ErrWalletNotFound
+	}
+	if amount.Currency != senderWallet.Currency {
+		return nil, ErrWrongCurrency
 	}
 
-	// Update transaction status
-	tx.Status = TransactionStatusSuccess
-	tx.UpdatedAt = time.Now()
+	recipientWallet, err := s.walletRepo.GetByWalletNumber(recipientWalletNumber)
+	if err != nil {
+		return nil, ErrWalletNotFound
+	}
 
-	if err := s.transactionRepo.Update(tx); err != nil {
-		return err
+	if recipientWallet.ID == senderWallet.ID {
+		return nil, ErrSelfTransfer
 	}
 
-	// Update wallet balance
-	if err := s.walletRepo.UpdateBalance(tx.WalletID, tx.Amount); err != nil {
-		return err
+	if senderWallet.Status == WalletStatusFrozen || recipientWallet.Status == WalletStatusFrozen {
+		return nil, ErrWalletFrozen
 	}
 
-	return nil
-}
+	converted := recipientWallet.Currency != senderWallet.Currency
+	if converted && conversion == nil {
+		return nil, ErrCrossCurrencyTransfer
+	}
+	if conversion != nil && (!converted || conversion.Source != amount) {
+		return nil, ErrQuoteMismatch
+	}
 
-func (s *Service) Transfer(senderWalletID, recipientWalletNumber string, amount int64) error {
-	if amount <= 0 {
-		return ErrInvalidAmount
+	tier, err := s.tier(senderWallet)
+	if err != nil {
+		return nil, err
 	}
 
-	// Get sender wallet
-	senderWallet, err := s.walletRepo.GetByUserID("") // We'll fix this
+	breakdown := s.price(tier, fee.OperationTransfer, amount)
+
+	uow, err := s.walletRepo.BeginTx()
 	if err != nil {
-		return err
+		return nil, err
 	}
+	defer uow.Rollback()
 
-	if senderWallet.Balance < amount {
-		return ErrInsufficientBalance
+	if err := s.checkLimit(uow, senderWallet, tier, fee.OperationTransfer, amount.Amount); err != nil {
+		return nil, err
 	}
 
-	// Get recipient wallet
-	recipientWallet, err := s.walletRepo.GetByWalletNumber(recipientWalletNumber)
+	var debitTx *Transaction
+	if converted {
+		debitTx, err = postConversion(uow, senderWallet, recipientWallet, *conversion, conversionLegs{
+			prefix:      identifier.PrefixTransfer,
+			description: "Wallet transfer with conversion",
+			debitType:   TransactionTypeTransfer,
+			creditType:  TransactionTypeReceived,
+		})
+	} else {
+		debitTx, err = postTransfer(uow, senderWallet, recipientWallet, amount.Amount, "")
+	}
 	if err != nil {
-		return ErrWalletNotFound
+		return nil, err
+	}
+
+	if err := chargeFee(uow, senderWallet, &breakdown, debitTx.Reference); err != nil {
+		return nil, err
+	}
+
+	if err := uow.Commit(); err != nil {
+		return nil, err
 	}
+	return &breakdown, nil
+}
+
+// postTransfer records a transfer between two wallets of the same currency
+// in uow and returns the sender's transaction.
+func postTransfer(uow TransactionInterface, senderWallet, recipientWallet *Wallet, amount int64, metadata string) (*Transaction, error) {
+	reference := identifier.NewReference(identifier.PrefixTransfer)
+	now := time.Now()
+
+	entry := ledger.NewEntry(reference, "Wallet transfer").
+		Debit(ledger.WalletAccountID(senderWallet.ID), amount).
+		Credit(ledger.WalletAccountID(recipientWallet.ID), amount)
 
 	// Create debit transaction for sender
 	debitTx := &Transaction{
@@ -144,11 +352,14 @@ func (s *Service) Transfer(senderWalletID, recipientWalletNumber string, amount
 		WalletID:        senderWallet.ID,
 		Type:            TransactionTypeTransfer,
 		Amount:          -amount,
+		Currency:        senderWallet.Currency,
 		Status:          TransactionStatusSuccess,
-		Reference:       generateReference(),
-		RecipientWallet: recipientWalletNumber,
-		CreatedAt:       time.Now(),
-		UpdatedAt:       time.Now(),
+		Reference:       reference,
+		RecipientWallet: recipientWallet.WalletNumber,
+		Metadata:        metadata,
+		JournalEntryID:  entry.ID,
+		CreatedAt:       now,
+		UpdatedAt:       now,
 	}
 
 	// Create credit transaction for recipient
@@ -157,50 +368,96 @@ func (s *Service) Transfer(senderWalletID, recipientWalletNumber string, amount
 		WalletID:        recipientWallet.ID,
 		Type:            TransactionTypeReceived,
 		Amount:          amount,
+		Currency:        recipientWallet.Currency,
 		Status:          TransactionStatusSuccess,
-		Reference:       debitTx.Reference,
+		Reference:       reference + "_CR",
 		RecipientWallet: senderWallet.WalletNumber,
-		CreatedAt:       time.Now(),
-		UpdatedAt:       time.Now(),
+		Metadata:        metadata,
+		JournalEntryID:  entry.ID,
+		CreatedAt:       now,
+		UpdatedAt:       now,
 	}
 
-	// Update balances
-	if err := s.walletRepo.UpdateBalance(senderWallet.ID, -amount); err != nil {
-		return err
+	// Posting re-checks the sender's available balance inside the
+	// transaction so concurrent transfers cannot overdraw it or spend held
+	// funds
+	if err := uow.PostEntry(entry); err != nil {
+		return nil, err
 	}
 
-	if err := s.walletRepo.UpdateBalance(recipientWallet.ID, amount); err != nil {
-		return err
+	if err := uow.CreateTransaction(debitTx); err != nil {
+		return nil, err
 	}
 
-	// Save transactions
-	if err := s.transactionRepo.Create(debitTx); err != nil {
-		return err
+	if err := uow.CreateTransaction(creditTx); err != nil {
+		return nil, err
 	}
 
-	if err := s.transactionRepo.Create(creditTx); err != nil {
-		return err
-	}
+	return debitTx, nil
+}
 
-	return nil
+// GetTransaction returns a wallet's transaction by reference. Transactions
+// belonging to other wallets are reported as not found.
+func (s *Service) GetTransaction(walletID, reference string) (*Transaction, error) {
+	tx, err := s.transactionRepo.GetByReference(reference)
+	if err != nil || tx.WalletID != walletID {
+		return nil, ErrTransactionNotFound
+	}
+	return tx, nil
 }
 
-func (s *Service) GetBalance(userID string) (int64, error) {
-	wallet, err := s.walletRepo.GetByUserID(userID)
+// GetUserTransaction returns a transaction by reference from any of the
+// user's wallets. Transactions of other users are reported as not found.
+func (s *Service) GetUserTransaction(userID, reference string) (*Transaction, error) {
+	tx, err := s.transactionRepo.GetByReference(reference)
 	if err != nil {
-		return 0, err
+		return nil, ErrTransactionNotFound
 	}
-	return wallet.Balance, nil
-}
 
-func (s *Service) GetTransactions(walletID string) ([]*Transaction, error) {
-	return s.transactionRepo.ListByWalletID(walletID)
+	w, err := s.walletRepo.GetByID(tx.WalletID)
+	if err != nil || w.UserID != userID {
+		return nil, ErrTransactionNotFound
+	}
+	return tx, nil
 }
 
-func generateWalletNumber() string {
-	return fmt.Sprintf("%013d", time.Now().UnixNano()%10000000000000)
-}
+// GetTransactions returns one page of a wallet's history. cursor is the
+// next_cursor of the previous page, or empty for the first page.
+func (s *Service) GetTransactions(walletID string, filter TransactionFilter, cursor string, limit int) (*TransactionPage, error) {
+	if err := filter.Validate(); err != nil {
+		return nil, err
+	}
+
+	var after *TransactionCursor
+	if cursor != "" {
+		var err error
+		if after, err = DecodeTransactionCursor(cursor); err != nil {
+			return nil, err
+		}
+	}
+
+	if limit <= 0 {
+		limit = DefaultPageSize
+	}
+	if limit > MaxPageSize {
+		limit = MaxPageSize
+	}
+
+	// Fetch one extra row to learn whether another page exists
+	transactions, err := s.transactionRepo.ListByWalletID(walletID, filter, after, limit+1)
+	if err != nil {
+		return nil, err
+	}
+
+	page := &TransactionPage{Transactions: transactions}
+	if len(transactions) > limit {
+		page.Transactions = transactions[:limit]
+		last := page.Transactions[limit-1]
+		page.NextCursor = TransactionCursor{CreatedAt: last.CreatedAt, ID: last.ID}.Encode()
+	}
+	if page.Transactions == nil {
+		page.Transactions = []*Transaction{}
+	}
 
-func generateReference() string {
-	return fmt.Sprintf("TXN_%d", time.Now().UnixNano())
+	return page, nil
 }
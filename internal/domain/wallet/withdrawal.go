@@ -0,0 +1,266 @@
+package wallet
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/BerylCAtieno/paystack-wallet/internal/domain/fee"
+	"github.com/BerylCAtieno/paystack-wallet/internal/domain/ledger"
+	"github.com/BerylCAtieno/paystack-wallet/internal/identifier"
+	"github.com/BerylCAtieno/paystack-wallet/internal/money"
+	"github.com/BerylCAtieno/paystack-wallet/internal/security"
+)
+
+// InitiateWithdrawal places a hold on amount plus the withdrawal fee and
+// records a pending withdrawal if amount is within the owner's withdrawal
+// limits. The funds stay on hold until Paystack reports the transfer as
+// settled, failed or reversed; the fee is only charged once it settles.
+func (s *Service) InitiateWithdrawal(walletID string, amount int64, details WithdrawalDetails) (*Transaction, error) {
+	if amount <= 0 {
+		return nil, ErrInvalidAmount
+	}
+
+	w, err := s.walletRepo.GetByID(walletID)
+	if err != nil {
+		return nil, ErrWalletNotFound
+	}
+	if w.Status == WalletStatusFrozen {
+		return nil, ErrWalletFrozen
+	}
+
+	tier, err := s.tier(w)
+	if err != nil {
+		return nil, err
+	}
+
+	breakdown := s.price(tier, fee.OperationWithdrawal, money.New(amount, w.Currency))
+	details.Fee = &breakdown
+
+	metadata, err := json.Marshal(details)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	tx := &Transaction{
+		ID:        security.GenerateID(),
+		WalletID:  w.ID,
+		Type:      TransactionTypeWithdrawal,
+		Amount:    -amount,
+		Currency:  w.Currency,
+		Status:    TransactionStatusPending,
+		Reference: identifier.NewReference(identifier.PrefixWithdrawal),
+		Metadata:  string(metadata),
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+
+	uow, err := s.walletRepo.BeginTx()
+	if err != nil {
+		return nil, err
+	}
+	defer uow.Rollback()
+
+	if err := s.checkLimit(uow, w, tier, fee.OperationWithdrawal, amount); err != nil {
+		return nil, err
+	}
+
+	if err := placeSettlementHold(uow, tx, breakdown.Total.Amount, "withdrawal"); err != nil {
+		return nil, err
+	}
+
+	if err := uow.CreateTransaction(tx); err != nil {
+		return nil, err
+	}
+
+	if err := uow.Commit(); err != nil {
+		return nil, err
+	}
+
+	return tx, nil
+}
+
+// AttachRecipient records the Paystack recipient a withdrawal is paid to.
+// The recipient is only created once the withdrawal's funds are on hold.
+func (s *Service) AttachRecipient(reference, recipientCode string) error {
+	return s.updateWithdrawalDetails(reference, func(details *WithdrawalDetails) {
+		details.RecipientCode = recipientCode
+	})
+}
+
+// AttachTransferCode records the Paystack transfer code on a withdrawal so
+// it can be finalized with an OTP later.
+func (s *Service) AttachTransferCode(reference, transferCode string) error {
+	return s.updateWithdrawalDetails(reference, func(details *WithdrawalDetails) {
+		details.TransferCode = transferCode
+	})
+}
+
+// updateWithdrawalDetails only touches a withdrawal that is still pending;
+// once Paystack has settled it the details are no longer needed and
+// ErrNotPending is returned.
+func (s *Service) updateWithdrawalDetails(reference string, update func(*WithdrawalDetails)) error {
+	tx, err := s.GetWithdrawal(reference)
+	if err != nil {
+		return err
+	}
+	if tx.Status != TransactionStatusPending {
+		return ErrNotPending
+	}
+
+	details, err := tx.WithdrawalDetails()
+	if err != nil {
+		return err
+	}
+	update(details)
+
+	metadata, err := json.Marshal(details)
+	if err != nil {
+		return err
+	}
+
+	updated, err := s.transactionRepo.UpdateMetadata(tx.ID, TransactionStatusPending, string(metadata))
+	if err != nil {
+		return err
+	}
+	if !updated {
+		return ErrNotPending
+	}
+	return nil
+}
+
+// CompleteWithdrawal settles a pending withdrawal once Paystack confirms the
+// transfer, capturing its hold against the settlement balance and charging
+// the fee held with it. A withdrawal that had already failed is paid out
+// all the same, so it is debited after all; see settleFailedPayout.
+func (s *Service) CompleteWithdrawal(reference string) error {
+	tx, err := s.GetWithdrawal(reference)
+	if err != nil {
+		return err
+	}
+
+	details, err := tx.WithdrawalDetails()
+	if err != nil {
+		return err
+	}
+
+	switch tx.Status {
+	case TransactionStatusPending:
+	case TransactionStatusFailed:
+		return s.settleFailedPayout(tx, details.Fee, "Withdrawal settled after failing")
+	default:
+		return nil // Already settled (idempotency)
+	}
+
+	uow, err := s.walletRepo.BeginTx()
+	if err != nil {
+		return err
+	}
+	defer uow.Rollback()
+
+	updated, err := uow.UpdateTransactionStatus(tx.ID, TransactionStatusPending, TransactionStatusSuccess)
+	if err != nil {
+		return err
+	}
+	if !updated {
+		return nil
+	}
+
+	if err := s.captureSettlementHold(uow, tx, "Withdrawal settled"); err != nil {
+		return err
+	}
+
+	w, err := uow.GetWallet(tx.WalletID)
+	if err != nil {
+		return err
+	}
+
+	if err := chargeFee(uow, w, details.Fee, tx.Reference); err != nil {
+		return err
+	}
+
+	return uow.Commit()
+}
+
+// FailWithdrawal returns the held funds to the wallet when a transfer fails
+// or is reversed. A pending withdrawal becomes failed and its hold, fee
+// included, is released; a withdrawal that had already settled becomes
+// reversed and its amount is refunded from the settlement balance Paystack
+// returned it to. The fee of a settled payout is not refunded.
+func (s *Service) FailWithdrawal(reference string) error {
+	tx, err := s.GetWithdrawal(reference)
+	if err != nil {
+		return err
+	}
+
+	var to TransactionStatus
+	switch tx.Status {
+	case TransactionStatusPending:
+		to = TransactionStatusFailed
+	case TransactionStatusSuccess:
+		to = TransactionStatusReversed
+	default:
+		return nil // Already failed or reversed (idempotency)
+	}
+
+	uow, err := s.walletRepo.BeginTx()
+	if err != nil {
+		return err
+	}
+	defer uow.Rollback()
+
+	updated, err := uow.UpdateTransactionStatus(tx.ID, tx.Status, to)
+	if err != nil {
+		return err
+	}
+	if !updated {
+		return nil
+	}
+
+	if to == TransactionStatusFailed {
+		if err := s.releaseSettlementHold(uow, tx); err != nil {
+			return err
+		}
+		return uow.Commit()
+	}
+
+	// Paystack returned the settled payout to our balance
+	entry := ledger.NewEntry(tx.Reference, "Withdrawal returned").
+		Debit(ledger.SystemAccountID(ledger.SystemPaystackSettlement, string(tx.Currency)), -tx.Amount).
+		Credit(ledger.WalletAccountID(tx.WalletID), -tx.Amount)
+
+	if err := uow.PostEntry(entry); err != nil {
+		return err
+	}
+
+	return uow.Commit()
+}
+
+// ListStaleWithdrawals returns withdrawals still pending after olderThan,
+// oldest first, continuing after the cursor when one is given.
+func (s *Service) ListStaleWithdrawals(olderThan time.Duration, after *TransactionCursor, limit int) ([]*Transaction, error) {
+	return s.transactionRepo.ListByStatus(TransactionTypeWithdrawal, TransactionStatusPending, time.Now().Add(-olderThan), after, limit)
+}
+
+func (s *Service) GetWithdrawal(reference string) (*Transaction, error) {
+	tx, err := s.transactionRepo.GetByReference(reference)
+	if err != nil {
+		return nil, err
+	}
+	if tx.Type != TransactionTypeWithdrawal {
+		return nil, ErrTransactionNotFound
+	}
+	return tx, nil
+}
+
+// WithdrawalDetails decodes the payout destination stored on a withdrawal
+func (t *Transaction) WithdrawalDetails() (*WithdrawalDetails, error) {
+	details := &WithdrawalDetails{}
+	if t.Metadata == "" {
+		return details, nil
+	}
+	if err := json.Unmarshal([]byte(t.Metadata), details); err != nil {
+		return nil, err
+	}
+	return details, nil
+}
@@ -0,0 +1,15 @@
+package webhook
+
+import "errors"
+
+var (
+	ErrNotFound = errors.New("webhook event not found")
+	// ErrDuplicate is returned when Paystack delivers an event that was
+	// already stored
+	ErrDuplicate     = errors.New("duplicate webhook event")
+	ErrNotReplayable = errors.New("only failed webhook events can be replayed")
+	ErrInvalidStatus = errors.New("invalid webhook event status")
+	// ErrIgnored is returned by a Processor for events about payments this
+	// service never made, which are recorded as ignored instead of retried
+	ErrIgnored = errors.New("webhook event ignored")
+)
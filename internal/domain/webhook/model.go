@@ -0,0 +1,42 @@
+package webhook
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// Event is a verified Paystack webhook kept for processing and replay
+type Event struct {
+	ID         string          `json:"id"`
+	EventType  string          `json:"event_type"`
+	PaystackID string          `json:"paystack_id"`
+	Payload    json.RawMessage `json:"payload"`
+	Status     Status          `json:"status"`
+	Attempts   int             `json:"attempts"`
+	LastError  string          `json:"last_error,omitempty"`
+	// NextAttemptAt is when a pending event is next due; while processing
+	// it is the lease after which a crashed attempt is picked up again
+	NextAttemptAt time.Time  `json:"next_attempt_at"`
+	ReceivedAt    time.Time  `json:"received_at"`
+	ProcessedAt   *time.Time `json:"processed_at,omitempty"`
+}
+
+type Status string
+
+const (
+	StatusPending    Status = "pending"
+	StatusProcessing Status = "processing"
+	StatusProcessed  Status = "processed"
+	// StatusFailed events exhausted their retries and wait for a replay
+	StatusFailed Status = "failed"
+	// StatusIgnored events were about something this service never created
+	StatusIgnored Status = "ignored"
+)
+
+func (s Status) Valid() bool {
+	switch s {
+	case StatusPending, StatusProcessing, StatusProcessed, StatusFailed, StatusIgnored:
+		return true
+	}
+	return false
+}
@@ -0,0 +1,233 @@
+package webhook
+
+import (
+	"errors"
+	"log"
+	"time"
+
+	"github.com/BerylCAtieno/paystack-wallet/internal/security"
+)
+
+const (
+	// processingLease bounds how long an attempt may run before the event
+	// is considered abandoned and claimed again
+	processingLease = 5 * time.Minute
+	baseRetryDelay  = 30 * time.Second
+	maxRetryDelay   = time.Hour
+	claimBatchSize  = 50
+
+	DefaultListLimit = 50
+	MaxListLimit     = 200
+)
+
+type Repository interface {
+	// Insert stores the event unless one with the same type and Paystack ID
+	// exists, reporting whether it was inserted.
+	Insert(event *Event) (bool, error)
+	GetByID(id string) (*Event, error)
+	List(status Status, limit int) ([]*Event, error)
+	// ListDue returns pending events due at now and processing events whose
+	// lease has run out, oldest first.
+	ListDue(now time.Time, limit int) ([]*Event, error)
+	// Claim marks a due event as processing until leaseUntil, reporting
+	// whether it was still due.
+	Claim(id string, now, leaseUntil time.Time) (bool, error)
+	Update(event *Event) error
+}
+
+// Processor applies a stored event to the wallets
+type Processor interface {
+	Process(eventType string, payload []byte) error
+}
+
+type Service struct {
+	repo        Repository
+	processor   Processor
+	maxAttempts int
+	wake        chan struct{}
+}
+
+func NewService(repo Repository, processor Processor, maxAttempts int) *Service {
+	if maxAttempts < 1 {
+		maxAttempts = 1
+	}
+	return &Service{
+		repo:        repo,
+		processor:   processor,
+		maxAttempts: maxAttempts,
+		wake:        make(chan struct{}, 1),
+	}
+}
+
+// Record stores a verified webhook for asynchronous processing. Redeliveries
+// of an event already stored return ErrDuplicate.
+func (s *Service) Record(eventType, paystackID string, payload []byte) (*Event, error) {
+	now := time.Now()
+	event := &Event{
+		ID:            security.GenerateID(),
+		EventType:     eventType,
+		PaystackID:    paystackID,
+		Payload:       payload,
+		Status:        StatusPending,
+		NextAttemptAt: now,
+		ReceivedAt:    now,
+	}
+
+	inserted, err := s.repo.Insert(event)
+	if err != nil {
+		return nil, err
+	}
+	if !inserted {
+		return nil, ErrDuplicate
+	}
+
+	s.notify()
+	return event, nil
+}
+
+func (s *Service) Get(id string) (*Event, error) {
+	event, err := s.repo.GetByID(id)
+	if err != nil {
+		return nil, ErrNotFound
+	}
+	return event, nil
+}
+
+// List returns the newest events, optionally only those in one status
+func (s *Service) List(status Status, limit int) ([]*Event, error) {
+	if status != "" && !status.Valid() {
+		return nil, ErrInvalidStatus
+	}
+	if limit <= 0 {
+		limit = DefaultListLimit
+	}
+	if limit > MaxListLimit {
+		limit = MaxListLimit
+	}
+	return s.repo.List(status, limit)
+}
+
+// Replay queues a failed event to be processed again with a fresh set of
+// attempts.
+func (s *Service) Replay(id string) (*Event, error) {
+	event, err := s.Get(id)
+	if err != nil {
+		return nil, err
+	}
+	if event.Status != StatusFailed {
+		return nil, ErrNotReplayable
+	}
+
+	event.Status = StatusPending
+	event.Attempts = 0
+	event.NextAttemptAt = time.Now()
+	if err := s.repo.Update(event); err != nil {
+		return nil, err
+	}
+
+	s.notify()
+	return event, nil
+}
+
+// Run processes due events until the process exits, waking every interval
+// or as soon as an event is recorded or replayed.
+func (s *Service) Run(interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		if _, err := s.ProcessDue(); err != nil {
+			log.Printf("Failed to process webhook events: %v", err)
+		}
+
+		select {
+		case <-ticker.C:
+		case <-s.wake:
+		}
+	}
+}
+
+// ProcessDue claims and processes every event that is due, returning how
+// many were processed successfully.
+func (s *Service) ProcessDue() (int, error) {
+	processed := 0
+	for {
+		now := time.Now()
+		events, err := s.repo.ListDue(now, claimBatchSize)
+		if err != nil {
+			return processed, err
+		}
+		if len(events) == 0 {
+			return processed, nil
+		}
+
+		for _, event := range events {
+			claimed, err := s.repo.Claim(event.ID, now, now.Add(processingLease))
+			if err != nil {
+				return processed, err
+			}
+			if !claimed {
+				continue
+			}
+
+			ok, err := s.process(event)
+			if err != nil {
+				return processed, err
+			}
+			if ok {
+				processed++
+			}
+		}
+	}
+}
+
+// process runs one attempt and records its outcome. Failed attempts are
+// retried with exponential backoff until maxAttempts is reached.
+func (s *Service) process(event *Event) (bool, error) {
+	procErr := s.processor.Process(event.EventType, event.Payload)
+
+	now := time.Now()
+	event.Attempts++
+
+	if procErr == nil {
+		event.Status = StatusProcessed
+		event.LastError = ""
+		event.ProcessedAt = &now
+		return true, s.repo.Update(event)
+	}
+	if errors.Is(procErr, ErrIgnored) {
+		event.Status = StatusIgnored
+		event.LastError = procErr.Error()
+		event.ProcessedAt = &now
+		return true, s.repo.Update(event)
+	}
+
+	event.LastError = procErr.Error()
+	if event.Attempts >= s.maxAttempts {
+		event.Status = StatusFailed
+		log.Printf("Webhook event %s (%s) failed after %d attempts: %v", event.ID, event.EventType, event.Attempts, procErr)
+	} else {
+		event.Status = StatusPending
+		event.NextAttemptAt = now.Add(retryDelay(event.Attempts))
+	}
+
+	return false, s.repo.Update(event)
+}
+
+func retryDelay(attempts int) time.Duration {
+	delay := baseRetryDelay
+	for i := 1; i < attempts && delay < maxRetryDelay; i++ {
+		delay *= 2
+	}
+	if delay > maxRetryDelay {
+		delay = maxRetryDelay
+	}
+	return delay
+}
+
+func (s *Service) notify() {
+	select {
+	case s.wake <- struct{}{}:
+	default:
+	}
+}
@@ -0,0 +1,17 @@
+package identifier
+
+// Reference prefixes identify the kind of transaction at a glance
+const (
+	PrefixDeposit    = "DEP"
+	PrefixTransfer   = "TXN"
+	PrefixWithdrawal = "WDR"
+	PrefixRefund     = "RFD"
+	PrefixExchange   = "EXC"
+	PrefixQuote      = "QTE"
+)
+
+// NewReference returns a unique transaction reference such as
+// DEP_01HF8Z3K9Q4W2X7V6B5N1M0C8D.
+func NewReference(prefix string) string {
+	return prefix + "_" + NewULID()
+}
@@ -0,0 +1,46 @@
+package identifier
+
+import (
+	"crypto/rand"
+	"encoding/binary"
+	"time"
+)
+
+// Crockford's base32 alphabet, which omits I, L, O and U
+const crockford = "0123456789ABCDEFGHJKMNPQRSTVWXYZ"
+
+// NewULID returns a 26 character ULID: a 48-bit millisecond timestamp
+// followed by 80 random bits. ULIDs sort by creation time and are safe to
+// generate concurrently without coordination.
+func NewULID() string {
+	return ulidAt(time.Now())
+}
+
+func ulidAt(t time.Time) string {
+	var b [16]byte
+
+	var ts [8]byte
+	binary.BigEndian.PutUint64(ts[:], uint64(t.UnixMilli()))
+	copy(b[:6], ts[2:])
+
+	if _, err := rand.Read(b[6:]); err != nil {
+		panic("identifier: failed to read random bytes: " + err.Error())
+	}
+
+	return encode(b)
+}
+
+// encode writes the 128 bits as 26 base32 characters, the first of which
+// only carries the top 3 bits.
+func encode(b [16]byte) string {
+	hi := binary.BigEndian.Uint64(b[:8])
+	lo := binary.BigEndian.Uint64(b[8:])
+
+	var out [26]byte
+	for i := 25; i >= 0; i-- {
+		out[i] = crockford[lo&0x1f]
+		lo = lo>>5 | hi<<59
+		hi >>= 5
+	}
+	return string(out[:])
+}
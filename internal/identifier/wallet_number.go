@@ -0,0 +1,65 @@
+package identifier
+
+import (
+	"crypto/rand"
+	"math/big"
+)
+
+// WalletNumberLength is the number of digits in a wallet number, including
+// the trailing Luhn check digit.
+const WalletNumberLength = 13
+
+// NewWalletNumber returns a random 13 digit wallet number whose last digit is
+// a Luhn check digit, so most typos are rejected before any lookup.
+func NewWalletNumber() string {
+	digits := make([]byte, WalletNumberLength)
+
+	for i := 0; i < WalletNumberLength-1; i++ {
+		max := int64(10)
+		offset := int64(0)
+		if i == 0 {
+			// Avoid leading zeros, which are easily dropped by spreadsheets
+			max, offset = 9, 1
+		}
+		n, err := rand.Int(rand.Reader, big.NewInt(max))
+		if err != nil {
+			panic("identifier: failed to read random bytes: " + err.Error())
+		}
+		digits[i] = byte('0' + n.Int64() + offset)
+	}
+
+	digits[WalletNumberLength-1] = luhnCheckDigit(digits[:WalletNumberLength-1])
+	return string(digits)
+}
+
+// ValidWalletNumber reports whether s is 13 digits with a valid check digit
+func ValidWalletNumber(s string) bool {
+	if len(s) != WalletNumberLength {
+		return false
+	}
+	for i := 0; i < len(s); i++ {
+		if s[i] < '0' || s[i] > '9' {
+			return false
+		}
+	}
+	return luhnCheckDigit([]byte(s[:WalletNumberLength-1])) == s[WalletNumberLength-1]
+}
+
+// luhnCheckDigit computes the digit that makes payload+digit pass the Luhn
+// checksum.
+func luhnCheckDigit(payload []byte) byte {
+	sum := 0
+	double := true // the digit left of the check digit is doubled
+	for i := len(payload) - 1; i >= 0; i-- {
+		d := int(payload[i] - '0')
+		if double {
+			d *= 2
+			if d > 9 {
+				d -= 9
+			}
+		}
+		sum += d
+		double = !double
+	}
+	return byte('0' + (10-sum%10)%10)
+}
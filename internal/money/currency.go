@@ -0,0 +1,73 @@
+package money
+
+import (
+	"errors"
+	"strings"
+)
+
+// Currency is an ISO 4217 currency code
+type Currency string
+
+const (
+	NGN Currency = "NGN"
+	GHS Currency = "GHS"
+	ZAR Currency = "ZAR"
+	KES Currency = "KES"
+	USD Currency = "USD"
+)
+
+// DefaultCurrency is the currency of wallets opened at sign-up and of
+// requests that do not name one.
+const DefaultCurrency = NGN
+
+var ErrUnsupportedCurrency = errors.New("unsupported currency")
+
+// minorUnit describes how a currency's amounts are stored: as an integer
+// count of the minor unit, which is 10^exponent of the major unit.
+type minorUnit struct {
+	exponent int
+	name     string
+}
+
+// Currencies Paystack can collect and settle in
+var minorUnits = map[Currency]minorUnit{
+	NGN: {exponent: 2, name: "kobo"},
+	GHS: {exponent: 2, name: "pesewas"},
+	ZAR: {exponent: 2, name: "cents"},
+	KES: {exponent: 2, name: "cents"},
+	USD: {exponent: 2, name: "cents"},
+}
+
+// ParseCurrency accepts a supported currency code in any case
+func ParseCurrency(code string) (Currency, error) {
+	c := Currency(strings.ToUpper(strings.TrimSpace(code)))
+	if !c.Valid() {
+		return "", ErrUnsupportedCurrency
+	}
+	return c, nil
+}
+
+// Supported lists the supported currencies, default first
+func Supported() []Currency {
+	return []Currency{NGN, GHS, ZAR, KES, USD}
+}
+
+func (c Currency) Valid() bool {
+	_, ok := minorUnits[c]
+	return ok
+}
+
+// Exponent is the number of decimal places between the major and minor
+// unit, e.g. 2 for NGN where 1 naira is 100 kobo.
+func (c Currency) Exponent() int {
+	return minorUnits[c].exponent
+}
+
+// MinorUnit names the unit amounts are stored in, e.g. "kobo"
+func (c Currency) MinorUnit() string {
+	return minorUnits[c].name
+}
+
+func (c Currency) String() string {
+	return string(c)
+}
@@ -0,0 +1,64 @@
+package money
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+)
+
+// Money is an amount in a currency's minor unit, e.g. 150000 NGN is
+// ₦1,500.00. Amounts are never stored as floats.
+type Money struct {
+	Amount   int64    `json:"amount"`
+	Currency Currency `json:"currency"`
+}
+
+var ErrCurrencyMismatch = errors.New("amounts are in different currencies")
+
+func New(amount int64, currency Currency) Money {
+	return Money{Amount: amount, Currency: currency}
+}
+
+func (m Money) IsPositive() bool {
+	return m.Amount > 0
+}
+
+// Add returns m + o; both must be in the same currency
+func (m Money) Add(o Money) (Money, error) {
+	if m.Currency != o.Currency {
+		return Money{}, ErrCurrencyMismatch
+	}
+	return New(m.Amount+o.Amount, m.Currency), nil
+}
+
+// Sub returns m - o; both must be in the same currency
+func (m Money) Sub(o Money) (Money, error) {
+	if m.Currency != o.Currency {
+		return Money{}, ErrCurrencyMismatch
+	}
+	return New(m.Amount-o.Amount, m.Currency), nil
+}
+
+// Major formats the amount in the major unit, e.g. "1500.00"
+func (m Money) Major() string {
+	exp := m.Currency.Exponent()
+
+	sign := ""
+	amount := m.Amount
+	if amount < 0 {
+		sign = "-"
+		amount = -amount
+	}
+
+	if exp == 0 {
+		return sign + strconv.FormatInt(amount, 10)
+	}
+
+	digits := fmt.Sprintf("%0*d", exp+1, amount)
+	return sign + digits[:len(digits)-exp] + "." + digits[len(digits)-exp:]
+}
+
+// String formats the amount with its currency, e.g. "NGN 1500.00"
+func (m Money) String() string {
+	return string(m.Currency) + " " + m.Major()
+}
@@ -0,0 +1,87 @@
+package paystack
+
+import (
+	"fmt"
+	"net/url"
+)
+
+type Bank struct {
+	ID       int64  `json:"id"`
+	Name     string `json:"name"`
+	Slug     string `json:"slug"`
+	Code     string `json:"code"`
+	Country  string `json:"country"`
+	Currency string `json:"currency"`
+	Type     string `json:"type"`
+	Active   bool   `json:"active"`
+}
+
+type ListBanksResponse struct {
+	Status  bool   `json:"status"`
+	Message string `json:"message"`
+	Data    []Bank `json:"data"`
+	Meta    struct {
+		Next string `json:"next"`
+	} `json:"meta"`
+}
+
+type ResolveAccountResponse struct {
+	Status  bool   `json:"status"`
+	Message string `json:"message"`
+	Data    struct {
+		AccountNumber string `json:"account_number"`
+		AccountName   string `json:"account_name"`
+		BankID        int64  `json:"bank_id"`
+	} `json:"data"`
+}
+
+// ListBanks returns every bank Paystack supports for the currency, following
+// the cursor until all pages are read.
+func (c *Client) ListBanks(currency string) ([]Bank, error) {
+	var banks []Bank
+	cursor := ""
+
+	for {
+		params := url.Values{}
+		params.Set("currency", currency)
+		params.Set("perPage", "100")
+		params.Set("use_cursor", "true")
+		if cursor != "" {
+			params.Set("next", cursor)
+		}
+
+		var listResp ListBanksResponse
+		if err := c.do("GET", "/bank?"+params.Encode(), nil, &listResp); err != nil {
+			return nil, err
+		}
+
+		if !listResp.Status {
+			return nil, fmt.Errorf("paystack error: %s", listResp.Message)
+		}
+
+		banks = append(banks, listResp.Data...)
+
+		if listResp.Meta.Next == "" {
+			return banks, nil
+		}
+		cursor = listResp.Meta.Next
+	}
+}
+
+// ResolveAccount looks up the account holder's name for a bank account
+func (c *Client) ResolveAccount(accountNumber, bankCode string) (*ResolveAccountResponse, error) {
+	params := url.Values{}
+	params.Set("account_number", accountNumber)
+	params.Set("bank_code", bankCode)
+
+	var resolveResp ResolveAccountResponse
+	if err := c.do("GET", "/bank/resolve?"+params.Encode(), nil, &resolveResp); err != nil {
+		return nil, err
+	}
+
+	if !resolveResp.Status {
+		return nil, fmt.Errorf("paystack error: %s", resolveResp.Message)
+	}
+
+	return &resolveResp, nil
+}
@@ -3,26 +3,39 @@ package paystack
 import (
 	"bytes"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/BerylCAtieno/paystack-wallet/internal/money"
 )
 
+// requestTimeout bounds each call to Paystack, so a hung connection cannot
+// hold a request or a worker forever
+const requestTimeout = 30 * time.Second
+
 type Client struct {
-	secretKey string
-	baseURL   string
+	secretKey  string
+	baseURL    string
+	httpClient *http.Client
 }
 
 func NewClient(secretKey string) *Client {
 	return &Client{
-		secretKey: secretKey,
-		baseURL:   "https://api.paystack.co",
+		secretKey:  secretKey,
+		baseURL:    "https://api.paystack.co",
+		httpClient: &http.Client{Timeout: requestTimeout},
 	}
 }
 
 type InitializeRequest struct {
 	Email     string `json:"email"`
-	Amount    int64  `json:"amount"` // in kobo
+	Amount    int64  `json:"amount"` // in the currency's minor unit, e.g. kobo
+	Currency  string `json:"currency"`
 	Reference string `json:"reference"`
 }
 
@@ -40,87 +53,133 @@ type VerifyResponse struct {
 	Status  bool   `json:"status"`
 	Message string `json:"message"`
 	Data    struct {
-		ID              int64  `json:"id"`
-		Reference       string `json:"reference"`
-		Amount          int64  `json:"amount"`
-		Status          string `json:"status"`
-		PaidAt          string `json:"paid_at"`
-		Channel         string `json:"channel"`
-		Currency        string `json:"currency"`
-		IPAddress       string `json:"ip_address"`
-		CreatedAt       string `json:"createdAt"`
-		GatewayResponse string `json:"gateway_response"`
+		ID              int64    `json:"id"`
+		Reference       string   `json:"reference"`
+		Amount          int64    `json:"amount"`
+		Status          string   `json:"status"`
+		PaidAt          string   `json:"paid_at"`
+		Channel         string   `json:"channel"`
+		Currency        string   `json:"currency"`
+		IPAddress       string   `json:"ip_address"`
+		CreatedAt       string   `json:"createdAt"`
+		GatewayResponse string   `json:"gateway_response"`
+		Customer        Customer `json:"customer"`
 	} `json:"data"`
 }
 
-func (c *Client) InitializeTransaction(email string, amount int64, reference string) (*InitializeResponse, error) {
+// Transaction statuses returned by verify
+const (
+	TransactionStatusSuccess   = "success"
+	TransactionStatusFailed    = "failed"
+	TransactionStatusAbandoned = "abandoned"
+)
+
+func (c *Client) InitializeTransaction(email string, amount money.Money, reference string) (*InitializeResponse, error) {
 	reqBody := InitializeRequest{
 		Email:     email,
-		Amount:    amount,
+		Amount:    amount.Amount,
+		Currency:  string(amount.Currency),
 		Reference: reference,
 	}
 
-	jsonData, err := json.Marshal(reqBody)
-	if err != nil {
+	var initResp InitializeResponse
+	if err := c.do("POST", "/transaction/initialize", reqBody, &initResp); err != nil {
 		return nil, err
 	}
 
-	req, err := http.NewRequest("POST", c.baseURL+"/transaction/initialize", bytes.NewBuffer(jsonData))
-	if err != nil {
-		return nil, err
+	if !initResp.Status {
+		return nil, fmt.Errorf("paystack error: %s", initResp.Message)
 	}
 
-	req.Header.Set("Authorization", "Bearer "+c.secretKey)
-	req.Header.Set("Content-Type", "application/json")
+	return &initResp, nil
+}
 
-	client := &http.Client{}
-	resp, err := client.Do(req)
-	if err != nil {
+func (c *Client) VerifyTransaction(reference string) (*VerifyResponse, error) {
+	var verifyResp VerifyResponse
+	if err := c.do("GET", "/transaction/verify/"+url.PathEscape(reference), nil, &verifyResp); err != nil {
 		return nil, err
 	}
-	defer resp.Body.Close()
 
-	body, err := io.ReadAll(resp.Body)
-	if err != nil {
-		return nil, err
+	if !verifyResp.Status {
+		return nil, fmt.Errorf("paystack error: %s", verifyResp.Message)
 	}
 
-	var initResp InitializeResponse
-	if err := json.Unmarshal(body, &initResp); err != nil {
-		return nil, err
-	}
+	return &verifyResp, nil
+}
 
-	if !initResp.Status {
-		return nil, fmt.Errorf("paystack error: %s", initResp.Message)
-	}
+// APIError is an error response from Paystack
+type APIError struct {
+	StatusCode int
+	Message    string
+}
 
-	return &initResp, nil
+func (e *APIError) Error() string {
+	return fmt.Sprintf("paystack error: %s (HTTP %d)", e.Message, e.StatusCode)
 }
 
-func (c *Client) VerifyTransaction(reference string) (*VerifyResponse, error) {
-	req, err := http.NewRequest("GET", c.baseURL+"/transaction/verify/"+reference, nil)
+// IsRejected reports whether Paystack answered with a 4xx, so it certainly
+// did not act on the request. After a timeout, a dropped connection or a
+// 5xx the request may still have gone through.
+func IsRejected(err error) bool {
+	var apiErr *APIError
+	return errors.As(err, &apiErr) && apiErr.StatusCode >= 400 && apiErr.StatusCode < 500
+}
+
+// IsNotFound reports whether Paystack answered that the object asked for
+// does not exist. Paystack reports unknown references with a 400 or 404 and
+// a "not found" message.
+func IsNotFound(err error) bool {
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) {
+		return false
+	}
+	return apiErr.StatusCode == http.StatusNotFound ||
+		(apiErr.StatusCode == http.StatusBadRequest && strings.Contains(strings.ToLower(apiErr.Message), "not found"))
+}
+
+// do sends an authenticated request to the Paystack API and decodes the JSON
+// response into out. A nil body sends no payload. Responses other than 2xx
+// are returned as an *APIError.
+func (c *Client) do(method, path string, body interface{}, out interface{}) error {
+	var reqBody io.Reader
+	if body != nil {
+		jsonData, err := json.Marshal(body)
+		if err != nil {
+			return err
+		}
+		reqBody = bytes.NewBuffer(jsonData)
+	}
+
+	req, err := http.NewRequest(method, c.baseURL+path, reqBody)
 	if err != nil {
-		return nil, err
+		return err
 	}
 
 	req.Header.Set("Authorization", "Bearer "+c.secretKey)
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
 
-	client := &http.Client{}
-	resp, err := client.Do(req)
+	resp, err := c.httpClient.Do(req)
 	if err != nil {
-		return nil, err
+		return err
 	}
 	defer resp.Body.Close()
 
-	body, err := io.ReadAll(resp.Body)
+	respBody, err := io.ReadAll(resp.Body)
 	if err != nil {
-		return nil, err
+		return err
 	}
 
-	var verifyResp VerifyResponse
-	if err := json.Unmarshal(body, &verifyResp); err != nil {
-		return nil, err
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		var errResp struct {
+			Message string `json:"message"`
+		}
+		if json.Unmarshal(respBody, &errResp) != nil || errResp.Message == "" {
+			errResp.Message = http.StatusText(resp.StatusCode)
+		}
+		return &APIError{StatusCode: resp.StatusCode, Message: errResp.Message}
 	}
 
-	return &verifyResp, nil
+	return json.Unmarshal(respBody, out)
 }
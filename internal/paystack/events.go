@@ -0,0 +1,214 @@
+package paystack
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strconv"
+)
+
+// Webhook event names
+const (
+	EventChargeSuccess        = "charge.success"
+	EventChargeFailed         = "charge.failed"
+	EventChargeDisputeCreate  = "charge.dispute.create"
+	EventChargeDisputeRemind  = "charge.dispute.remind"
+	EventChargeDisputeResolve = "charge.dispute.resolve"
+
+	EventTransferSuccess  = "transfer.success"
+	EventTransferFailed   = "transfer.failed"
+	EventTransferReversed = "transfer.reversed"
+
+	EventRefundPending    = "refund.pending"
+	EventRefundProcessing = "refund.processing"
+	EventRefundProcessed  = "refund.processed"
+	EventRefundFailed     = "refund.failed"
+
+	EventSubscriptionCreate        = "subscription.create"
+	EventSubscriptionDisable       = "subscription.disable"
+	EventSubscriptionNotRenew      = "subscription.not_renew"
+	EventSubscriptionExpiringCards = "subscription.expiring_cards"
+)
+
+// FlexInt decodes integers that Paystack sends either as JSON numbers or as
+// numeric strings, as it does for refund amounts.
+type FlexInt int64
+
+func (n *FlexInt) UnmarshalJSON(data []byte) error {
+	data = bytes.Trim(data, `"`)
+	if len(data) == 0 || string(data) == "null" {
+		*n = 0
+		return nil
+	}
+
+	v, err := strconv.ParseInt(string(data), 10, 64)
+	if err != nil {
+		return fmt.Errorf("paystack: invalid integer %s", data)
+	}
+	*n = FlexInt(v)
+	return nil
+}
+
+func (n FlexInt) String() string {
+	return strconv.FormatInt(int64(n), 10)
+}
+
+type Customer struct {
+	Email        string `json:"email"`
+	CustomerCode string `json:"customer_code"`
+}
+
+// ChargeEvent is the payload of charge.success and charge.failed
+type ChargeEvent struct {
+	ID              FlexInt  `json:"id"`
+	Reference       string   `json:"reference"`
+	Amount          FlexInt  `json:"amount"`
+	Currency        string   `json:"currency"`
+	Status          string   `json:"status"`
+	Channel         string   `json:"channel"`
+	PaidAt          string   `json:"paid_at"`
+	GatewayResponse string   `json:"gateway_response"`
+	Customer        Customer `json:"customer"`
+}
+
+// TransferEvent is the payload of transfer.success, transfer.failed and
+// transfer.reversed
+type TransferEvent struct {
+	ID           FlexInt `json:"id"`
+	Reference    string  `json:"reference"`
+	Amount       FlexInt `json:"amount"`
+	Currency     string  `json:"currency"`
+	Status       string  `json:"status"`
+	TransferCode string  `json:"transfer_code"`
+	Reason       string  `json:"reason"`
+}
+
+// RefundEvent is the payload of the refund.* events
+type RefundEvent struct {
+	ID                   FlexInt  `json:"id"`
+	TransactionReference string   `json:"transaction_reference"`
+	RefundReference      string   `json:"refund_reference"`
+	Amount               FlexInt  `json:"amount"`
+	Currency             string   `json:"currency"`
+	Status               string   `json:"status"`
+	Customer             Customer `json:"customer"`
+}
+
+type DisputeTransaction struct {
+	ID        FlexInt `json:"id"`
+	Reference string  `json:"reference"`
+	Amount    FlexInt `json:"amount"`
+	Currency  string  `json:"currency"`
+}
+
+// DisputeEvent is the payload of the charge.dispute.* events
+type DisputeEvent struct {
+	ID           FlexInt            `json:"id"`
+	RefundAmount FlexInt            `json:"refund_amount"`
+	Currency     string             `json:"currency"`
+	Status       string             `json:"status"`
+	Resolution   string             `json:"resolution"`
+	Category     string             `json:"category"`
+	DueAt        string             `json:"dueAt"`
+	Transaction  DisputeTransaction `json:"transaction"`
+}
+
+type SubscriptionPlan struct {
+	PlanCode string `json:"plan_code"`
+	Name     string `json:"name"`
+}
+
+// SubscriptionEvent is the payload of the subscription.* events
+type SubscriptionEvent struct {
+	ID               FlexInt          `json:"id"`
+	SubscriptionCode string           `json:"subscription_code"`
+	Status           string           `json:"status"`
+	Amount           FlexInt          `json:"amount"`
+	Plan             SubscriptionPlan `json:"plan"`
+	Customer         Customer         `json:"customer"`
+}
+
+// Dispatcher routes webhook events to handlers registered by event name,
+// decoding each event's data into its typed payload first.
+type Dispatcher struct {
+	handlers map[string]func(data json.RawMessage) error
+}
+
+func NewDispatcher() *Dispatcher {
+	return &Dispatcher{handlers: make(map[string]func(data json.RawMessage) error)}
+}
+
+func (d *Dispatcher) OnCharge(event string, handle func(*ChargeEvent) error) {
+	d.handlers[event] = func(data json.RawMessage) error {
+		payload := &ChargeEvent{}
+		if err := json.Unmarshal(data, payload); err != nil {
+			return err
+		}
+		return handle(payload)
+	}
+}
+
+func (d *Dispatcher) OnTransfer(event string, handle func(*TransferEvent) error) {
+	d.handlers[event] = func(data json.RawMessage) error {
+		payload := &TransferEvent{}
+		if err := json.Unmarshal(data, payload); err != nil {
+			return err
+		}
+		return handle(payload)
+	}
+}
+
+func (d *Dispatcher) OnRefund(event string, handle func(*RefundEvent) error) {
+	d.handlers[event] = func(data json.RawMessage) error {
+		payload := &RefundEvent{}
+		if err := json.Unmarshal(data, payload); err != nil {
+			return err
+		}
+		return handle(payload)
+	}
+}
+
+func (d *Dispatcher) OnDispute(event string, handle func(*DisputeEvent) error) {
+	d.handlers[event] = func(data json.RawMessage) error {
+		payload := &DisputeEvent{}
+		if err := json.Unmarshal(data, payload); err != nil {
+			return err
+		}
+		return handle(payload)
+	}
+}
+
+func (d *Dispatcher) OnSubscription(event string, handle func(*SubscriptionEvent) error) {
+	d.handlers[event] = func(data json.RawMessage) error {
+		payload := &SubscriptionEvent{}
+		if err := json.Unmarshal(data, payload); err != nil {
+			return err
+		}
+		return handle(payload)
+	}
+}
+
+// Handles reports whether a handler is registered for the event
+func (d *Dispatcher) Handles(event string) bool {
+	_, ok := d.handlers[event]
+	return ok
+}
+
+// Dispatch decodes a webhook body and calls the handler registered for its
+// event. Events without a handler are ignored.
+func (d *Dispatcher) Dispatch(body []byte) error {
+	event, err := ParseWebhookEvent(body)
+	if err != nil {
+		return err
+	}
+
+	handle, ok := d.handlers[event.Event]
+	if !ok {
+		return nil
+	}
+
+	if err := handle(event.Data); err != nil {
+		return fmt.Errorf("%s: %w", event.Event, err)
+	}
+	return nil
+}
@@ -0,0 +1,404 @@
+package paystack
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/BerylCAtieno/paystack-wallet/internal/money"
+)
+
+// FailingAccountNumber is the account number whose transfers the fake
+// fails, so integrators can exercise failed withdrawals
+const FailingAccountNumber = "0000000000"
+
+// fakeSettleDelay is how long after a request the fake sends its webhook,
+// roughly as Paystack's test mode would
+const fakeSettleDelay = time.Second
+
+// Fake is an in-memory stand-in for the Paystack API, used by sandbox wallets
+// when no test secret key is configured. Charges are paid as soon as they are
+// initialized, transfers and refunds settle straight away and the webhooks
+// Paystack would send are passed to the handler set with OnEvent.
+type Fake struct {
+	mu         sync.Mutex
+	nextID     int64
+	charges    map[string]*fakeCharge // by reference
+	recipients map[string]string      // account number by recipient code
+	transfers  map[string]*TransferEvent
+	handler    func(event, paystackID string, payload []byte)
+}
+
+type fakeCharge struct {
+	ID       int64
+	Email    string
+	Amount   int64
+	Currency string
+	PaidAt   time.Time
+}
+
+func NewFake() *Fake {
+	return &Fake{
+		charges:    make(map[string]*fakeCharge),
+		recipients: make(map[string]string),
+		transfers:  make(map[string]*TransferEvent),
+	}
+}
+
+// Client returns a client whose requests are served by the fake
+func (f *Fake) Client() *Client {
+	return &Client{
+		secretKey:  "sk_test_fake",
+		baseURL:    "https://api.paystack.co",
+		httpClient: &http.Client{Transport: fakeTransport{f}},
+	}
+}
+
+// OnEvent sets where the fake delivers its webhook events, along with the
+// ID of the object each one is about
+func (f *Fake) OnEvent(handler func(event, paystackID string, payload []byte)) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.handler = handler
+}
+
+// fakeTransport answers requests with the fake instead of the network
+type fakeTransport struct {
+	fake *Fake
+}
+
+func (t fakeTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	rec := httptest.NewRecorder()
+	t.fake.ServeHTTP(rec, req)
+	return rec.Result(), nil
+}
+
+func (f *Fake) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	path := r.URL.Path
+	switch {
+	case r.Method == http.MethodPost && path == "/transaction/initialize":
+		f.initialize(w, r)
+	case r.Method == http.MethodGet && strings.HasPrefix(path, "/transaction/verify/"):
+		f.verify(w, strings.TrimPrefix(path, "/transaction/verify/"))
+	case r.Method == http.MethodPost && path == "/transferrecipient":
+		f.createRecipient(w, r)
+	case r.Method == http.MethodPost && path == "/transfer":
+		f.transfer(w, r)
+	case r.Method == http.MethodGet && strings.HasPrefix(path, "/transfer/verify/"):
+		f.verifyTransfer(w, strings.TrimPrefix(path, "/transfer/verify/"))
+	case r.Method == http.MethodPost && path == "/transfer/finalize_transfer":
+		f.finalizeTransfer(w, r)
+	case r.Method == http.MethodPost && path == "/refund":
+		f.refund(w, r)
+	case r.Method == http.MethodGet && path == "/bank":
+		f.listBanks(w, r)
+	case r.Method == http.MethodGet && path == "/bank/resolve":
+		f.resolveAccount(w, r)
+	default:
+		fakeError(w, http.StatusNotFound, "the sandbox does not support "+r.Method+" "+path)
+	}
+}
+
+func (f *Fake) initialize(w http.ResponseWriter, r *http.Request) {
+	var req InitializeRequest
+	if !decodeFake(w, r, &req) {
+		return
+	}
+	if _, exists := f.charges[req.Reference]; exists {
+		fakeError(w, http.StatusBadRequest, "Duplicate Transaction Reference")
+		return
+	}
+
+	charge := &fakeCharge{
+		ID:       f.id(),
+		Email:    req.Email,
+		Amount:   req.Amount,
+		Currency: req.Currency,
+		PaidAt:   time.Now().UTC(),
+	}
+	f.charges[req.Reference] = charge
+
+	var resp InitializeResponse
+	resp.Status = true
+	resp.Message = "Authorization URL created"
+	resp.Data.AccessCode = fmt.Sprintf("sandbox_%d", charge.ID)
+	resp.Data.AuthorizationURL = "https://checkout.paystack.com/" + resp.Data.AccessCode
+	resp.Data.Reference = req.Reference
+	writeFake(w, resp)
+
+	f.send(EventChargeSuccess, f.chargeEvent(req.Reference, charge))
+}
+
+func (f *Fake) verify(w http.ResponseWriter, reference string) {
+	charge, ok := f.charges[reference]
+	if !ok {
+		fakeError(w, http.StatusBadRequest, "Transaction reference not found")
+		return
+	}
+
+	var resp VerifyResponse
+	resp.Status = true
+	resp.Message = "Verification successful"
+	resp.Data.ID = charge.ID
+	resp.Data.Reference = reference
+	resp.Data.Amount = charge.Amount
+	resp.Data.Currency = charge.Currency
+	resp.Data.Status = TransactionStatusSuccess
+	resp.Data.Channel = "card"
+	resp.Data.PaidAt = charge.PaidAt.Format(time.RFC3339)
+	resp.Data.CreatedAt = resp.Data.PaidAt
+	resp.Data.GatewayResponse = "Successful"
+	resp.Data.Customer = Customer{Email: charge.Email}
+	writeFake(w, resp)
+}
+
+func (f *Fake) createRecipient(w http.ResponseWriter, r *http.Request) {
+	var req TransferRecipientRequest
+	if !decodeFake(w, r, &req) {
+		return
+	}
+
+	code := fmt.Sprintf("RCP_sandbox%d", f.id())
+	f.recipients[code] = req.AccountNumber
+
+	var resp TransferRecipientResponse
+	resp.Status = true
+	resp.Message = "Transfer recipient created successfully"
+	resp.Data.RecipientCode = code
+	resp.Data.Name = req.Name
+	resp.Data.Details.AccountNumber = req.AccountNumber
+	resp.Data.Details.AccountName = req.Name
+	resp.Data.Details.BankCode = req.BankCode
+	resp.Data.Details.BankName = "Test Bank"
+	writeFake(w, resp)
+}
+
+// transfer settles every transfer straight away, failing those to
+// FailingAccountNumber
+func (f *Fake) transfer(w http.ResponseWriter, r *http.Request) {
+	var req TransferRequest
+	if !decodeFake(w, r, &req) {
+		return
+	}
+	accountNumber, ok := f.recipients[req.Recipient]
+	if !ok {
+		fakeError(w, http.StatusBadRequest, "Recipient specified is invalid")
+		return
+	}
+
+	id := f.id()
+	event := &TransferEvent{
+		ID:           FlexInt(id),
+		Reference:    req.Reference,
+		Amount:       FlexInt(req.Amount),
+		Currency:     req.Currency,
+		Status:       TransferStatusSuccess,
+		TransferCode: fmt.Sprintf("TRF_sandbox%d", id),
+		Reason:       req.Reason,
+	}
+	eventName := EventTransferSuccess
+	if accountNumber == FailingAccountNumber {
+		event.Status = TransferStatusFailed
+		eventName = EventTransferFailed
+	}
+	f.transfers[event.TransferCode] = event
+
+	writeFake(w, transferResponse(event, TransferStatusPending))
+	f.send(eventName, event)
+}
+
+// finalizeTransfer only confirms transfers, as the fake never asks for an OTP
+func (f *Fake) finalizeTransfer(w http.ResponseWriter, r *http.Request) {
+	var req FinalizeTransferRequest
+	if !decodeFake(w, r, &req) {
+		return
+	}
+	event, ok := f.transfers[req.TransferCode]
+	if !ok {
+		fakeError(w, http.StatusBadRequest, "Transfer code is invalid")
+		return
+	}
+	writeFake(w, transferResponse(event, event.Status))
+}
+
+func (f *Fake) verifyTransfer(w http.ResponseWriter, reference string) {
+	for _, event := range f.transfers {
+		if event.Reference == reference {
+			writeFake(w, transferResponse(event, event.Status))
+			return
+		}
+	}
+	fakeError(w, http.StatusNotFound, "Transfer not found")
+}
+
+func (f *Fake) refund(w http.ResponseWriter, r *http.Request) {
+	var req RefundRequest
+	if !decodeFake(w, r, &req) {
+		return
+	}
+	charge, ok := f.charges[req.Transaction]
+	if !ok {
+		fakeError(w, http.StatusBadRequest, "Transaction not found")
+		return
+	}
+
+	amount := req.Amount
+	if amount == 0 {
+		amount = charge.Amount
+	}
+	if amount > charge.Amount {
+		fakeError(w, http.StatusBadRequest, "Refund amount cannot be greater than transaction amount")
+		return
+	}
+
+	var resp RefundResponse
+	resp.Status = true
+	resp.Message = "Refund has been queued for processing"
+	resp.Data.ID = FlexInt(f.id())
+	resp.Data.Amount = FlexInt(amount)
+	resp.Data.Currency = charge.Currency
+	resp.Data.Status = "pending"
+	resp.Data.ExpectedAt = time.Now().UTC().Format(time.RFC3339)
+	resp.Data.Transaction.ID = FlexInt(charge.ID)
+	resp.Data.Transaction.Reference = req.Transaction
+	writeFake(w, resp)
+
+	f.send(EventRefundProcessed, &RefundEvent{
+		ID:                   resp.Data.ID,
+		TransactionReference: req.Transaction,
+		Amount:               resp.Data.Amount,
+		Currency:             charge.Currency,
+		Status:               "processed",
+		Customer:             Customer{Email: charge.Email},
+	})
+}
+
+// listBanks returns a single test bank in the requested currency
+func (f *Fake) listBanks(w http.ResponseWriter, r *http.Request) {
+	currency := r.URL.Query().Get("currency")
+	if currency == "" {
+		currency = string(money.NGN)
+	}
+
+	var resp ListBanksResponse
+	resp.Status = true
+	resp.Message = "Banks retrieved"
+	resp.Data = []Bank{{
+		ID:       1,
+		Name:     "Test Bank",
+		Slug:     "test-bank",
+		Code:     "001",
+		Currency: currency,
+		Type:     recipientTypes[money.Currency(currency)],
+		Active:   true,
+	}}
+	writeFake(w, resp)
+}
+
+// resolveAccount resolves any ten digit account number
+func (f *Fake) resolveAccount(w http.ResponseWriter, r *http.Request) {
+	accountNumber := r.URL.Query().Get("account_number")
+	if len(accountNumber) != 10 {
+		fakeError(w, http.StatusUnprocessableEntity, "Could not resolve account name. Check parameters or try again.")
+		return
+	}
+
+	var resp ResolveAccountResponse
+	resp.Status = true
+	resp.Message = "Account number resolved"
+	resp.Data.AccountNumber = accountNumber
+	resp.Data.AccountName = "SANDBOX ACCOUNT"
+	resp.Data.BankID = 1
+	writeFake(w, resp)
+}
+
+func (f *Fake) chargeEvent(reference string, charge *fakeCharge) *ChargeEvent {
+	return &ChargeEvent{
+		ID:              FlexInt(charge.ID),
+		Reference:       reference,
+		Amount:          FlexInt(charge.Amount),
+		Currency:        charge.Currency,
+		Status:          TransactionStatusSuccess,
+		Channel:         "card",
+		PaidAt:          charge.PaidAt.Format(time.RFC3339),
+		GatewayResponse: "Successful",
+		Customer:        Customer{Email: charge.Email},
+	}
+}
+
+// send delivers an event to the handler after fakeSettleDelay, once the
+// caller has had time to record the request
+func (f *Fake) send(event string, data interface{}) {
+	if f.handler == nil {
+		return
+	}
+
+	raw, err := json.Marshal(data)
+	if err != nil {
+		log.Printf("sandbox: failed to encode %s: %v", event, err)
+		return
+	}
+	webhookEvent := WebhookEvent{Event: event, Data: raw}
+	payload, err := json.Marshal(webhookEvent)
+	if err != nil {
+		log.Printf("sandbox: failed to encode %s: %v", event, err)
+		return
+	}
+
+	handler, paystackID := f.handler, webhookEvent.ObjectID()
+	time.AfterFunc(fakeSettleDelay, func() {
+		handler(event, paystackID, payload)
+	})
+}
+
+func (f *Fake) id() int64 {
+	f.nextID++
+	return f.nextID
+}
+
+func transferResponse(event *TransferEvent, status string) TransferResponse {
+	var resp TransferResponse
+	resp.Status = true
+	resp.Message = "Transfer has been queued"
+	resp.Data.TransferCode = event.TransferCode
+	resp.Data.Reference = event.Reference
+	resp.Data.Amount = int64(event.Amount)
+	resp.Data.Currency = event.Currency
+	resp.Data.Status = status
+	return resp
+}
+
+func decodeFake(w http.ResponseWriter, r *http.Request, v interface{}) bool {
+	body, err := io.ReadAll(r.Body)
+	if err == nil {
+		err = json.Unmarshal(body, v)
+	}
+	if err != nil {
+		fakeError(w, http.StatusBadRequest, "Invalid request body")
+		return false
+	}
+	return true
+}
+
+func writeFake(w http.ResponseWriter, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(v)
+}
+
+func fakeError(w http.ResponseWriter, status int, message string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"status":  false,
+		"message": message,
+	})
+}
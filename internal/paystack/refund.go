@@ -0,0 +1,50 @@
+package paystack
+
+import "fmt"
+
+type RefundRequest struct {
+	Transaction  string `json:"transaction"`      // reference or ID of the charge
+	Amount       int64  `json:"amount,omitempty"` // in the minor unit, defaults to the full charge
+	Currency     string `json:"currency,omitempty"`
+	CustomerNote string `json:"customer_note,omitempty"`
+	MerchantNote string `json:"merchant_note,omitempty"`
+}
+
+type RefundResponse struct {
+	Status  bool   `json:"status"`
+	Message string `json:"message"`
+	Data    struct {
+		ID          FlexInt `json:"id"`
+		Amount      FlexInt `json:"amount"`
+		Currency    string  `json:"currency"`
+		Status      string  `json:"status"` // pending, processing, processed, failed
+		ExpectedAt  string  `json:"expected_at"`
+		Transaction struct {
+			ID        FlexInt `json:"id"`
+			Reference string  `json:"reference"`
+		} `json:"transaction"`
+	} `json:"data"`
+}
+
+// CreateRefund asks Paystack to return amount of a successful charge to the
+// customer. Refunds complete asynchronously; the outcome arrives as a
+// refund.processed or refund.failed webhook.
+func (c *Client) CreateRefund(reference string, amount int64, note string) (*RefundResponse, error) {
+	reqBody := RefundRequest{
+		Transaction:  reference,
+		Amount:       amount,
+		CustomerNote: note,
+		MerchantNote: note,
+	}
+
+	var refundResp RefundResponse
+	if err := c.do("POST", "/refund", reqBody, &refundResp); err != nil {
+		return nil, err
+	}
+
+	if !refundResp.Status {
+		return nil, fmt.Errorf("paystack error: %s", refundResp.Message)
+	}
+
+	return &refundResp, nil
+}
@@ -0,0 +1,162 @@
+package paystack
+
+import (
+	"fmt"
+	"net/url"
+
+	"github.com/BerylCAtieno/paystack-wallet/internal/money"
+)
+
+type TransferRecipientRequest struct {
+	Type          string `json:"type"`
+	Name          string `json:"name"`
+	AccountNumber string `json:"account_number"`
+	BankCode      string `json:"bank_code"`
+	Currency      string `json:"currency"`
+}
+
+type TransferRecipientResponse struct {
+	Status  bool   `json:"status"`
+	Message string `json:"message"`
+	Data    struct {
+		RecipientCode string `json:"recipient_code"`
+		Name          string `json:"name"`
+		Details       struct {
+			AccountNumber string `json:"account_number"`
+			AccountName   string `json:"account_name"`
+			BankCode      string `json:"bank_code"`
+			BankName      string `json:"bank_name"`
+		} `json:"details"`
+	} `json:"data"`
+}
+
+type TransferRequest struct {
+	Source    string `json:"source"`
+	Amount    int64  `json:"amount"` // in the currency's minor unit
+	Currency  string `json:"currency"`
+	Recipient string `json:"recipient"`
+	Reference string `json:"reference"`
+	Reason    string `json:"reason,omitempty"`
+}
+
+type FinalizeTransferRequest struct {
+	TransferCode string `json:"transfer_code"`
+	OTP          string `json:"otp"`
+}
+
+type TransferResponse struct {
+	Status  bool   `json:"status"`
+	Message string `json:"message"`
+	Data    struct {
+		TransferCode string `json:"transfer_code"`
+		Reference    string `json:"reference"`
+		Amount       int64  `json:"amount"`
+		Currency     string `json:"currency"`
+		Status       string `json:"status"` // pending, otp, success, failed
+	} `json:"data"`
+}
+
+// Transfer statuses returned when a transfer is initiated
+const (
+	TransferStatusOTP     = "otp"
+	TransferStatusPending = "pending"
+	TransferStatusSuccess = "success"
+	TransferStatusFailed  = "failed"
+	// TransferStatusReversed is reported by VerifyTransfer for a transfer
+	// that settled and was then returned
+	TransferStatusReversed = "reversed"
+)
+
+// recipientTypes maps each payout currency to the Paystack recipient type
+// of its local bank accounts
+var recipientTypes = map[money.Currency]string{
+	money.NGN: "nuban",
+	money.GHS: "ghipss",
+	money.ZAR: "basa",
+	money.KES: "kepss",
+}
+
+// CreateTransferRecipient registers a local bank account in currency as a
+// payout destination and returns its recipient code.
+func (c *Client) CreateTransferRecipient(name, accountNumber, bankCode string, currency money.Currency) (*TransferRecipientResponse, error) {
+	recipientType, ok := recipientTypes[currency]
+	if !ok {
+		return nil, fmt.Errorf("paystack: payouts in %s are not supported", currency)
+	}
+
+	reqBody := TransferRecipientRequest{
+		Type:          recipientType,
+		Name:          name,
+		AccountNumber: accountNumber,
+		BankCode:      bankCode,
+		Currency:      string(currency),
+	}
+
+	var recipientResp TransferRecipientResponse
+	if err := c.do("POST", "/transferrecipient", reqBody, &recipientResp); err != nil {
+		return nil, err
+	}
+
+	if !recipientResp.Status {
+		return nil, fmt.Errorf("paystack error: %s", recipientResp.Message)
+	}
+
+	return &recipientResp, nil
+}
+
+// InitiateTransfer sends amount from the Paystack balance to a recipient.
+// When OTP confirmation is enabled on the integration the returned status is
+// "otp" and the transfer must be completed with FinalizeTransfer.
+func (c *Client) InitiateTransfer(amount money.Money, recipientCode, reference, reason string) (*TransferResponse, error) {
+	reqBody := TransferRequest{
+		Source:    "balance",
+		Amount:    amount.Amount,
+		Currency:  string(amount.Currency),
+		Recipient: recipientCode,
+		Reference: reference,
+		Reason:    reason,
+	}
+
+	var transferResp TransferResponse
+	if err := c.do("POST", "/transfer", reqBody, &transferResp); err != nil {
+		return nil, err
+	}
+
+	if !transferResp.Status {
+		return nil, fmt.Errorf("paystack error: %s", transferResp.Message)
+	}
+
+	return &transferResp, nil
+}
+
+// VerifyTransfer looks up a transfer by the reference it was initiated with
+func (c *Client) VerifyTransfer(reference string) (*TransferResponse, error) {
+	var transferResp TransferResponse
+	if err := c.do("GET", "/transfer/verify/"+url.PathEscape(reference), nil, &transferResp); err != nil {
+		return nil, err
+	}
+
+	if !transferResp.Status {
+		return nil, fmt.Errorf("paystack error: %s", transferResp.Message)
+	}
+
+	return &transferResp, nil
+}
+
+func (c *Client) FinalizeTransfer(transferCode, otp string) (*TransferResponse, error) {
+	reqBody := FinalizeTransferRequest{
+		TransferCode: transferCode,
+		OTP:          otp,
+	}
+
+	var transferResp TransferResponse
+	if err := c.do("POST", "/transfer/finalize_transfer", reqBody, &transferResp); err != nil {
+		return nil, err
+	}
+
+	if !transferResp.Status {
+		return nil, fmt.Errorf("paystack error: %s", transferResp.Message)
+	}
+
+	return &transferResp, nil
+}
@@ -9,25 +9,11 @@ import (
 	"net/http"
 )
 
+// WebhookEvent is the envelope of every webhook. Data is decoded into a
+// typed payload by the Dispatcher.
 type WebhookEvent struct {
-	Event string      `json:"event"`
-	Data  WebhookData `json:"data"`
-}
-
-type WebhookData struct {
-	ID              int64    `json:"id"`
-	Reference       string   `json:"reference"`
-	Amount          int64    `json:"amount"`
-	Status          string   `json:"status"`
-	PaidAt          string   `json:"paid_at"`
-	Channel         string   `json:"channel"`
-	Currency        string   `json:"currency"`
-	Customer        Customer `json:"customer"`
-	GatewayResponse string   `json:"gateway_response"`
-}
-
-type Customer struct {
-	Email string `json:"email"`
+	Event string          `json:"event"`
+	Data  json.RawMessage `json:"data"`
 }
 
 func ValidateWebhookSignature(r *http.Request, secretKey string) ([]byte, bool) {
@@ -48,6 +34,24 @@ func ValidateWebhookSignature(r *http.Request, secretKey string) ([]byte, bool)
 	return body, hmac.Equal([]byte(signature), []byte(expectedSignature))
 }
 
+// ObjectID identifies the charge, transfer or refund an event is about.
+// Paystack does not send event IDs, so redeliveries are recognised by the
+// event name together with this ID.
+func (e *WebhookEvent) ObjectID() string {
+	var object struct {
+		ID        FlexInt `json:"id"`
+		Reference string  `json:"reference"`
+	}
+	if err := json.Unmarshal(e.Data, &object); err != nil {
+		return ""
+	}
+
+	if object.ID != 0 {
+		return object.ID.String()
+	}
+	return object.Reference
+}
+
 func ParseWebhookEvent(body []byte) (*WebhookEvent, error) {
 	var event WebhookEvent
 	if err := json.Unmarshal(body, &event); err != nil {
@@ -0,0 +1,124 @@
+package reconcile
+
+import (
+	"log"
+	"time"
+
+	"github.com/BerylCAtieno/paystack-wallet/internal/domain/ledger"
+	"github.com/BerylCAtieno/paystack-wallet/internal/domain/wallet"
+)
+
+type WalletRepository interface {
+	List() ([]*wallet.Wallet, error)
+	UpdateStatus(walletID string, status wallet.WalletStatus) error
+}
+
+type TransactionRepository interface {
+	SumBalanceByWalletID(walletID string) (int64, error)
+}
+
+type LedgerRepository interface {
+	GetAccountBalance(id string) (int64, error)
+}
+
+// Engine compares each wallet's cached balance with the balance recomputed
+// from its transaction history and from its ledger postings.
+type Engine struct {
+	walletRepo      WalletRepository
+	transactionRepo TransactionRepository
+	ledgerRepo      LedgerRepository
+}
+
+func NewEngine(walletRepo WalletRepository, transactionRepo TransactionRepository, ledgerRepo LedgerRepository) *Engine {
+	return &Engine{
+		walletRepo:      walletRepo,
+		transactionRepo: transactionRepo,
+		ledgerRepo:      ledgerRepo,
+	}
+}
+
+type Options struct {
+	// FreezeDrifted marks every drifted wallet as frozen
+	FreezeDrifted bool
+	// Database names the database reconciled in the report, live or
+	// sandbox
+	Database string
+}
+
+type Report struct {
+	Database       string    `json:"database"`
+	StartedAt      time.Time `json:"started_at"`
+	FinishedAt     time.Time `json:"finished_at"`
+	WalletsScanned int       `json:"wallets_scanned"`
+	Drifted        []Drift   `json:"drifted"`
+}
+
+type Drift struct {
+	WalletID           string `json:"wallet_id"`
+	WalletNumber       string `json:"wallet_number"`
+	UserID             string `json:"user_id"`
+	CachedBalance      int64  `json:"cached_balance"`
+	TransactionBalance int64  `json:"transaction_balance"`
+	LedgerBalance      int64  `json:"ledger_balance"`
+	Frozen             bool   `json:"frozen"`
+}
+
+// HasDrift reports whether any wallet failed reconciliation
+func (r *Report) HasDrift() bool {
+	return len(r.Drifted) > 0
+}
+
+func (e *Engine) Run(opts Options) (*Report, error) {
+	report := &Report{
+		Database:  opts.Database,
+		StartedAt: time.Now(),
+		Drifted:   []Drift{},
+	}
+
+	wallets, err := e.walletRepo.List()
+	if err != nil {
+		return nil, err
+	}
+
+	for _, w := range wallets {
+		report.WalletsScanned++
+
+		txBalance, err := e.transactionRepo.SumBalanceByWalletID(w.ID)
+		if err != nil {
+			return nil, err
+		}
+
+		ledgerBalance, err := e.ledgerRepo.GetAccountBalance(ledger.WalletAccountID(w.ID))
+		if err != nil {
+			return nil, err
+		}
+
+		if w.Balance == txBalance && w.Balance == ledgerBalance {
+			continue
+		}
+
+		drift := Drift{
+			WalletID:           w.ID,
+			WalletNumber:       w.WalletNumber,
+			UserID:             w.UserID,
+			CachedBalance:      w.Balance,
+			TransactionBalance: txBalance,
+			LedgerBalance:      ledgerBalance,
+			Frozen:             w.Status == wallet.WalletStatusFrozen,
+		}
+
+		if opts.FreezeDrifted && !drift.Frozen {
+			if err := e.walletRepo.UpdateStatus(w.ID, wallet.WalletStatusFrozen); err != nil {
+				return nil, err
+			}
+			drift.Frozen = true
+			log.Printf("reconcile: froze wallet %s (cached=%d transactions=%d ledger=%d)",
+				w.ID, w.Balance, txBalance, ledgerBalance)
+		}
+
+		report.Drifted = append(report.Drifted, drift)
+	}
+
+	report.FinishedAt = time.Now()
+	return report, nil
+}
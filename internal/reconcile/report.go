@@ -0,0 +1,34 @@
+package reconcile
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"text/tabwriter"
+	"time"
+)
+
+func (r *Report) WriteJSON(w io.Writer) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(r)
+}
+
+func (r *Report) WriteText(w io.Writer) error {
+	fmt.Fprintf(w, "Reconciliation of the %s database finished %s (%s)\n",
+		r.Database, r.FinishedAt.Format(time.RFC3339), r.FinishedAt.Sub(r.StartedAt).Round(time.Millisecond))
+	fmt.Fprintf(w, "Wallets scanned: %d, drifted: %d\n", r.WalletsScanned, len(r.Drifted))
+
+	if !r.HasDrift() {
+		return nil
+	}
+
+	fmt.Fprintln(w)
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "WALLET\tNUMBER\tCACHED\tTRANSACTIONS\tLEDGER\tFROZEN")
+	for _, d := range r.Drifted {
+		fmt.Fprintf(tw, "%s\t%s\t%d\t%d\t%d\t%t\n",
+			d.WalletID, d.WalletNumber, d.CachedBalance, d.TransactionBalance, d.LedgerBalance, d.Frozen)
+	}
+	return tw.Flush()
+}
@@ -3,10 +3,14 @@ package repository
 import (
 	"database/sql"
 	"encoding/json"
+	"time"
 
 	"github.com/BerylCAtieno/paystack-wallet/internal/domain/auth"
 )
 
+const apiKeyColumns = `id, user_id, name, key_hash, key_prefix, mode, permissions, allowed_ips, expires_at, is_revoked,
+	last_used_at, last_used_ip, created_at, updated_at`
+
 type APIKeyRepository struct {
 	db *sql.DB
 }
@@ -15,32 +19,14 @@ func NewAPIKeyRepository(db *sql.DB) *APIKeyRepository {
 	return &APIKeyRepository{db: db}
 }
 
-func (r *APIKeyRepository) Create(key *auth.APIKey) error {
-	permsJSON, err := json.Marshal(key.Permissions)
-	if err != nil {
-		return err
-	}
-
-	query := `INSERT INTO api_keys (id, user_id, name, key_hash, permissions, expires_at, is_revoked, created_at, updated_at)
-		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)`
-
-	_, err = r.db.Exec(query,
-		key.ID, key.UserID, key.Name, key.KeyHash, string(permsJSON),
-		key.ExpiresAt, key.IsRevoked, key.CreatedAt, key.UpdatedAt,
-	)
-	return err
-}
-
-func (r *APIKeyRepository) GetByID(id string) (*auth.APIKey, error) {
-	query := `SELECT id, user_id, name, key_hash, permissions, expires_at, is_revoked, created_at, updated_at
-		FROM api_keys WHERE id = ?`
-
+func scanAPIKey(row rowScanner) (*auth.APIKey, error) {
 	key := &auth.APIKey{}
-	var permsJSON string
+	var permsJSON, allowedIPsJSON string
+	var lastUsedAt sql.NullTime
 
-	err := r.db.QueryRow(query, id).Scan(
-		&key.ID, &key.UserID, &key.Name, &key.KeyHash, &permsJSON,
-		&key.ExpiresAt, &key.IsRevoked, &key.CreatedAt, &key.UpdatedAt,
+	err := row.Scan(
+		&key.ID, &key.UserID, &key.Name, &key.KeyHash, &key.KeyPrefix, &key.Mode, &permsJSON,
+		&allowedIPsJSON, &key.ExpiresAt, &key.IsRevoked, &lastUsedAt, &key.LastUsedIP, &key.CreatedAt, &key.UpdatedAt,
 	)
 	if err != nil {
 		return nil, err
@@ -49,34 +35,49 @@ func (r *APIKeyRepository) GetByID(id string) (*auth.APIKey, error) {
 	if err := json.Unmarshal([]byte(permsJSON), &key.Permissions); err != nil {
 		return nil, err
 	}
+	if err := json.Unmarshal([]byte(allowedIPsJSON), &key.AllowedIPs); err != nil {
+		return nil, err
+	}
+
+	if lastUsedAt.Valid {
+		key.LastUsedAt = &lastUsedAt.Time
+	}
 
 	return key, nil
 }
 
-func (r *APIKeyRepository) GetByKeyHash(hash string) (*auth.APIKey, error) {
-	query := `SELECT id, user_id, name, key_hash, permissions, expires_at, is_revoked, created_at, updated_at
-		FROM api_keys WHERE key_hash = ?`
+func (r *APIKeyRepository) Create(key *auth.APIKey) error {
+	permsJSON, err := json.Marshal(key.Permissions)
+	if err != nil {
+		return err
+	}
+	allowedIPsJSON, err := allowedIPsJSON(key)
+	if err != nil {
+		return err
+	}
 
-	key := &auth.APIKey{}
-	var permsJSON string
+	query := `INSERT INTO api_keys (` + apiKeyColumns + `)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`
 
-	err := r.db.QueryRow(query, hash).Scan(
-		&key.ID, &key.UserID, &key.Name, &key.KeyHash, &permsJSON,
-		&key.ExpiresAt, &key.IsRevoked, &key.CreatedAt, &key.UpdatedAt,
+	_, err = r.db.Exec(query,
+		key.ID, key.UserID, key.Name, key.KeyHash, key.KeyPrefix, key.Mode, string(permsJSON),
+		allowedIPsJSON, key.ExpiresAt, key.IsRevoked, key.LastUsedAt, key.LastUsedIP, key.CreatedAt, key.UpdatedAt,
 	)
-	if err != nil {
-		return nil, err
-	}
+	return err
+}
 
-	if err := json.Unmarshal([]byte(permsJSON), &key.Permissions); err != nil {
-		return nil, err
-	}
+func (r *APIKeyRepository) GetByID(id string) (*auth.APIKey, error) {
+	query := `SELECT ` + apiKeyColumns + ` FROM api_keys WHERE id = ?`
+	return scanAPIKey(r.db.QueryRow(query, id))
+}
 
-	return key, nil
+func (r *APIKeyRepository) GetByKeyHash(hash string) (*auth.APIKey, error) {
+	query := `SELECT ` + apiKeyColumns + ` FROM api_keys WHERE key_hash = ?`
+	return scanAPIKey(r.db.QueryRow(query, hash))
 }
 
 func (r *APIKeyRepository) CountActiveByUserID(userID string) (int, error) {
-	query := `SELECT COUNT(*) FROM api_keys 
+	query := `SELECT COUNT(*) FROM api_keys
 		WHERE user_id = ? AND is_revoked = 0 AND expires_at > CURRENT_TIMESTAMP`
 
 	var count int
@@ -96,9 +97,30 @@ func (r *APIKeyRepository) Update(key *auth.APIKey) error {
 	return err
 }
 
+func (r *APIKeyRepository) UpdateAllowedIPs(key *auth.APIKey) error {
+	allowedIPsJSON, err := allowedIPsJSON(key)
+	if err != nil {
+		return err
+	}
+
+	query := `UPDATE api_keys SET allowed_ips = ?, updated_at = ? WHERE id = ?`
+
+	_, err = r.db.Exec(query, allowedIPsJSON, key.UpdatedAt, key.ID)
+	return err
+}
+
+// allowedIPsJSON stores a missing allowlist as an empty array
+func allowedIPsJSON(key *auth.APIKey) (string, error) {
+	ips := key.AllowedIPs
+	if ips == nil {
+		ips = []string{}
+	}
+	data, err := json.Marshal(ips)
+	return string(data), err
+}
+
 func (r *APIKeyRepository) ListByUserID(userID string) ([]*auth.APIKey, error) {
-	query := `SELECT id, user_id, name, key_hash, permissions, expires_at, is_revoked, created_at, updated_at
-		FROM api_keys WHERE user_id = ? ORDER BY created_at DESC`
+	query := `SELECT ` + apiKeyColumns + ` FROM api_keys WHERE user_id = ? ORDER BY created_at DESC`
 
 	rows, err := r.db.Query(query, userID)
 	if err != nil {
@@ -108,23 +130,22 @@ func (r *APIKeyRepository) ListByUserID(userID string) ([]*auth.APIKey, error) {
 
 	var keys []*auth.APIKey
 	for rows.Next() {
-		key := &auth.APIKey{}
-		var permsJSON string
-
-		err := rows.Scan(
-			&key.ID, &key.UserID, &key.Name, &key.KeyHash, &permsJSON,
-			&key.ExpiresAt, &key.IsRevoked, &key.CreatedAt, &key.UpdatedAt,
-		)
+		key, err := scanAPIKey(rows)
 		if err != nil {
 			return nil, err
 		}
-
-		if err := json.Unmarshal([]byte(permsJSON), &key.Permissions); err != nil {
-			return nil, err
-		}
-
 		keys = append(keys, key)
 	}
 
-	return keys, nil
+	return keys, rows.Err()
+}
+
+// RecordUse skips the write when the key was last used from the same IP
+// after staleBefore, so only the first request each minute updates the row.
+func (r *APIKeyRepository) RecordUse(id string, at time.Time, ip string, staleBefore time.Time) error {
+	query := `UPDATE api_keys SET last_used_at = ?, last_used_ip = ?
+		WHERE id = ? AND (last_used_at IS NULL OR last_used_at < ? OR last_used_ip != ?)`
+
+	_, err := r.db.Exec(query, storedTime(at), ip, id, storedTime(staleBefore), ip)
+	return err
 }
@@ -0,0 +1,54 @@
+package repository
+
+import (
+	"database/sql"
+
+	"github.com/BerylCAtieno/paystack-wallet/internal/domain/auth"
+)
+
+const auditColumns = `id, api_key_id, user_id, event, ip, detail, created_at`
+
+type AuditRepository struct {
+	db *sql.DB
+}
+
+func NewAuditRepository(db *sql.DB) *AuditRepository {
+	return &AuditRepository{db: db}
+}
+
+func scanAuditEvent(row rowScanner) (*auth.AuditEvent, error) {
+	e := &auth.AuditEvent{}
+	err := row.Scan(&e.ID, &e.APIKeyID, &e.UserID, &e.Event, &e.IP, &e.Detail, &e.CreatedAt)
+	if err != nil {
+		return nil, err
+	}
+	return e, nil
+}
+
+func (r *AuditRepository) Create(e *auth.AuditEvent) error {
+	query := `INSERT INTO api_key_audit (` + auditColumns + `) VALUES (?, ?, ?, ?, ?, ?, ?)`
+
+	_, err := r.db.Exec(query, e.ID, e.APIKeyID, e.UserID, e.Event, e.IP, e.Detail, storedTime(e.CreatedAt))
+	return err
+}
+
+func (r *AuditRepository) ListByAPIKeyID(apiKeyID string, limit int) ([]*auth.AuditEvent, error) {
+	query := `SELECT ` + auditColumns + ` FROM api_key_audit
+		WHERE api_key_id = ? ORDER BY created_at DESC LIMIT ?`
+
+	rows, err := r.db.Query(query, apiKeyID, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var events []*auth.AuditEvent
+	for rows.Next() {
+		e, err := scanAuditEvent(rows)
+		if err != nil {
+			return nil, err
+		}
+		events = append(events, e)
+	}
+	return events, rows.Err()
+}
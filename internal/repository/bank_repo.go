@@ -0,0 +1,62 @@
+package repository
+
+import (
+	"database/sql"
+
+	"github.com/BerylCAtieno/paystack-wallet/internal/domain/bank"
+)
+
+type BankRepository struct {
+	db *sql.DB
+}
+
+func NewBankRepository(db *sql.DB) *BankRepository {
+	return &BankRepository{db: db}
+}
+
+func (r *BankRepository) ListByCurrency(currency string) ([]*bank.Bank, error) {
+	query := `SELECT code, name, slug, country, currency, type, active, fetched_at
+		FROM banks WHERE currency = ? ORDER BY name`
+
+	rows, err := r.db.Query(query, currency)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var banks []*bank.Bank
+	for rows.Next() {
+		b := &bank.Bank{}
+		if err := rows.Scan(&b.Code, &b.Name, &b.Slug, &b.Country, &b.Currency, &b.Type, &b.Active, &b.FetchedAt); err != nil {
+			return nil, err
+		}
+		banks = append(banks, b)
+	}
+
+	return banks, rows.Err()
+}
+
+// ReplaceForCurrency swaps the cached bank list for a currency in one
+// transaction so readers never see a partial list.
+func (r *BankRepository) ReplaceForCurrency(currency string, banks []*bank.Bank) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec(`DELETE FROM banks WHERE currency = ?`, currency); err != nil {
+		return err
+	}
+
+	query := `INSERT OR REPLACE INTO banks (currency, code, name, slug, country, type, active, fetched_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?)`
+
+	for _, b := range banks {
+		if _, err := tx.Exec(query, currency, b.Code, b.Name, b.Slug, b.Country, b.Type, b.Active, b.FetchedAt); err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
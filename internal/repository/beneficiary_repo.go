@@ -0,0 +1,89 @@
+package repository
+
+import (
+	"database/sql"
+
+	"github.com/BerylCAtieno/paystack-wallet/internal/domain/beneficiary"
+)
+
+const beneficiaryColumns = `id, wallet_id, type, nickname, wallet_number, account_number, bank_code, bank_name, account_name, created_at, updated_at`
+
+type BeneficiaryRepository struct {
+	db *sql.DB
+}
+
+func NewBeneficiaryRepository(db *sql.DB) *BeneficiaryRepository {
+	return &BeneficiaryRepository{db: db}
+}
+
+func scanBeneficiary(row rowScanner) (*beneficiary.Beneficiary, error) {
+	b := &beneficiary.Beneficiary{}
+	err := row.Scan(
+		&b.ID, &b.WalletID, &b.Type, &b.Nickname, &b.WalletNumber,
+		&b.AccountNumber, &b.BankCode, &b.BankName, &b.AccountName, &b.CreatedAt, &b.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return b, nil
+}
+
+func (r *BeneficiaryRepository) Create(b *beneficiary.Beneficiary) error {
+	query := `INSERT INTO beneficiaries (` + beneficiaryColumns + `)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`
+
+	_, err := r.db.Exec(query,
+		b.ID, b.WalletID, b.Type, b.Nickname, b.WalletNumber,
+		b.AccountNumber, b.BankCode, b.BankName, b.AccountName, b.CreatedAt, b.UpdatedAt,
+	)
+	return err
+}
+
+func (r *BeneficiaryRepository) GetByID(id string) (*beneficiary.Beneficiary, error) {
+	query := `SELECT ` + beneficiaryColumns + ` FROM beneficiaries WHERE id = ?`
+
+	return scanBeneficiary(r.db.QueryRow(query, id))
+}
+
+func (r *BeneficiaryRepository) ListByWalletID(walletID string) ([]*beneficiary.Beneficiary, error) {
+	query := `SELECT ` + beneficiaryColumns + `
+		FROM beneficiaries WHERE wallet_id = ? ORDER BY nickname, created_at`
+
+	rows, err := r.db.Query(query, walletID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	beneficiaries := []*beneficiary.Beneficiary{}
+	for rows.Next() {
+		b, err := scanBeneficiary(rows)
+		if err != nil {
+			return nil, err
+		}
+		beneficiaries = append(beneficiaries, b)
+	}
+
+	return beneficiaries, rows.Err()
+}
+
+// FindDuplicate returns an existing beneficiary of the same wallet that
+// points at the same payee, or sql.ErrNoRows.
+func (r *BeneficiaryRepository) FindDuplicate(b *beneficiary.Beneficiary) (*beneficiary.Beneficiary, error) {
+	query := `SELECT ` + beneficiaryColumns + ` FROM beneficiaries
+		WHERE wallet_id = ? AND type = ? AND wallet_number = ? AND bank_code = ? AND account_number = ?`
+
+	return scanBeneficiary(r.db.QueryRow(query, b.WalletID, b.Type, b.WalletNumber, b.BankCode, b.AccountNumber))
+}
+
+func (r *BeneficiaryRepository) Update(b *beneficiary.Beneficiary) error {
+	query := `UPDATE beneficiaries SET nickname = ?, updated_at = ? WHERE id = ?`
+
+	_, err := r.db.Exec(query, b.Nickname, b.UpdatedAt, b.ID)
+	return err
+}
+
+func (r *BeneficiaryRepository) Delete(id string) error {
+	_, err := r.db.Exec(`DELETE FROM beneficiaries WHERE id = ?`, id)
+	return err
+}
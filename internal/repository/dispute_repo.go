@@ -0,0 +1,52 @@
+package repository
+
+import (
+	"database/sql"
+
+	"github.com/BerylCAtieno/paystack-wallet/internal/domain/dispute"
+)
+
+const disputeColumns = `id, paystack_id, deposit_reference, wallet_id, amount, currency, status, category, resolution, due_at, created_at, updated_at`
+
+type DisputeRepository struct {
+	db *sql.DB
+}
+
+func NewDisputeRepository(db *sql.DB) *DisputeRepository {
+	return &DisputeRepository{db: db}
+}
+
+func scanDispute(row rowScanner) (*dispute.Dispute, error) {
+	d := &dispute.Dispute{}
+	err := row.Scan(
+		&d.ID, &d.PaystackID, &d.DepositReference, &d.WalletID, &d.Amount, &d.Currency,
+		&d.Status, &d.Category, &d.Resolution, &d.DueAt, &d.CreatedAt, &d.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return d, nil
+}
+
+func (r *DisputeRepository) Create(d *dispute.Dispute) error {
+	query := `INSERT INTO disputes (` + disputeColumns + `) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`
+
+	_, err := r.db.Exec(query,
+		d.ID, d.PaystackID, d.DepositReference, d.WalletID, d.Amount, d.Currency,
+		d.Status, d.Category, d.Resolution, d.DueAt, d.CreatedAt, d.UpdatedAt,
+	)
+	return err
+}
+
+func (r *DisputeRepository) GetByPaystackID(paystackID string) (*dispute.Dispute, error) {
+	query := `SELECT ` + disputeColumns + ` FROM disputes WHERE paystack_id = ?`
+
+	return scanDispute(r.db.QueryRow(query, paystackID))
+}
+
+func (r *DisputeRepository) Update(d *dispute.Dispute) error {
+	query := `UPDATE disputes SET status = ?, resolution = ?, due_at = ?, updated_at = ? WHERE id = ?`
+
+	_, err := r.db.Exec(query, d.Status, d.Resolution, d.DueAt, d.UpdatedAt, d.ID)
+	return err
+}
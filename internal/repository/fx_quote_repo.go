@@ -0,0 +1,50 @@
+package repository
+
+import (
+	"database/sql"
+
+	"github.com/BerylCAtieno/paystack-wallet/internal/domain/fx"
+)
+
+const fxQuoteColumns = `id, user_id, source_currency, source_amount, spread_amount, target_currency, target_amount, rate, status, expires_at, created_at`
+
+type FXQuoteRepository struct {
+	db *sql.DB
+}
+
+func NewFXQuoteRepository(db *sql.DB) *FXQuoteRepository {
+	return &FXQuoteRepository{db: db}
+}
+
+func scanFXQuote(row rowScanner) (*fx.Quote, error) {
+	q := &fx.Quote{}
+	err := row.Scan(
+		&q.ID, &q.UserID, &q.Source.Currency, &q.Source.Amount, &q.Spread.Amount,
+		&q.Target.Currency, &q.Target.Amount, &q.Rate, &q.Status, &q.ExpiresAt, &q.CreatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	q.Spread.Currency = q.Source.Currency
+	return q, nil
+}
+
+func (r *FXQuoteRepository) Create(q *fx.Quote) error {
+	query := `INSERT INTO fx_quotes (` + fxQuoteColumns + `) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`
+
+	_, err := r.db.Exec(query,
+		q.ID, q.UserID, q.Source.Currency, q.Source.Amount, q.Spread.Amount,
+		q.Target.Currency, q.Target.Amount, q.Rate, q.Status, q.ExpiresAt, q.CreatedAt,
+	)
+	return err
+}
+
+func (r *FXQuoteRepository) GetByID(id string) (*fx.Quote, error) {
+	query := `SELECT ` + fxQuoteColumns + ` FROM fx_quotes WHERE id = ?`
+
+	q, err := scanFXQuote(r.db.QueryRow(query, id))
+	if err == sql.ErrNoRows {
+		return nil, fx.ErrQuoteNotFound
+	}
+	return q, err
+}
@@ -0,0 +1,103 @@
+package repository
+
+import (
+	"database/sql"
+	"time"
+
+	"github.com/BerylCAtieno/paystack-wallet/internal/domain/wallet"
+)
+
+const holdColumns = `id, wallet_id, amount, captured_amount, reason, reference, recipient_wallet, status, expires_at, created_at, updated_at`
+
+// heldAmountQuery sums a wallet's active holds that have not expired. It
+// takes the wallet ID, the active status and the current time.
+const heldAmountQuery = `SELECT COALESCE(SUM(amount), 0) FROM holds
+	WHERE wallet_id = ? AND status = ? AND (expires_at IS NULL OR expires_at > ?)`
+
+type HoldRepository struct {
+	db *sql.DB
+}
+
+func NewHoldRepository(db *sql.DB) *HoldRepository {
+	return &HoldRepository{db: db}
+}
+
+func scanHold(row rowScanner) (*wallet.Hold, error) {
+	h := &wallet.Hold{}
+	var expiresAt sql.NullTime
+	err := row.Scan(
+		&h.ID, &h.WalletID, &h.Amount, &h.CapturedAmount, &h.Reason, &h.Reference,
+		&h.RecipientWallet, &h.Status, &expiresAt, &h.CreatedAt, &h.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	if expiresAt.Valid {
+		h.ExpiresAt = &expiresAt.Time
+	}
+	return h, nil
+}
+
+func (r *HoldRepository) GetByID(id string) (*wallet.Hold, error) {
+	query := `SELECT ` + holdColumns + ` FROM holds WHERE id = ?`
+
+	h, err := scanHold(r.db.QueryRow(query, id))
+	if err == sql.ErrNoRows {
+		return nil, wallet.ErrHoldNotFound
+	}
+	return h, err
+}
+
+func (r *HoldRepository) GetByReference(walletID, reference string) (*wallet.Hold, error) {
+	query := `SELECT ` + holdColumns + ` FROM holds WHERE wallet_id = ? AND reference = ?`
+
+	h, err := scanHold(r.db.QueryRow(query, walletID, reference))
+	if err == sql.ErrNoRows {
+		return nil, wallet.ErrHoldNotFound
+	}
+	return h, err
+}
+
+func (r *HoldRepository) ListByWalletID(walletID string, status wallet.HoldStatus) ([]*wallet.Hold, error) {
+	query := `SELECT ` + holdColumns + ` FROM holds WHERE wallet_id = ?`
+	args := []interface{}{walletID}
+	if status != "" {
+		query += ` AND status = ?`
+		args = append(args, status)
+	}
+	query += ` ORDER BY created_at DESC, id DESC`
+
+	return r.queryHolds(query, args...)
+}
+
+func (r *HoldRepository) SumActive(walletID string, now time.Time) (int64, error) {
+	var sum int64
+	err := r.db.QueryRow(heldAmountQuery, walletID, wallet.HoldStatusActive, storedTime(now)).Scan(&sum)
+	return sum, err
+}
+
+func (r *HoldRepository) ListExpired(now time.Time, limit int) ([]*wallet.Hold, error) {
+	query := `SELECT ` + holdColumns + ` FROM holds
+		WHERE status = ? AND expires_at IS NOT NULL AND expires_at <= ?
+		ORDER BY expires_at LIMIT ?`
+
+	return r.queryHolds(query, wallet.HoldStatusActive, storedTime(now), limit)
+}
+
+func (r *HoldRepository) queryHolds(query string, args ...interface{}) ([]*wallet.Hold, error) {
+	rows, err := r.db.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	holds := []*wallet.Hold{}
+	for rows.Next() {
+		h, err := scanHold(rows)
+		if err != nil {
+			return nil, err
+		}
+		holds = append(holds, h)
+	}
+	return holds, rows.Err()
+}
@@ -0,0 +1,67 @@
+package repository
+
+import (
+	"database/sql"
+	"time"
+
+	"github.com/BerylCAtieno/paystack-wallet/internal/domain/idempotency"
+)
+
+type IdempotencyRepository struct {
+	db *sql.DB
+}
+
+func NewIdempotencyRepository(db *sql.DB) *IdempotencyRepository {
+	return &IdempotencyRepository{db: db}
+}
+
+func (r *IdempotencyRepository) Insert(record *idempotency.Record) (bool, error) {
+	query := `INSERT OR IGNORE INTO idempotency_keys (user_id, key, request_hash, status_code, created_at, expires_at)
+		VALUES (?, ?, ?, 0, ?, ?)`
+
+	res, err := r.db.Exec(query, record.UserID, record.Key, record.RequestHash, record.CreatedAt, record.ExpiresAt)
+	if err != nil {
+		return false, err
+	}
+
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	return affected == 1, nil
+}
+
+func (r *IdempotencyRepository) Get(userID, key string) (*idempotency.Record, error) {
+	query := `SELECT user_id, key, request_hash, status_code, response_body, created_at, expires_at
+		FROM idempotency_keys WHERE user_id = ? AND key = ?`
+
+	record := &idempotency.Record{}
+	err := r.db.QueryRow(query, userID, key).Scan(
+		&record.UserID, &record.Key, &record.RequestHash, &record.StatusCode,
+		&record.ResponseBody, &record.CreatedAt, &record.ExpiresAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return record, nil
+}
+
+func (r *IdempotencyRepository) SaveResponse(userID, key string, statusCode int, body []byte) error {
+	query := `UPDATE idempotency_keys SET status_code = ?, response_body = ? WHERE user_id = ? AND key = ?`
+
+	_, err := r.db.Exec(query, statusCode, body, userID, key)
+	return err
+}
+
+func (r *IdempotencyRepository) Delete(userID, key string) error {
+	_, err := r.db.Exec(`DELETE FROM idempotency_keys WHERE user_id = ? AND key = ?`, userID, key)
+	return err
+}
+
+func (r *IdempotencyRepository) DeleteExpired(now time.Time) (int64, error) {
+	res, err := r.db.Exec(`DELETE FROM idempotency_keys WHERE expires_at < ?`, now)
+	if err != nil {
+		return 0, err
+	}
+	return res.RowsAffected()
+}
@@ -0,0 +1,84 @@
+package repository
+
+import (
+	"database/sql"
+
+	"github.com/BerylCAtieno/paystack-wallet/internal/domain/ledger"
+)
+
+type LedgerRepository struct {
+	db *sql.DB
+}
+
+func NewLedgerRepository(db *sql.DB) *LedgerRepository {
+	return &LedgerRepository{db: db}
+}
+
+func (r *LedgerRepository) GetAccount(id string) (*ledger.Account, error) {
+	query := `SELECT id, name, type, currency, COALESCE(wallet_id, ''), created_at
+		FROM ledger_accounts WHERE id = ?`
+
+	a := &ledger.Account{}
+	err := r.db.QueryRow(query, id).Scan(&a.ID, &a.Name, &a.Type, &a.Currency, &a.WalletID, &a.CreatedAt)
+	if err == sql.ErrNoRows {
+		return nil, ledger.ErrAccountNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return a, nil
+}
+
+// GetAccountBalance returns the account's natural balance computed from its
+// postings, ignoring any cached projection.
+func (r *LedgerRepository) GetAccountBalance(id string) (int64, error) {
+	account, err := r.GetAccount(id)
+	if err != nil {
+		return 0, err
+	}
+
+	var sum int64
+	query := `SELECT COALESCE(SUM(amount), 0) FROM postings WHERE account_id = ?`
+	if err := r.db.QueryRow(query, id).Scan(&sum); err != nil {
+		return 0, err
+	}
+
+	return ledger.Balance(account.Type, sum), nil
+}
+
+func (r *LedgerRepository) ListEntriesByReference(reference string) ([]*ledger.JournalEntry, error) {
+	query := `SELECT e.id, e.reference, COALESCE(e.description, ''), e.created_at,
+			p.id, p.account_id, p.amount, p.created_at
+		FROM journal_entries e
+		JOIN postings p ON p.entry_id = e.id
+		WHERE e.reference = ?
+		ORDER BY e.created_at, e.id, p.id`
+
+	rows, err := r.db.Query(query, reference)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var entries []*ledger.JournalEntry
+	var current *ledger.JournalEntry
+	for rows.Next() {
+		e := ledger.JournalEntry{}
+		p := ledger.Posting{}
+		if err := rows.Scan(
+			&e.ID, &e.Reference, &e.Description, &e.CreatedAt,
+			&p.ID, &p.AccountID, &p.Amount, &p.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+
+		if current == nil || current.ID != e.ID {
+			current = &e
+			entries = append(entries, current)
+		}
+		p.EntryID = current.ID
+		current.Postings = append(current.Postings, p)
+	}
+
+	return entries, rows.Err()
+}
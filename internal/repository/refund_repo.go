@@ -0,0 +1,52 @@
+package repository
+
+import (
+	"database/sql"
+
+	"github.com/BerylCAtieno/paystack-wallet/internal/domain/refund"
+)
+
+const refundColumns = `id, paystack_id, deposit_reference, wallet_id, transaction_reference, amount, currency, status, created_at, updated_at`
+
+type RefundRepository struct {
+	db *sql.DB
+}
+
+func NewRefundRepository(db *sql.DB) *RefundRepository {
+	return &RefundRepository{db: db}
+}
+
+func scanRefund(row rowScanner) (*refund.Refund, error) {
+	rf := &refund.Refund{}
+	err := row.Scan(
+		&rf.ID, &rf.PaystackID, &rf.DepositReference, &rf.WalletID, &rf.TransactionReference, &rf.Amount,
+		&rf.Currency, &rf.Status, &rf.CreatedAt, &rf.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return rf, nil
+}
+
+func (r *RefundRepository) Create(rf *refund.Refund) error {
+	query := `INSERT INTO refunds (` + refundColumns + `) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`
+
+	_, err := r.db.Exec(query,
+		rf.ID, rf.PaystackID, rf.DepositReference, rf.WalletID, rf.TransactionReference, rf.Amount,
+		rf.Currency, rf.Status, rf.CreatedAt, rf.UpdatedAt,
+	)
+	return err
+}
+
+func (r *RefundRepository) GetByPaystackID(paystackID string) (*refund.Refund, error) {
+	query := `SELECT ` + refundColumns + ` FROM refunds WHERE paystack_id = ?`
+
+	return scanRefund(r.db.QueryRow(query, paystackID))
+}
+
+func (r *RefundRepository) Update(rf *refund.Refund) error {
+	query := `UPDATE refunds SET status = ?, wallet_id = ?, transaction_reference = ?, updated_at = ? WHERE id = ?`
+
+	_, err := r.db.Exec(query, rf.Status, rf.WalletID, rf.TransactionReference, rf.UpdatedAt, rf.ID)
+	return err
+}
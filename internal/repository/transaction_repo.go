@@ -2,10 +2,14 @@ package repository
 
 import (
 	"database/sql"
+	"strings"
+	"time"
 
 	"github.com/BerylCAtieno/paystack-wallet/internal/domain/wallet"
 )
 
+const transactionColumns = `id, wallet_id, type, amount, currency, status, reference, recipient_wallet, metadata, journal_entry_id, created_at, updated_at`
+
 type TransactionRepository struct {
 	db *sql.DB
 }
@@ -14,44 +18,129 @@ func NewTransactionRepository(db *sql.DB) *TransactionRepository {
 	return &TransactionRepository{db: db}
 }
 
+// rowScanner is satisfied by both *sql.Row and *sql.Rows
+type rowScanner interface {
+	Scan(dest ...interface{}) error
+}
+
+func scanTransaction(row rowScanner) (*wallet.Transaction, error) {
+	tx := &wallet.Transaction{}
+	err := row.Scan(
+		&tx.ID, &tx.WalletID, &tx.Type, &tx.Amount, &tx.Currency, &tx.Status,
+		&tx.Reference, &tx.RecipientWallet, &tx.Metadata, &tx.JournalEntryID, &tx.CreatedAt, &tx.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return tx, nil
+}
+
 func (r *TransactionRepository) Create(tx *wallet.Transaction) error {
-	query := `INSERT INTO transactions (id, wallet_id, type, amount, status, reference, recipient_wallet, metadata, created_at, updated_at)
-		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`
+	query := `INSERT INTO transactions (` + transactionColumns + `)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`
 
 	_, err := r.db.Exec(query,
-		tx.ID, tx.WalletID, tx.Type, tx.Amount, tx.Status,
-		tx.Reference, tx.RecipientWallet, tx.Metadata, tx.CreatedAt, tx.UpdatedAt,
+		tx.ID, tx.WalletID, tx.Type, tx.Amount, tx.Currency, tx.Status,
+		tx.Reference, tx.RecipientWallet, tx.Metadata, tx.JournalEntryID, tx.CreatedAt, tx.UpdatedAt,
 	)
+	if isUniqueViolation(err, "transactions.reference") {
+		return wallet.ErrDuplicateReference
+	}
 	return err
 }
 
 func (r *TransactionRepository) GetByReference(reference string) (*wallet.Transaction, error) {
-	query := `SELECT id, wallet_id, type, amount, status, reference, recipient_wallet, metadata, created_at, updated_at
-		FROM transactions WHERE reference = ?`
+	query := `SELECT ` + transactionColumns + ` FROM transactions WHERE reference = ?`
 
-	tx := &wallet.Transaction{}
-	err := r.db.QueryRow(query, reference).Scan(
-		&tx.ID, &tx.WalletID, &tx.Type, &tx.Amount, &tx.Status,
-		&tx.Reference, &tx.RecipientWallet, &tx.Metadata, &tx.CreatedAt, &tx.UpdatedAt,
-	)
-	if err != nil {
-		return nil, err
+	tx, err := scanTransaction(r.db.QueryRow(query, reference))
+	if err == sql.ErrNoRows {
+		return nil, wallet.ErrTransactionNotFound
 	}
-	return tx, nil
+	return tx, err
 }
 
-func (r *TransactionRepository) Update(tx *wallet.Transaction) error {
-	query := `UPDATE transactions SET status = ?, updated_at = ? WHERE id = ?`
+// GetUnlinkedRefund returns the oldest pending refund of amount against the
+// deposit that no Paystack refund has been recorded for yet
+func (r *TransactionRepository) GetUnlinkedRefund(depositReference string, amount int64) (*wallet.Transaction, error) {
+	query := `SELECT ` + transactionColumns + ` FROM transactions
+		WHERE type = ? AND status = ? AND amount = ?
+			AND json_extract(metadata, '$.deposit_reference') = ?
+			AND reference NOT IN (SELECT transaction_reference FROM refunds)
+		ORDER BY created_at, id LIMIT 1`
 
-	_, err := r.db.Exec(query, tx.Status, tx.UpdatedAt, tx.ID)
-	return err
+	tx, err := scanTransaction(r.db.QueryRow(query,
+		wallet.TransactionTypeRefund, wallet.TransactionStatusPending, -amount, depositReference,
+	))
+	if err == sql.ErrNoRows {
+		return nil, wallet.ErrTransactionNotFound
+	}
+	return tx, err
 }
 
-func (r *TransactionRepository) ListByWalletID(walletID string) ([]*wallet.Transaction, error) {
-	query := `SELECT id, wallet_id, type, amount, status, reference, recipient_wallet, metadata, created_at, updated_at
-		FROM transactions WHERE wallet_id = ? ORDER BY created_at DESC`
+// UpdateMetadata leaves the status alone so a copy read before a webhook
+// settled the transaction cannot move it back
+func (r *TransactionRepository) UpdateMetadata(id string, status wallet.TransactionStatus, metadata string) (bool, error) {
+	query := `UPDATE transactions SET metadata = ?, updated_at = ? WHERE id = ? AND status = ?`
+
+	res, err := r.db.Exec(query, metadata, time.Now(), id, status)
+	if err != nil {
+		return false, err
+	}
 
-	rows, err := r.db.Query(query, walletID)
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	return affected == 1, nil
+}
+
+// ListByWalletID pages through a wallet's history in (created_at, id)
+// descending order. Seeking past the cursor instead of using OFFSET keeps
+// deep pages as cheap as the first one on idx_transactions_wallet_created.
+func (r *TransactionRepository) ListByWalletID(walletID string, filter wallet.TransactionFilter, after *wallet.TransactionCursor, limit int) ([]*wallet.Transaction, error) {
+	conditions := []string{"wallet_id = ?"}
+	args := []interface{}{walletID}
+
+	if filter.Type != "" {
+		conditions = append(conditions, "type = ?")
+		args = append(args, filter.Type)
+	}
+	if filter.Status != "" {
+		conditions = append(conditions, "status = ?")
+		args = append(args, filter.Status)
+	}
+	if !filter.From.IsZero() {
+		conditions = append(conditions, "created_at >= ?")
+		args = append(args, storedTime(filter.From))
+	}
+	if !filter.To.IsZero() {
+		conditions = append(conditions, "created_at <= ?")
+		args = append(args, storedTime(filter.To))
+	}
+	if filter.MinAmount > 0 {
+		conditions = append(conditions, "ABS(amount) >= ?")
+		args = append(args, filter.MinAmount)
+	}
+	if filter.MaxAmount > 0 {
+		conditions = append(conditions, "ABS(amount) <= ?")
+		args = append(args, filter.MaxAmount)
+	}
+	if filter.Counterparty != "" {
+		conditions = append(conditions, "recipient_wallet = ?")
+		args = append(args, filter.Counterparty)
+	}
+	if after != nil {
+		createdAt := storedTime(after.CreatedAt)
+		conditions = append(conditions, "(created_at < ? OR (created_at = ? AND id < ?))")
+		args = append(args, createdAt, createdAt, after.ID)
+	}
+
+	query := `SELECT ` + transactionColumns + ` FROM transactions
+		WHERE ` + strings.Join(conditions, " AND ") + `
+		ORDER BY created_at DESC, id DESC LIMIT ?`
+	args = append(args, limit)
+
+	rows, err := r.db.Query(query, args...)
 	if err != nil {
 		return nil, err
 	}
@@ -59,16 +148,77 @@ func (r *TransactionRepository) ListByWalletID(walletID string) ([]*wallet.Trans
 
 	var transactions []*wallet.Transaction
 	for rows.Next() {
-		tx := &wallet.Transaction{}
-		err := rows.Scan(
-			&tx.ID, &tx.WalletID, &tx.Type, &tx.Amount, &tx.Status,
-			&tx.Reference, &tx.RecipientWallet, &tx.Metadata, &tx.CreatedAt, &tx.UpdatedAt,
-		)
+		tx, err := scanTransaction(rows)
 		if err != nil {
 			return nil, err
 		}
 		transactions = append(transactions, tx)
 	}
 
-	return transactions, nil
+	return transactions, rows.Err()
+}
+
+func (r *TransactionRepository) ListByStatus(txType wallet.TransactionType, status wallet.TransactionStatus, createdBefore time.Time, after *wallet.TransactionCursor, limit int) ([]*wallet.Transaction, error) {
+	query := `SELECT ` + transactionColumns + ` FROM transactions
+		WHERE type = ? AND status = ? AND created_at < ?`
+	args := []interface{}{txType, status, storedTime(createdBefore)}
+
+	if after != nil {
+		createdAt := storedTime(after.CreatedAt)
+		query += ` AND (created_at > ? OR (created_at = ? AND id > ?))`
+		args = append(args, createdAt, createdAt, after.ID)
+	}
+
+	query += ` ORDER BY created_at, id LIMIT ?`
+	args = append(args, limit)
+
+	rows, err := r.db.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	transactions := []*wallet.Transaction{}
+	for rows.Next() {
+		tx, err := scanTransaction(rows)
+		if err != nil {
+			return nil, err
+		}
+		transactions = append(transactions, tx)
+	}
+
+	return transactions, rows.Err()
+}
+
+// storedTime converts t to the server's zone. created_at is stored as text
+// in the zone it was written in, so comparisons only order correctly when
+// both sides use the same offset.
+func storedTime(t time.Time) time.Time {
+	return t.In(time.Local)
+}
+
+// usageQuery sums the absolute amounts of a wallet's transactions of one
+// type since a time that count towards its limits. It takes the wallet ID,
+// the type, the pending, review and success statuses and the start time.
+const usageQuery = `SELECT COALESCE(SUM(ABS(amount)), 0) FROM transactions
+	WHERE wallet_id = ? AND type = ? AND status IN (?, ?, ?) AND created_at >= ?`
+
+func (r *TransactionRepository) SumUsage(walletID string, txType wallet.TransactionType, since time.Time) (int64, error) {
+	var sum int64
+	err := r.db.QueryRow(usageQuery, walletID, txType,
+		wallet.TransactionStatusPending, wallet.TransactionStatusReview, wallet.TransactionStatusSuccess, storedTime(since),
+	).Scan(&sum)
+	return sum, err
+}
+
+// SumBalanceByWalletID recomputes a wallet balance from its transaction
+// history. Only successful transactions have moved money; pending
+// withdrawals and refunds reserve funds with holds instead. Debits are
+// stored as negative amounts.
+func (r *TransactionRepository) SumBalanceByWalletID(walletID string) (int64, error) {
+	query := `SELECT COALESCE(SUM(amount), 0) FROM transactions WHERE wallet_id = ? AND status = ?`
+
+	var sum int64
+	err := r.db.QueryRow(query, walletID, wallet.TransactionStatusSuccess).Scan(&sum)
+	return sum, err
 }
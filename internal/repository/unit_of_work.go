@@ -0,0 +1,307 @@
+package repository
+
+import (
+	"database/sql"
+	"time"
+
+	"github.com/BerylCAtieno/paystack-wallet/internal/domain/fx"
+	"github.com/BerylCAtieno/paystack-wallet/internal/domain/ledger"
+	"github.com/BerylCAtieno/paystack-wallet/internal/domain/wallet"
+)
+
+// UnitOfWork implements wallet.TransactionInterface on top of a sql.Tx so
+// journal entries, balance projections and transaction rows commit or roll
+// back together.
+type UnitOfWork struct {
+	tx *sql.Tx
+}
+
+func (u *UnitOfWork) Commit() error {
+	return u.tx.Commit()
+}
+
+// Rollback is safe to defer; it is a no-op once the transaction is committed.
+func (u *UnitOfWork) Rollback() error {
+	if err := u.tx.Rollback(); err != nil && err != sql.ErrTxDone {
+		return err
+	}
+	return nil
+}
+
+func (u *UnitOfWork) GetWallet(walletID string) (*wallet.Wallet, error) {
+	query := `SELECT ` + walletColumns + ` FROM wallets WHERE id = ?`
+
+	w, err := scanWallet(u.tx.QueryRow(query, walletID))
+	if err == sql.ErrNoRows {
+		return nil, wallet.ErrWalletNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return w, nil
+}
+
+func (u *UnitOfWork) UpdateWalletBalance(walletID string, newBalance int64) error {
+	query := `UPDATE wallets SET balance = ?, updated_at = ? WHERE id = ?`
+
+	res, err := u.tx.Exec(query, newBalance, time.Now(), walletID)
+	if err != nil {
+		return err
+	}
+	return expectRow(res, wallet.ErrWalletNotFound)
+}
+
+// debitWallet lowers the cached balance only if the available balance,
+// what is left after active holds, covers the amount.
+func (u *UnitOfWork) debitWallet(walletID string, amount int64) error {
+	now := time.Now()
+	query := `UPDATE wallets SET balance = balance - ?, updated_at = ? 
+		WHERE id = ? AND balance - (` + heldAmountQuery + `) >= ?`
+
+	res, err := u.tx.Exec(query, amount, now, walletID,
+		walletID, wallet.HoldStatusActive, storedTime(now), amount)
+	if err != nil {
+		return err
+	}
+
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if affected == 0 {
+		// Distinguish a missing wallet from one that cannot cover the debit
+		if _, err := u.GetWallet(walletID); err != nil {
+			return err
+		}
+		return wallet.ErrInsufficientBalance
+	}
+	return nil
+}
+
+func (u *UnitOfWork) creditWallet(walletID string, amount int64) error {
+	query := `UPDATE wallets SET balance = balance + ?, updated_at = ? WHERE id = ?`
+
+	res, err := u.tx.Exec(query, amount, time.Now(), walletID)
+	if err != nil {
+		return err
+	}
+	return expectRow(res, wallet.ErrWalletNotFound)
+}
+
+func (u *UnitOfWork) CreateTransaction(t *wallet.Transaction) error {
+	query := `INSERT INTO transactions (` + transactionColumns + `)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`
+
+	_, err := u.tx.Exec(query,
+		t.ID, t.WalletID, t.Type, t.Amount, t.Currency, t.Status,
+		t.Reference, t.RecipientWallet, t.Metadata, t.JournalEntryID, t.CreatedAt, t.UpdatedAt,
+	)
+	if isUniqueViolation(err, "transactions.reference") {
+		return wallet.ErrDuplicateReference
+	}
+	return err
+}
+
+func (u *UnitOfWork) LinkJournalEntry(transactionID, entryID string) error {
+	query := `UPDATE transactions SET journal_entry_id = ?, updated_at = ? WHERE id = ?`
+
+	res, err := u.tx.Exec(query, entryID, time.Now(), transactionID)
+	if err != nil {
+		return err
+	}
+	return expectRow(res, wallet.ErrTransactionNotFound)
+}
+
+func (u *UnitOfWork) UpdateTransactionDetails(id string, amount int64, metadata string) error {
+	query := `UPDATE transactions SET amount = ?, metadata = ?, updated_at = ? WHERE id = ?`
+
+	res, err := u.tx.Exec(query, amount, metadata, time.Now(), id)
+	if err != nil {
+		return err
+	}
+	return expectRow(res, wallet.ErrTransactionNotFound)
+}
+
+func (u *UnitOfWork) RefundedAmount(depositReference string) (int64, error) {
+	query := `SELECT COALESCE(SUM(-amount), 0) FROM transactions
+		WHERE type = ? AND status IN (?, ?) AND json_extract(metadata, '$.deposit_reference') = ?`
+
+	var sum int64
+	err := u.tx.QueryRow(query,
+		wallet.TransactionTypeRefund, wallet.TransactionStatusPending, wallet.TransactionStatusSuccess, depositReference,
+	).Scan(&sum)
+	return sum, err
+}
+
+func (u *UnitOfWork) SumUsage(walletID string, txType wallet.TransactionType, since time.Time) (int64, error) {
+	var sum int64
+	err := u.tx.QueryRow(usageQuery, walletID, txType,
+		wallet.TransactionStatusPending, wallet.TransactionStatusReview, wallet.TransactionStatusSuccess, storedTime(since),
+	).Scan(&sum)
+	return sum, err
+}
+
+// CreateHold inserts the hold only if the wallet's available balance covers
+// it, so concurrent holds and debits cannot reserve the same funds twice.
+func (u *UnitOfWork) CreateHold(h *wallet.Hold) error {
+	query := `INSERT INTO holds (` + holdColumns + `)
+		SELECT ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?
+		FROM wallets WHERE id = ? AND balance - (` + heldAmountQuery + `) >= ?`
+
+	res, err := u.tx.Exec(query,
+		h.ID, h.WalletID, h.Amount, h.CapturedAmount, h.Reason, h.Reference,
+		h.RecipientWallet, h.Status, h.ExpiresAt, h.CreatedAt, h.UpdatedAt,
+		h.WalletID, h.WalletID, wallet.HoldStatusActive, storedTime(time.Now()), h.Amount,
+	)
+	if isUniqueViolation(err, "holds.reference") {
+		return wallet.ErrDuplicateHoldReference
+	}
+	if err != nil {
+		return err
+	}
+
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if affected == 0 {
+		if _, err := u.GetWallet(h.WalletID); err != nil {
+			return err
+		}
+		return wallet.ErrInsufficientBalance
+	}
+	return nil
+}
+
+func (u *UnitOfWork) CloseHold(id string, to wallet.HoldStatus, capturedAmount int64) (bool, error) {
+	query := `UPDATE holds SET status = ?, captured_amount = ?, updated_at = ? WHERE id = ? AND status = ?`
+
+	res, err := u.tx.Exec(query, to, capturedAmount, time.Now(), id, wallet.HoldStatusActive)
+	if err != nil {
+		return false, err
+	}
+
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	return affected == 1, nil
+}
+
+// ConsumeQuote marks the quote used only while it is open and unexpired, in
+// the same transaction as the money it moves.
+func (u *UnitOfWork) ConsumeQuote(id string) (bool, error) {
+	query := `UPDATE fx_quotes SET status = ? WHERE id = ? AND status = ? AND expires_at > ?`
+
+	res, err := u.tx.Exec(query, fx.QuoteStatusUsed, id, fx.QuoteStatusOpen, storedTime(time.Now()))
+	if err != nil {
+		return false, err
+	}
+
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	return affected == 1, nil
+}
+
+// PostEntry persists a balanced journal entry and applies each wallet
+// posting to the cached wallets.balance projection. A posting that would
+// take a wallet below zero fails with wallet.ErrInsufficientBalance.
+func (u *UnitOfWork) PostEntry(entry *ledger.JournalEntry) error {
+	if err := entry.Validate(); err != nil {
+		return err
+	}
+
+	// Postings must also balance within each currency
+	sums := make(map[string]int64)
+	accounts := make(map[string]*ledger.Account, len(entry.Postings))
+	for _, p := range entry.Postings {
+		account, err := u.getAccount(p.AccountID)
+		if err != nil {
+			return err
+		}
+		accounts[p.AccountID] = account
+		sums[account.Currency] += p.Amount
+	}
+	for _, sum := range sums {
+		if sum != 0 {
+			return ledger.ErrUnbalancedEntry
+		}
+	}
+
+	entryQuery := `INSERT INTO journal_entries (id, reference, description, created_at)
+		VALUES (?, ?, ?, ?)`
+
+	if _, err := u.tx.Exec(entryQuery, entry.ID, entry.Reference, entry.Description, entry.CreatedAt); err != nil {
+		return err
+	}
+
+	postingQuery := `INSERT INTO postings (id, entry_id, account_id, amount, created_at)
+		VALUES (?, ?, ?, ?, ?)`
+
+	for _, p := range entry.Postings {
+		if _, err := u.tx.Exec(postingQuery, p.ID, entry.ID, p.AccountID, p.Amount, p.CreatedAt); err != nil {
+			return err
+		}
+
+		account := accounts[p.AccountID]
+		if account.WalletID == "" {
+			continue
+		}
+
+		delta := ledger.Balance(account.Type, p.Amount)
+		if delta < 0 {
+			if err := u.debitWallet(account.WalletID, -delta); err != nil {
+				return err
+			}
+		} else {
+			if err := u.creditWallet(account.WalletID, delta); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+func (u *UnitOfWork) getAccount(id string) (*ledger.Account, error) {
+	query := `SELECT id, name, type, currency, COALESCE(wallet_id, ''), created_at
+		FROM ledger_accounts WHERE id = ?`
+
+	a := &ledger.Account{}
+	err := u.tx.QueryRow(query, id).Scan(&a.ID, &a.Name, &a.Type, &a.Currency, &a.WalletID, &a.CreatedAt)
+	if err == sql.ErrNoRows {
+		return nil, ledger.ErrAccountNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return a, nil
+}
+
+func (u *UnitOfWork) UpdateTransactionStatus(id string, from, to wallet.TransactionStatus) (bool, error) {
+	query := `UPDATE transactions SET status = ?, updated_at = ? WHERE id = ? AND status = ?`
+
+	res, err := u.tx.Exec(query, to, time.Now(), id, from)
+	if err != nil {
+		return false, err
+	}
+
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	return affected == 1, nil
+}
+
+func expectRow(res sql.Result, notFound error) error {
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if affected == 0 {
+		return notFound
+	}
+	return nil
+}
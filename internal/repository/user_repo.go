@@ -2,10 +2,13 @@ package repository
 
 import (
 	"database/sql"
+	"time"
 
 	"github.com/BerylCAtieno/paystack-wallet/internal/domain/user"
 )
 
+const userColumns = `id, email, name, google_id, kyc_tier, created_at, updated_at`
+
 type UserRepository struct {
 	db *sql.DB
 }
@@ -14,49 +17,53 @@ func NewUserRepository(db *sql.DB) *UserRepository {
 	return &UserRepository{db: db}
 }
 
+func scanUser(row rowScanner) (*user.User, error) {
+	u := &user.User{}
+	err := row.Scan(&u.ID, &u.Email, &u.Name, &u.GoogleID, &u.Tier, &u.CreatedAt, &u.UpdatedAt)
+	if err != nil {
+		return nil, err
+	}
+	return u, nil
+}
+
 func (r *UserRepository) Create(u *user.User) error {
-	query := `INSERT INTO users (id, email, name, google_id, created_at, updated_at)
-		VALUES (?, ?, ?, ?, ?, ?)`
+	query := `INSERT INTO users (` + userColumns + `) VALUES (?, ?, ?, ?, ?, ?, ?)`
 
-	_, err := r.db.Exec(query, u.ID, u.Email, u.Name, u.GoogleID, u.CreatedAt, u.UpdatedAt)
+	_, err := r.db.Exec(query, u.ID, u.Email, u.Name, u.GoogleID, u.Tier, u.CreatedAt, u.UpdatedAt)
 	return err
 }
 
-func (r *UserRepository) GetByID(id string) (*user.User, error) {
-	query := `SELECT id, email, name, google_id, created_at, updated_at FROM users WHERE id = ?`
+// CreateIfMissing inserts u unless a user with its ID already exists
+func (r *UserRepository) CreateIfMissing(u *user.User) error {
+	query := `INSERT OR IGNORE INTO users (` + userColumns + `) VALUES (?, ?, ?, ?, ?, ?, ?)`
 
-	u := &user.User{}
-	err := r.db.QueryRow(query, id).Scan(
-		&u.ID, &u.Email, &u.Name, &u.GoogleID, &u.CreatedAt, &u.UpdatedAt,
-	)
-	if err != nil {
-		return nil, err
-	}
-	return u, nil
+	_, err := r.db.Exec(query, u.ID, u.Email, u.Name, u.GoogleID, u.Tier, u.CreatedAt, u.UpdatedAt)
+	return err
 }
 
-func (r *UserRepository) GetByGoogleID(googleID string) (*user.User, error) {
-	query := `SELECT id, email, name, google_id, created_at, updated_at FROM users WHERE google_id = ?`
+func (r *UserRepository) GetByID(id string) (*user.User, error) {
+	query := `SELECT ` + userColumns + ` FROM users WHERE id = ?`
+	return scanUser(r.db.QueryRow(query, id))
+}
 
-	u := &user.User{}
-	err := r.db.QueryRow(query, googleID).Scan(
-		&u.ID, &u.Email, &u.Name, &u.GoogleID, &u.CreatedAt, &u.UpdatedAt,
-	)
-	if err != nil {
-		return nil, err
-	}
-	return u, nil
+func (r *UserRepository) GetByGoogleID(googleID string) (*user.User, error) {
+	query := `SELECT ` + userColumns + ` FROM users WHERE google_id = ?`
+	return scanUser(r.db.QueryRow(query, googleID))
 }
 
 func (r *UserRepository) GetByEmail(email string) (*user.User, error) {
-	query := `SELECT id, email, name, google_id, created_at, updated_at FROM users WHERE email = ?`
+	query := `SELECT ` + userColumns + ` FROM users WHERE email = ?`
+	return scanUser(r.db.QueryRow(query, email))
+}
 
-	u := &user.User{}
-	err := r.db.QueryRow(query, email).Scan(
-		&u.ID, &u.Email, &u.Name, &u.GoogleID, &u.CreatedAt, &u.UpdatedAt,
-	)
+// UpdateTier moves the user to a KYC tier, returning sql.ErrNoRows if there
+// is no such user
+func (r *UserRepository) UpdateTier(id string, tier user.Tier) error {
+	query := `UPDATE users SET kyc_tier = ?, updated_at = ? WHERE id = ?`
+
+	res, err := r.db.Exec(query, tier, time.Now(), id)
 	if err != nil {
-		return nil, err
+		return err
 	}
-	return u, nil
+	return expectRow(res, sql.ErrNoRows)
 }
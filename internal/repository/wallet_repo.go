@@ -2,10 +2,15 @@ package repository
 
 import (
 	"database/sql"
+	"time"
 
+	"github.com/BerylCAtieno/paystack-wallet/internal/domain/ledger"
 	"github.com/BerylCAtieno/paystack-wallet/internal/domain/wallet"
+	"github.com/BerylCAtieno/paystack-wallet/internal/money"
 )
 
+const walletColumns = `id, user_id, wallet_number, balance, currency, status, created_at, updated_at`
+
 type WalletRepository struct {
 	db *sql.DB
 }
@@ -14,65 +19,113 @@ func NewWalletRepository(db *sql.DB) *WalletRepository {
 	return &WalletRepository{db: db}
 }
 
+// Create inserts the wallet together with the ledger account its balance is
+// projected from.
 func (r *WalletRepository) Create(w *wallet.Wallet) error {
-	query := `INSERT INTO wallets (id, user_id, wallet_number, balance, created_at, updated_at)
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	query := `INSERT INTO wallets (` + walletColumns + `)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?)`
+
+	if _, err := tx.Exec(query, w.ID, w.UserID, w.WalletNumber, w.Balance, w.Currency, w.Status, w.CreatedAt, w.UpdatedAt); err != nil {
+		if isUniqueViolation(err, "wallets.wallet_number") {
+			return wallet.ErrDuplicateWalletNumber
+		}
+		if isUniqueViolation(err, "wallets.currency") {
+			return wallet.ErrWalletExists
+		}
+		return err
+	}
+
+	accountQuery := `INSERT INTO ledger_accounts (id, name, type, currency, wallet_id, created_at)
 		VALUES (?, ?, ?, ?, ?, ?)`
 
-	_, err := r.db.Exec(query, w.ID, w.UserID, w.WalletNumber, w.Balance, w.CreatedAt, w.UpdatedAt)
-	return err
+	if _, err := tx.Exec(accountQuery,
+		ledger.WalletAccountID(w.ID), "Wallet "+w.WalletNumber, ledger.AccountTypeLiability,
+		string(w.Currency), w.ID, w.CreatedAt,
+	); err != nil {
+		return err
+	}
+
+	return tx.Commit()
 }
 
-func (r *WalletRepository) GetByUserID(userID string) (*wallet.Wallet, error) {
-	query := `SELECT id, user_id, wallet_number, balance, created_at, updated_at 
-		FROM wallets WHERE user_id = ?`
+func (r *WalletRepository) GetByUserID(userID string, currency money.Currency) (*wallet.Wallet, error) {
+	query := `SELECT ` + walletColumns + ` FROM wallets WHERE user_id = ? AND currency = ?`
 
-	w := &wallet.Wallet{}
-	err := r.db.QueryRow(query, userID).Scan(
-		&w.ID, &w.UserID, &w.WalletNumber, &w.Balance, &w.CreatedAt, &w.UpdatedAt,
-	)
-	if err != nil {
-		return nil, err
-	}
-	return w, nil
+	return scanWallet(r.db.QueryRow(query, userID, currency))
+}
+
+func (r *WalletRepository) ListByUserID(userID string) ([]*wallet.Wallet, error) {
+	query := `SELECT ` + walletColumns + ` FROM wallets WHERE user_id = ? ORDER BY created_at, id`
+
+	return r.list(query, userID)
 }
 
 func (r *WalletRepository) GetByWalletNumber(walletNumber string) (*wallet.Wallet, error) {
-	query := `SELECT id, user_id, wallet_number, balance, created_at, updated_at 
-		FROM wallets WHERE wallet_number = ?`
+	query := `SELECT ` + walletColumns + ` FROM wallets WHERE wallet_number = ?`
 
-	w := &wallet.Wallet{}
-	err := r.db.QueryRow(query, walletNumber).Scan(
-		&w.ID, &w.UserID, &w.WalletNumber, &w.Balance, &w.CreatedAt, &w.UpdatedAt,
-	)
-	if err != nil {
-		return nil, err
-	}
-	return w, nil
+	return scanWallet(r.db.QueryRow(query, walletNumber))
 }
 
 func (r *WalletRepository) GetByID(id string) (*wallet.Wallet, error) {
-	query := `SELECT id, user_id, wallet_number, balance, created_at, updated_at 
-		FROM wallets WHERE id = ?`
+	query := `SELECT ` + walletColumns + ` FROM wallets WHERE id = ?`
 
-	w := &wallet.Wallet{}
-	err := r.db.QueryRow(query, id).Scan(
-		&w.ID, &w.UserID, &w.WalletNumber, &w.Balance, &w.CreatedAt, &w.UpdatedAt,
-	)
+	return scanWallet(r.db.QueryRow(query, id))
+}
+
+// List returns every wallet, oldest first
+func (r *WalletRepository) List() ([]*wallet.Wallet, error) {
+	query := `SELECT ` + walletColumns + ` FROM wallets ORDER BY created_at, id`
+
+	return r.list(query)
+}
+
+func (r *WalletRepository) list(query string, args ...interface{}) ([]*wallet.Wallet, error) {
+	rows, err := r.db.Query(query, args...)
 	if err != nil {
 		return nil, err
 	}
-	return w, nil
+	defer rows.Close()
+
+	var wallets []*wallet.Wallet
+	for rows.Next() {
+		w, err := scanWallet(rows)
+		if err != nil {
+			return nil, err
+		}
+		wallets = append(wallets, w)
+	}
+
+	return wallets, rows.Err()
 }
 
-func (r *WalletRepository) UpdateBalance(walletID string, amount int64) error {
-	query := `UPDATE wallets SET balance = balance + ?, updated_at = CURRENT_TIMESTAMP 
-		WHERE id = ?`
+func (r *WalletRepository) UpdateStatus(walletID string, status wallet.WalletStatus) error {
+	query := `UPDATE wallets SET status = ?, updated_at = ? WHERE id = ?`
 
-	_, err := r.db.Exec(query, amount, walletID)
+	_, err := r.db.Exec(query, status, time.Now(), walletID)
 	return err
 }
 
-func (r *WalletRepository) BeginTx() (wallet.Transaction, error) {
-	// For simplicity, we're not implementing full transaction support in this version
-	return wallet.Transaction{}, nil
+func (r *WalletRepository) BeginTx() (wallet.TransactionInterface, error) {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return nil, err
+	}
+	return &UnitOfWork{tx: tx}, nil
+}
+
+func scanWallet(row rowScanner) (*wallet.Wallet, error) {
+	w := &wallet.Wallet{}
+	err := row.Scan(
+		&w.ID, &w.UserID, &w.WalletNumber, &w.Balance, &w.Currency, &w.Status, &w.CreatedAt, &w.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return w, nil
 }
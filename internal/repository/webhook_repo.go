@@ -0,0 +1,129 @@
+package repository
+
+import (
+	"database/sql"
+	"time"
+
+	"github.com/BerylCAtieno/paystack-wallet/internal/domain/webhook"
+)
+
+const webhookEventColumns = `id, event_type, paystack_id, payload, status, attempts, last_error, next_attempt_at, received_at, processed_at`
+
+type WebhookEventRepository struct {
+	db *sql.DB
+}
+
+func NewWebhookEventRepository(db *sql.DB) *WebhookEventRepository {
+	return &WebhookEventRepository{db: db}
+}
+
+func scanWebhookEvent(row rowScanner) (*webhook.Event, error) {
+	e := &webhook.Event{}
+	var payload []byte
+	var processedAt sql.NullTime
+	err := row.Scan(
+		&e.ID, &e.EventType, &e.PaystackID, &payload, &e.Status, &e.Attempts,
+		&e.LastError, &e.NextAttemptAt, &e.ReceivedAt, &processedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	e.Payload = payload
+	if processedAt.Valid {
+		e.ProcessedAt = &processedAt.Time
+	}
+	return e, nil
+}
+
+func (r *WebhookEventRepository) Insert(e *webhook.Event) (bool, error) {
+	query := `INSERT OR IGNORE INTO webhook_events (` + webhookEventColumns + `)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`
+
+	res, err := r.db.Exec(query,
+		e.ID, e.EventType, e.PaystackID, []byte(e.Payload), e.Status, e.Attempts,
+		e.LastError, e.NextAttemptAt, e.ReceivedAt, e.ProcessedAt,
+	)
+	if err != nil {
+		return false, err
+	}
+
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	return affected == 1, nil
+}
+
+func (r *WebhookEventRepository) GetByID(id string) (*webhook.Event, error) {
+	query := `SELECT ` + webhookEventColumns + ` FROM webhook_events WHERE id = ?`
+
+	return scanWebhookEvent(r.db.QueryRow(query, id))
+}
+
+func (r *WebhookEventRepository) List(status webhook.Status, limit int) ([]*webhook.Event, error) {
+	query := `SELECT ` + webhookEventColumns + ` FROM webhook_events`
+	args := []interface{}{}
+	if status != "" {
+		query += ` WHERE status = ?`
+		args = append(args, status)
+	}
+	query += ` ORDER BY received_at DESC, id DESC LIMIT ?`
+	args = append(args, limit)
+
+	return r.queryEvents(query, args...)
+}
+
+func (r *WebhookEventRepository) ListDue(now time.Time, limit int) ([]*webhook.Event, error) {
+	query := `SELECT ` + webhookEventColumns + ` FROM webhook_events
+		WHERE status IN (?, ?) AND next_attempt_at <= ?
+		ORDER BY received_at, id LIMIT ?`
+
+	return r.queryEvents(query, webhook.StatusPending, webhook.StatusProcessing, now, limit)
+}
+
+func (r *WebhookEventRepository) Claim(id string, now, leaseUntil time.Time) (bool, error) {
+	query := `UPDATE webhook_events SET status = ?, next_attempt_at = ?
+		WHERE id = ? AND status IN (?, ?) AND next_attempt_at <= ?`
+
+	res, err := r.db.Exec(query,
+		webhook.StatusProcessing, leaseUntil,
+		id, webhook.StatusPending, webhook.StatusProcessing, now,
+	)
+	if err != nil {
+		return false, err
+	}
+
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	return affected == 1, nil
+}
+
+func (r *WebhookEventRepository) Update(e *webhook.Event) error {
+	query := `UPDATE webhook_events
+		SET status = ?, attempts = ?, last_error = ?, next_attempt_at = ?, processed_at = ?
+		WHERE id = ?`
+
+	_, err := r.db.Exec(query, e.Status, e.Attempts, e.LastError, e.NextAttemptAt, e.ProcessedAt, e.ID)
+	return err
+}
+
+func (r *WebhookEventRepository) queryEvents(query string, args ...interface{}) ([]*webhook.Event, error) {
+	rows, err := r.db.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	events := []*webhook.Event{}
+	for rows.Next() {
+		e, err := scanWebhookEvent(rows)
+		if err != nil {
+			return nil, err
+		}
+		events = append(events, e)
+	}
+
+	return events, rows.Err()
+}
@@ -12,10 +12,12 @@ func HashAPIKey(key string) string {
 	return hex.EncodeToString(hash[:])
 }
 
-func GenerateAPIKey() string {
+// GenerateAPIKey returns a new key for mode, "live" or "test", as in
+// sk_live_...
+func GenerateAPIKey(mode string) string {
 	bytes := make([]byte, 32)
 	rand.Read(bytes)
-	return fmt.Sprintf("sk_live_%s", hex.EncodeToString(bytes))
+	return fmt.Sprintf("sk_%s_%s", mode, hex.EncodeToString(bytes))
 }
 
 func GenerateID() string {
@@ -0,0 +1,34 @@
+package settlement
+
+import (
+	"strconv"
+
+	"github.com/BerylCAtieno/paystack-wallet/internal/domain/wallet"
+	"github.com/BerylCAtieno/paystack-wallet/internal/paystack"
+)
+
+// ChargePayment converts a charge.success payload into the payment a
+// deposit is settled with
+func ChargePayment(e *paystack.ChargeEvent) wallet.DepositPayment {
+	return wallet.DepositPayment{
+		PaystackID:    e.ID.String(),
+		Amount:        int64(e.Amount),
+		Currency:      e.Currency,
+		CustomerEmail: e.Customer.Email,
+		Channel:       e.Channel,
+		PaidAt:        e.PaidAt,
+	}
+}
+
+// VerifiedPayment converts a transaction verification into the payment a
+// deposit is settled with
+func VerifiedPayment(v *paystack.VerifyResponse) wallet.DepositPayment {
+	return wallet.DepositPayment{
+		PaystackID:    strconv.FormatInt(v.Data.ID, 10),
+		Amount:        v.Data.Amount,
+		Currency:      v.Data.Currency,
+		CustomerEmail: v.Data.Customer.Email,
+		Channel:       v.Data.Channel,
+		PaidAt:        v.Data.PaidAt,
+	}
+}
@@ -0,0 +1,129 @@
+package settlement
+
+import (
+	"errors"
+	"fmt"
+	"log"
+
+	"github.com/BerylCAtieno/paystack-wallet/internal/domain/dispute"
+	"github.com/BerylCAtieno/paystack-wallet/internal/domain/refund"
+	"github.com/BerylCAtieno/paystack-wallet/internal/domain/wallet"
+	"github.com/BerylCAtieno/paystack-wallet/internal/domain/webhook"
+	"github.com/BerylCAtieno/paystack-wallet/internal/paystack"
+)
+
+// Processor applies stored Paystack webhook events to wallets. Every
+// operation it calls is idempotent, so events can be retried and replayed
+// safely.
+type Processor struct {
+	walletService  *wallet.Service
+	refundService  *refund.Service
+	disputeService *dispute.Service
+	dispatcher     *paystack.Dispatcher
+}
+
+func NewProcessor(walletService *wallet.Service, refundService *refund.Service, disputeService *dispute.Service) *Processor {
+	p := &Processor{
+		walletService:  walletService,
+		refundService:  refundService,
+		disputeService: disputeService,
+		dispatcher:     paystack.NewDispatcher(),
+	}
+
+	p.dispatcher.OnCharge(paystack.EventChargeSuccess, p.chargeSucceeded)
+	p.dispatcher.OnCharge(paystack.EventChargeFailed, p.chargeFailed)
+
+	p.dispatcher.OnTransfer(paystack.EventTransferSuccess, p.transferSucceeded)
+	p.dispatcher.OnTransfer(paystack.EventTransferFailed, p.transferFailed)
+	p.dispatcher.OnTransfer(paystack.EventTransferReversed, p.transferFailed)
+
+	p.dispatcher.OnRefund(paystack.EventRefundPending, p.refundUpdated(refund.StatusPending))
+	p.dispatcher.OnRefund(paystack.EventRefundProcessing, p.refundUpdated(refund.StatusProcessing))
+	p.dispatcher.OnRefund(paystack.EventRefundProcessed, p.refundUpdated(refund.StatusProcessed))
+	p.dispatcher.OnRefund(paystack.EventRefundFailed, p.refundUpdated(refund.StatusFailed))
+
+	p.dispatcher.OnDispute(paystack.EventChargeDisputeCreate, p.disputeUpdated)
+	p.dispatcher.OnDispute(paystack.EventChargeDisputeRemind, p.disputeUpdated)
+	p.dispatcher.OnDispute(paystack.EventChargeDisputeResolve, p.disputeUpdated)
+
+	// Wallets do not use Paystack subscriptions; these are only logged so
+	// unexpected plans on the integration are noticed
+	p.dispatcher.OnSubscription(paystack.EventSubscriptionCreate, subscriptionLogged(paystack.EventSubscriptionCreate))
+	p.dispatcher.OnSubscription(paystack.EventSubscriptionDisable, subscriptionLogged(paystack.EventSubscriptionDisable))
+	p.dispatcher.OnSubscription(paystack.EventSubscriptionNotRenew, subscriptionLogged(paystack.EventSubscriptionNotRenew))
+	p.dispatcher.OnSubscription(paystack.EventSubscriptionExpiringCards, subscriptionLogged(paystack.EventSubscriptionExpiringCards))
+
+	return p
+}
+
+func (p *Processor) Process(eventType string, payload []byte) error {
+	if !p.dispatcher.Handles(eventType) {
+		log.Printf("Ignoring unhandled Paystack event %s", eventType)
+		return fmt.Errorf("%w: unhandled event type %s", webhook.ErrIgnored, eventType)
+	}
+	return p.dispatcher.Dispatch(payload)
+}
+
+func (p *Processor) chargeSucceeded(e *paystack.ChargeEvent) error {
+	if e.Status != paystack.TransactionStatusSuccess {
+		return nil
+	}
+	_, err := p.walletService.SettleDeposit(e.Reference, ChargePayment(e))
+	return ignoreUnknown("charge", e.Reference, err)
+}
+
+func (p *Processor) chargeFailed(e *paystack.ChargeEvent) error {
+	return ignoreUnknown("charge", e.Reference, p.walletService.FailDeposit(e.Reference))
+}
+
+func (p *Processor) transferSucceeded(e *paystack.TransferEvent) error {
+	return ignoreUnknown("transfer", e.Reference, p.walletService.CompleteWithdrawal(e.Reference))
+}
+
+// transferFailed releases the hold, or refunds a settled payout that bounced
+func (p *Processor) transferFailed(e *paystack.TransferEvent) error {
+	return ignoreUnknown("transfer", e.Reference, p.walletService.FailWithdrawal(e.Reference))
+}
+
+func (p *Processor) refundUpdated(status refund.Status) func(*paystack.RefundEvent) error {
+	return func(e *paystack.RefundEvent) error {
+		r, err := p.refundService.RecordEvent(e.ID.String(), e.TransactionReference, int64(e.Amount), e.Currency, status)
+		if err != nil {
+			return err
+		}
+		return ignoreUnknown("refund", r.TransactionReference, SettleRefund(p.walletService, r))
+	}
+}
+
+func (p *Processor) disputeUpdated(e *paystack.DisputeEvent) error {
+	_, err := p.disputeService.Record(&dispute.Dispute{
+		PaystackID:       e.ID.String(),
+		DepositReference: e.Transaction.Reference,
+		Amount:           int64(e.Transaction.Amount),
+		Currency:         e.Currency,
+		Status:           e.Status,
+		Category:         e.Category,
+		Resolution:       e.Resolution,
+		DueAt:            e.DueAt,
+	})
+	return err
+}
+
+// ignoreUnknown turns a missing transaction into an ignored event. Other
+// integrations on the same Paystack account, and payments made from the
+// dashboard, send events for references this service never created, and
+// retrying them would only end in a failed event.
+func ignoreUnknown(kind, reference string, err error) error {
+	if !errors.Is(err, wallet.ErrTransactionNotFound) {
+		return err
+	}
+	log.Printf("Ignoring Paystack %s event for unknown reference %s", kind, reference)
+	return fmt.Errorf("%w: unknown reference %s", webhook.ErrIgnored, reference)
+}
+
+func subscriptionLogged(event string) func(*paystack.SubscriptionEvent) error {
+	return func(e *paystack.SubscriptionEvent) error {
+		log.Printf("Paystack %s for subscription %s (%s)", event, e.SubscriptionCode, e.Customer.Email)
+		return nil
+	}
+}
@@ -0,0 +1,24 @@
+package settlement
+
+import (
+	"github.com/BerylCAtieno/paystack-wallet/internal/domain/refund"
+	"github.com/BerylCAtieno/paystack-wallet/internal/domain/wallet"
+)
+
+// SettleRefund applies a refund's final status to the wallet refund holding
+// its funds: processed refunds clear the hold, failed ones return it to the
+// wallet. Refunds started from the Paystack dashboard have no wallet refund
+// and are only recorded.
+func SettleRefund(walletService *wallet.Service, r *refund.Refund) error {
+	if r.TransactionReference == "" {
+		return nil
+	}
+
+	switch r.Status {
+	case refund.StatusProcessed:
+		return walletService.CompleteRefund(r.TransactionReference)
+	case refund.StatusFailed:
+		return walletService.FailRefund(r.TransactionReference)
+	}
+	return nil
+}
@@ -0,0 +1,243 @@
+package settlement
+
+import (
+	"log"
+	"sync"
+	"time"
+
+	"github.com/BerylCAtieno/paystack-wallet/internal/domain/wallet"
+	"github.com/BerylCAtieno/paystack-wallet/internal/paystack"
+)
+
+const sweepBatchSize = 100
+
+// Verifier looks up charges and transfers on Paystack
+type Verifier interface {
+	VerifyTransaction(reference string) (*paystack.VerifyResponse, error)
+	VerifyTransfer(reference string) (*paystack.TransferResponse, error)
+}
+
+// SweepResult counts what happened to the deposits and withdrawals one sweep
+// looked at. Withdrawals that settled count as recovered.
+type SweepResult struct {
+	Checked   int `json:"checked"`
+	Recovered int `json:"recovered"`
+	Review    int `json:"review"`
+	Failed    int `json:"failed"`
+	Expired   int `json:"expired"`
+	Errors    int `json:"errors"`
+}
+
+func (r *SweepResult) add(o SweepResult) {
+	r.Checked += o.Checked
+	r.Recovered += o.Recovered
+	r.Review += o.Review
+	r.Failed += o.Failed
+	r.Expired += o.Expired
+	r.Errors += o.Errors
+}
+
+// SweepMetrics are the totals since the server started
+type SweepMetrics struct {
+	Runs      int         `json:"runs"`
+	LastRunAt *time.Time  `json:"last_run_at,omitempty"`
+	LastRun   SweepResult `json:"last_run"`
+	Total     SweepResult `json:"total"`
+}
+
+// Sweeper settles deposits and withdrawals whose webhook never arrived by
+// verifying them with Paystack, and expires deposits that were never paid.
+type Sweeper struct {
+	walletService *wallet.Service
+	verifier      Verifier
+	// verifyAfter is how long a deposit or withdrawal may stay pending
+	// before it is verified; expireAfter is when an unpaid deposit is given
+	// up on
+	verifyAfter time.Duration
+	expireAfter time.Duration
+
+	mu      sync.Mutex
+	metrics SweepMetrics
+}
+
+func NewSweeper(walletService *wallet.Service, verifier Verifier, verifyAfter, expireAfter time.Duration) *Sweeper {
+	return &Sweeper{
+		walletService: walletService,
+		verifier:      verifier,
+		verifyAfter:   verifyAfter,
+		expireAfter:   expireAfter,
+	}
+}
+
+// Run sweeps every interval until the process exits
+func (s *Sweeper) Run(interval time.Duration) {
+	for range time.Tick(interval) {
+		result, err := s.Sweep()
+		if err != nil {
+			log.Printf("Failed to sweep pending transactions: %v", err)
+		}
+		if result.Checked > 0 {
+			log.Printf("Sweep: checked %d, recovered %d, review %d, failed %d, expired %d, errors %d",
+				result.Checked, result.Recovered, result.Review, result.Failed, result.Expired, result.Errors)
+		}
+	}
+}
+
+// Sweep checks every deposit and withdrawal that has been pending longer
+// than verifyAfter
+func (s *Sweeper) Sweep() (SweepResult, error) {
+	var result SweepResult
+	err := s.sweep(&result, s.walletService.ListStaleDeposits, s.check)
+	if err == nil {
+		err = s.sweep(&result, s.walletService.ListStaleWithdrawals, s.checkWithdrawal)
+	}
+
+	s.record(result)
+	return result, err
+}
+
+// sweep checks every transaction list returns, a batch at a time
+func (s *Sweeper) sweep(
+	result *SweepResult,
+	list func(time.Duration, *wallet.TransactionCursor, int) ([]*wallet.Transaction, error),
+	check func(*wallet.Transaction) SweepResult,
+) error {
+	var after *wallet.TransactionCursor
+	for {
+		txs, err := list(s.verifyAfter, after, sweepBatchSize)
+		if err != nil {
+			return err
+		}
+
+		for _, tx := range txs {
+			result.add(check(tx))
+		}
+
+		if len(txs) < sweepBatchSize {
+			return nil
+		}
+		last := txs[len(txs)-1]
+		after = &wallet.TransactionCursor{CreatedAt: last.CreatedAt, ID: last.ID}
+	}
+}
+
+func (s *Sweeper) check(tx *wallet.Transaction) SweepResult {
+	result := SweepResult{Checked: 1}
+	expired := time.Since(tx.CreatedAt) > s.expireAfter
+
+	verification, err := s.verifier.VerifyTransaction(tx.Reference)
+	if err != nil {
+		// Paystack has no charge for references that were never opened.
+		// Any other error says nothing about the charge, so the deposit is
+		// left for the next sweep.
+		if expired && paystack.IsNotFound(err) {
+			return s.expire(tx, result)
+		}
+		log.Printf("Sweeper: verify deposit %s: %v", tx.Reference, err)
+		result.Errors++
+		return result
+	}
+
+	switch verification.Data.Status {
+	case paystack.TransactionStatusSuccess:
+		settled, err := s.walletService.SettleDeposit(tx.Reference, VerifiedPayment(verification))
+		if err != nil {
+			log.Printf("Sweeper: settle deposit %s: %v", tx.Reference, err)
+			result.Errors++
+			return result
+		}
+		if settled.Status == wallet.TransactionStatusReview {
+			result.Review++
+		} else {
+			result.Recovered++
+		}
+
+	case paystack.TransactionStatusFailed:
+		if err := s.walletService.FailDeposit(tx.Reference); err != nil {
+			log.Printf("Sweeper: fail deposit %s: %v", tx.Reference, err)
+			result.Errors++
+			return result
+		}
+		result.Failed++
+
+	default:
+		// abandoned, ongoing and the like: the customer may still pay
+		if expired {
+			return s.expire(tx, result)
+		}
+	}
+
+	return result
+}
+
+// checkWithdrawal settles a withdrawal whose transfer request had an unknown
+// outcome, or whose webhook was lost
+func (s *Sweeper) checkWithdrawal(tx *wallet.Transaction) SweepResult {
+	result := SweepResult{Checked: 1}
+
+	verification, err := s.verifier.VerifyTransfer(tx.Reference)
+	switch {
+	case paystack.IsNotFound(err):
+		// The transfer request never reached Paystack
+		if err := s.walletService.FailWithdrawal(tx.Reference); err != nil {
+			log.Printf("Sweeper: fail withdrawal %s: %v", tx.Reference, err)
+			result.Errors++
+			return result
+		}
+		result.Failed++
+		return result
+	case err != nil:
+		log.Printf("Sweeper: verify withdrawal %s: %v", tx.Reference, err)
+		result.Errors++
+		return result
+	}
+
+	switch verification.Data.Status {
+	case paystack.TransferStatusSuccess:
+		if err := s.walletService.CompleteWithdrawal(tx.Reference); err != nil {
+			log.Printf("Sweeper: complete withdrawal %s: %v", tx.Reference, err)
+			result.Errors++
+			return result
+		}
+		result.Recovered++
+
+	case paystack.TransferStatusFailed, paystack.TransferStatusReversed:
+		if err := s.walletService.FailWithdrawal(tx.Reference); err != nil {
+			log.Printf("Sweeper: fail withdrawal %s: %v", tx.Reference, err)
+			result.Errors++
+			return result
+		}
+		result.Failed++
+	}
+
+	// pending and otp: Paystack is still working on it or waiting for
+	// the OTP
+	return result
+}
+
+func (s *Sweeper) expire(tx *wallet.Transaction, result SweepResult) SweepResult {
+	if err := s.walletService.ExpireDeposit(tx.Reference); err != nil {
+		log.Printf("Sweeper: expire deposit %s: %v", tx.Reference, err)
+		result.Errors++
+		return result
+	}
+	result.Expired++
+	return result
+}
+
+func (s *Sweeper) record(result SweepResult) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+	s.metrics.Runs++
+	s.metrics.LastRunAt = &now
+	s.metrics.LastRun = result
+	s.metrics.Total.add(result)
+}
+
+func (s *Sweeper) Metrics() SweepMetrics {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.metrics
+}
@@ -4,6 +4,10 @@ import "github.com/gin-gonic/gin"
 
 type ErrorResponse struct {
 	Error string `json:"error"`
+	// Message and Details explain errors whose Error is a code clients
+	// branch on
+	Message string      `json:"message,omitempty"`
+	Details interface{} `json:"details,omitempty"`
 }
 
 type SuccessResponse struct {
@@ -19,6 +23,12 @@ func RespondError(c *gin.Context, status int, message string) {
 	c.JSON(status, ErrorResponse{Error: message})
 }
 
+// RespondErrorDetails writes an error code with a readable message and
+// structured details
+func RespondErrorDetails(c *gin.Context, status int, code, message string, details interface{}) {
+	c.JSON(status, ErrorResponse{Error: code, Message: message, Details: details})
+}
+
 func RespondSuccess(c *gin.Context, data interface{}) {
 	c.JSON(200, SuccessResponse{
 		Data: data,